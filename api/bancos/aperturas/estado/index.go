@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	bancoHandler "github.com/luispfcanales/rainforestapp/pkg/handler"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// Handler es la función principal para Vercel
+func Handler(w http.ResponseWriter, r *http.Request) {
+	metrics.Instrument("/api/bancos/aperturas/estado", serve)(w, r)
+}
+
+// serve inicializa el handler y delega la petición
+func serve(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.Load()
+	if err != nil {
+		response.InternalServerError(w, "Error de configuración")
+		return
+	}
+
+	h, err := bancoHandler.NewBancoHandler(cfg)
+	if err != nil {
+		response.InternalServerError(w, "Error inicializando handler")
+		return
+	}
+
+	// Delegar al handler para actualizar el estado de una apertura
+	h.UpdateApertura(w, r)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	catalogoHandler "github.com/luispfcanales/rainforestapp/pkg/handler"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// Handler es la función principal para Vercel
+func Handler(w http.ResponseWriter, r *http.Request) {
+	metrics.Instrument("/api/catalogos", serve)(w, r)
+}
+
+// serve inicializa el handler y delega la petición
+func serve(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.Load()
+	if err != nil {
+		response.InternalServerError(w, "Error de configuración")
+		return
+	}
+
+	h := catalogoHandler.NewCatalogoHandler(cfg)
+
+	// Delegar al handler para publicar los catálogos
+	h.GetCatalogos(w, r)
+}
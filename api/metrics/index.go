@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// Handler expone las métricas en formato Prometheus. Cada función de Vercel es un proceso
+// aparte, así que solo muestra los contadores de la instancia que responde; los contadores
+// completos solo se ven si un único proceso de larga duración atiende todas las rutas.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	// Si la configuración no carga se siguen exponiendo las métricas para diagnosticar
+	if cfg, err := config.Load(); err == nil && !cfg.Features.Metrics {
+		response.NotFound(w, "Métricas deshabilitadas")
+		return
+	}
+
+	metrics.Handler().ServeHTTP(w, r)
+}
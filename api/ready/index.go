@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"net/http"
+
+	usuarioHandler "github.com/luispfcanales/rainforestapp/pkg/handler"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+)
+
+// Handler es el punto de entrada de Vercel para el readiness check
+func Handler(w http.ResponseWriter, r *http.Request) {
+	metrics.Instrument("/api/ready", usuarioHandler.NewHealthHandler().Ready)(w, r)
+}
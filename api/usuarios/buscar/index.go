@@ -5,11 +5,17 @@ import (
 
 	"github.com/luispfcanales/rainforestapp/pkg/config"
 	usuarioHandler "github.com/luispfcanales/rainforestapp/pkg/handler"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
 	"github.com/luispfcanales/rainforestapp/pkg/response"
 )
 
 // Handler es la función principal para Vercel
 func Handler(w http.ResponseWriter, r *http.Request) {
+	metrics.Instrument("/api/usuarios/buscar", serve)(w, r)
+}
+
+// serve inicializa el handler y delega la petición
+func serve(w http.ResponseWriter, r *http.Request) {
 	cfg, err := config.Load()
 	if err != nil {
 		response.InternalServerError(w, "Error de configuración")
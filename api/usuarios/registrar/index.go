@@ -7,6 +7,7 @@ import (
 
 	"github.com/luispfcanales/rainforestapp/pkg/config"
 	usuarioHandler "github.com/luispfcanales/rainforestapp/pkg/handler"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
 	"github.com/luispfcanales/rainforestapp/pkg/response"
 )
 
@@ -46,6 +47,11 @@ func getHandler() (*usuarioHandler.UsuarioHandler, error) {
 
 // Handler es el punto de entrada de Vercel
 func Handler(w http.ResponseWriter, r *http.Request) {
+	metrics.Instrument("/api/usuarios/registrar", serve)(w, r)
+}
+
+// serve inicializa el handler y delega la petición
+func serve(w http.ResponseWriter, r *http.Request) {
 	// Obtener handler inicializado
 	h, err := getHandler()
 	if err != nil {
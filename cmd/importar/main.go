@@ -0,0 +1,113 @@
+// Comando importar carga usuarios desde un archivo CSV o XLSX usando la misma
+// validación que el endpoint /api/usuarios/importar.
+//
+//	go run ./cmd/importar -archivo personal.csv               # solo valida
+//	go run ./cmd/importar -archivo personal.xlsx -dry-run=false -politica actualizar
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/database"
+	"github.com/luispfcanales/rainforestapp/pkg/importer"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/repository"
+	"github.com/luispfcanales/rainforestapp/pkg/service"
+)
+
+func main() {
+	path := flag.String("archivo", "", "archivo CSV o XLSX a importar")
+	dryRun := flag.Bool("dry-run", true, "solo valida el archivo sin guardar")
+	policy := flag.String("politica", string(models.ImportPolicyOmitir), "documentos ya registrados: omitir o actualizar")
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := run(*path, *dryRun, models.ImportPolicy(*policy))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printReport(report)
+	if report.Invalidas > 0 || report.Fallidos > 0 {
+		os.Exit(1)
+	}
+}
+
+func run(path string, dryRun bool, policy models.ImportPolicy) (*models.ImportReport, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	format, ok := importer.FormatFromName(path)
+	if !ok {
+		return nil, fmt.Errorf("el archivo debe tener extensión .csv o .xlsx")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo archivo: %w", err)
+	}
+	defer file.Close()
+
+	rows, err := importer.Parse(file, format, cfg.Limits.MaxImportRows)
+	if err != nil {
+		return nil, fmt.Errorf("archivo inválido: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Import.Duration)
+	defer cancel()
+
+	repo, err := newRepository(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	return service.NewUsuarioService(repo).ImportUsuarios(ctx, rows, models.ImportOptions{
+		DryRun: dryRun,
+		Policy: policy,
+		Limits: cfg.Limits.RequestLimits(),
+	})
+}
+
+// newRepository crea el repositorio; con el backend en memoria solo tiene sentido validar
+func newRepository(ctx context.Context, cfg *config.Config) (repository.UsuarioRepository, error) {
+	if cfg.Storage.Backend == config.StorageMemory {
+		return repository.NewMemoryUsuarioRepository(), nil
+	}
+
+	client, err := database.GetFirestoreClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewUsuarioRepository(client), nil
+}
+
+func printReport(report *models.ImportReport) {
+	for _, fila := range report.Filas {
+		line := fmt.Sprintf("fila %4d  %-9s  %-12s  %-12s", fila.Fila, fila.TipoDocumento, fila.NumeroDocumento, fila.Estado)
+		if len(fila.Errores) > 0 {
+			line += "  " + strings.Join(fila.Errores, "; ")
+		}
+		fmt.Println(line)
+	}
+
+	mode := "importación"
+	if report.DryRun {
+		mode = "validación (dry-run)"
+	}
+	fmt.Printf("\n%s, política %s: %d filas, %d válidas, %d inválidas, %d creados, %d actualizados, %d omitidos, %d fallidos\n",
+		mode, report.Politica, report.Total, report.Validas, report.Invalidas,
+		report.Creados, report.Actualizados, report.Omitidos, report.Fallidos)
+}
@@ -0,0 +1,259 @@
+// Comando migrar corrige los documentos de usuarios ya guardados cuando cambia el formato
+// de un campo. Cada migración recorre todos los usuarios y actualiza solo los campos que
+// cambian, siempre que el usuario no se haya modificado después de leerlo. Si al terminar
+// dos usuarios quedarían con el mismo documento no se guarda nada.
+//
+// Los usuarios se leen y se guardan en lotes ordenados por ID. Después de cada lote se
+// muestra el último ID guardado; si la migración se interrumpe se retoma con -desde.
+// No tiene tiempo máximo salvo que se indique -timeout.
+//
+//	go run ./cmd/migrar -migracion catalogos                # solo muestra los cambios
+//	go run ./cmd/migrar -migracion catalogos -dry-run=false
+//	go run ./cmd/migrar -migracion documentos -dry-run=false -desde 8fK2xQ -timeout 30m
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/database"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/repository"
+)
+
+// migration corrige un usuario; devuelve si cambió y observaciones de los valores que no
+// pudo corregir
+type migration func(u *models.Usuario) (changed bool, notes []string)
+
+// migrations migraciones disponibles por nombre
+var migrations = map[string]migration{
+	// catalogos reemplaza el texto libre de los campos codificados por el código del catálogo
+	"catalogos": (*models.Usuario).NormalizeCatalogs,
+	// ubigeo completa el ubigeo de nacimiento y deja los nombres oficiales del lugar
+	"ubigeo": (*models.Usuario).NormalizeUbigeo,
+	// documentos completa el tipo y número de documento de los usuarios registrados solo con DNI
+	"documentos": (*models.Usuario).NormalizeDocumento,
+}
+
+// result resultado de la migración de un usuario
+type result struct {
+	documento string
+	changed   bool
+	notes     []string
+	err       error
+}
+
+// options opciones de la ejecución
+type options struct {
+	dryRun  bool
+	timeout time.Duration
+	batch   int
+	after   string
+}
+
+func main() {
+	name := flag.String("migracion", "", "migración a aplicar: "+strings.Join(migrationNames(), ", "))
+	var opts options
+	flag.BoolVar(&opts.dryRun, "dry-run", true, "solo muestra los cambios sin guardar")
+	flag.DurationVar(&opts.timeout, "timeout", 0, "tiempo máximo de la migración; 0 sin límite")
+	flag.IntVar(&opts.batch, "lote", 300, "usuarios leídos y guardados por lote")
+	flag.StringVar(&opts.after, "desde", "", "retoma la migración después del usuario con este ID")
+	flag.Parse()
+
+	migrate, ok := migrations[*name]
+	if !ok || opts.batch <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Si la migración se interrumpe se muestra igual lo que se guardó hasta ese momento
+	results, err := run(migrate, opts)
+	failed := results != nil && printReport(*name, results, opts.dryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func run(migrate migration, opts options) ([]result, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	repo, err := newRepository(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	// Primero se recorre toda la colección sin guardar: los documentos duplicados se detectan
+	// con todos los usuarios, también los anteriores a -desde
+	var preview []result
+	owners := make(map[models.Documento][]string)
+	err = eachPage(ctx, repo, "", opts.batch, func(page []*models.Usuario) error {
+		for _, u := range page {
+			changed, notes := migrate(u)
+			owners[u.Documento()] = append(owners[u.Documento()], u.ID)
+			if changed || len(notes) > 0 {
+				preview = append(preview, result{documento: u.Documento().String(), changed: changed, notes: notes})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error recorriendo usuarios: %w", err)
+	}
+
+	if err := checkDuplicados(owners); err != nil {
+		return nil, err
+	}
+
+	if opts.dryRun {
+		return preview, nil
+	}
+
+	results := []result{}
+	err = eachPage(ctx, repo, opts.after, opts.batch, func(page []*models.Usuario) error {
+		var pending []repository.UsuarioPatch
+		var indexes []int
+		for _, u := range page {
+			before := *u
+			changed, notes := migrate(u)
+			if changed || len(notes) > 0 {
+				results = append(results, result{documento: u.Documento().String(), changed: changed, notes: notes})
+			}
+			if changed {
+				pending = append(pending, repository.UsuarioPatch{Usuario: u, Fields: models.ChangedFields(&before, u)})
+				indexes = append(indexes, len(results)-1)
+			}
+		}
+
+		if len(pending) > 0 {
+			for n, err := range repo.PatchAll(ctx, pending) {
+				results[indexes[n]].err = err
+			}
+		}
+		log.Printf("lote guardado hasta el usuario %s (para retomar: -desde %s)", page[len(page)-1].ID, page[len(page)-1].ID)
+		return ctx.Err()
+	})
+	if err != nil {
+		return results, fmt.Errorf("migración interrumpida: %w", err)
+	}
+	return results, nil
+}
+
+// eachPage llama a fn con los usuarios de a batch, ordenados por ID, después de afterID
+func eachPage(ctx context.Context, repo repository.UsuarioRepository, afterID string, batch int, fn func([]*models.Usuario) error) error {
+	for {
+		page, err := repo.Page(ctx, afterID, batch)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		if len(page) < batch {
+			return nil
+		}
+		afterID = page[len(page)-1].ID
+	}
+}
+
+// checkDuplicados devuelve un error con los documentos que tendrían varios usuarios después
+// de la migración; hay que corregirlos a mano antes de migrar
+func checkDuplicados(owners map[models.Documento][]string) error {
+	var lines []string
+	for doc, ids := range owners {
+		if doc.Numero != "" && len(ids) > 1 {
+			lines = append(lines, fmt.Sprintf("  %s: %s", doc, strings.Join(ids, ", ")))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	sort.Strings(lines)
+	return fmt.Errorf("%d documentos quedarían con más de un usuario, corríjalos antes de migrar:\n%s",
+		len(lines), strings.Join(lines, "\n"))
+}
+
+// newRepository crea el repositorio; con el backend en memoria solo tiene sentido probar
+func newRepository(ctx context.Context, cfg *config.Config) (repository.UsuarioRepository, error) {
+	if cfg.Storage.Backend == config.StorageMemory {
+		return repository.NewMemoryUsuarioRepository(), nil
+	}
+
+	client, err := database.GetFirestoreClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewUsuarioRepository(client), nil
+}
+
+func migrationNames() []string {
+	names := make([]string, 0, len(migrations))
+	for name := range migrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printReport imprime una línea por usuario con cambios u observaciones y el resumen;
+// devuelve si hubo errores al guardar
+func printReport(name string, results []result, dryRun bool) bool {
+	var changed, pending, failed int
+	for _, r := range results {
+		estado := "sin cambios"
+		switch {
+		case r.err != nil:
+			estado = "error"
+			failed++
+		case r.changed && dryRun:
+			estado = "actualizar"
+		case r.changed:
+			estado = "actualizado"
+		}
+		if r.changed && r.err == nil {
+			changed++
+		}
+		if len(r.notes) > 0 {
+			pending++
+		}
+
+		line := fmt.Sprintf("%-22s  %-12s", r.documento, estado)
+		if r.err != nil {
+			line += "  " + r.err.Error()
+		} else if len(r.notes) > 0 {
+			line += "  " + strings.Join(r.notes, "; ")
+		}
+		fmt.Println(line)
+	}
+
+	mode := "migración"
+	if dryRun {
+		mode = "validación (dry-run)"
+	}
+	fmt.Printf("\n%s %s: %d usuarios con cambios, %d con valores por corregir a mano, %d fallidos\n",
+		mode, name, changed, pending, failed)
+	return failed > 0
+}
@@ -0,0 +1,191 @@
+// Comando ubigeo genera pkg/catalog/ubigeo.csv a partir de la tabla de ubigeo del INEI
+// (exportación CSV con una fila por distrito y las columnas ubigeo_inei o ubigeo,
+// departamento, provincia y distrito, separadas por coma o punto y coma).
+//
+//	go run ./cmd/ubigeo -archivo TB_UBIGEOS.csv
+//	go run ./cmd/ubigeo -archivo ubigeo_inei.csv -salida pkg/catalog/ubigeo.csv
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// minDistritos cantidad mínima de distritos de la tabla completa; evita embeber por error un
+// extracto (el INEI registra unos 1 890 distritos)
+const minDistritos = 1800
+
+func main() {
+	path := flag.String("archivo", "", "exportación CSV de la tabla de ubigeo del INEI")
+	output := flag.String("salida", "pkg/catalog/ubigeo.csv", "archivo que se genera")
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*path, *output); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(path, output string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error leyendo archivo: %w", err)
+	}
+
+	distritos, err := parseINEI(data)
+	if err != nil {
+		return err
+	}
+	if len(distritos) < minDistritos {
+		return fmt.Errorf("el archivo tiene %d distritos; la tabla del INEI tiene al menos %d", len(distritos), minDistritos)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTable(&buf, distritos); err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error escribiendo %s: %w", output, err)
+	}
+	fmt.Printf("%s: %d distritos\n", output, len(distritos))
+	return nil
+}
+
+// distrito fila de la tabla del INEI
+type distrito struct {
+	ubigeo       string
+	departamento string
+	provincia    string
+	distrito     string
+}
+
+// parseINEI lee las filas de distritos buscando las columnas por su nombre
+func parseINEI(data []byte) ([]distrito, error) {
+	data = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+	if !utf8.Valid(data) {
+		// Las exportaciones antiguas del INEI están en Latin-1
+		data = latin1ToUTF8(data)
+	}
+
+	reader := csv.NewReader(bufio.NewReader(bytes.NewReader(data)))
+	reader.FieldsPerRecord = -1
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo encabezado: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	codigo, ok := index["ubigeo_inei"]
+	if !ok {
+		codigo, ok = index["ubigeo"]
+	}
+	if !ok {
+		return nil, fmt.Errorf("falta la columna ubigeo_inei o ubigeo")
+	}
+	cols := []int{codigo}
+	for _, name := range []string{"departamento", "provincia", "distrito"} {
+		i, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("falta la columna %s", name)
+		}
+		cols = append(cols, i)
+	}
+
+	var distritos []distrito
+	seen := make(map[string]bool)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: %w", line, err)
+		}
+		values := make([]string, len(cols))
+		for i, col := range cols {
+			if col >= len(record) {
+				return nil, fmt.Errorf("línea %d: faltan columnas", line)
+			}
+			values[i] = strings.Join(strings.Fields(record[col]), " ")
+		}
+
+		d := distrito{ubigeo: values[0], departamento: values[1], provincia: values[2], distrito: values[3]}
+		if len(d.ubigeo) == 5 {
+			// Excel quita el cero inicial de los departamentos 01 a 09
+			d.ubigeo = "0" + d.ubigeo
+		}
+		if len(d.ubigeo) != 6 || strings.Trim(d.ubigeo, "0123456789") != "" || d.ubigeo[4:] == "00" {
+			return nil, fmt.Errorf("línea %d: ubigeo %q inválido", line, values[0])
+		}
+		if d.departamento == "" || d.provincia == "" || d.distrito == "" {
+			return nil, fmt.Errorf("línea %d: el ubigeo %s no tiene departamento, provincia y distrito", line, d.ubigeo)
+		}
+		if seen[d.ubigeo] {
+			return nil, fmt.Errorf("línea %d: ubigeo %s duplicado", line, d.ubigeo)
+		}
+		seen[d.ubigeo] = true
+		distritos = append(distritos, d)
+	}
+
+	slices.SortFunc(distritos, func(a, b distrito) int { return cmp.Compare(a.ubigeo, b.ubigeo) })
+	return distritos, nil
+}
+
+// writeTable escribe la tabla en el formato de pkg/catalog: cada departamento y provincia
+// antes de sus distritos. Un departamento o provincia con dos nombres distintos es un error.
+func writeTable(w io.Writer, distritos []distrito) error {
+	fmt.Fprintln(w, "# Ubigeo INEI: departamentos (2 dígitos), provincias (4) y distritos (6)")
+	fmt.Fprintln(w, "# Generado con go run ./cmd/ubigeo a partir de la tabla del INEI; no editar a mano")
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"ubigeo", "nombre"})
+
+	nombres := make(map[string]string)
+	for _, d := range distritos {
+		for _, level := range [][2]string{{d.ubigeo[:2], d.departamento}, {d.ubigeo[:4], d.provincia}} {
+			nombre, ok := nombres[level[0]]
+			if ok && nombre != level[1] {
+				return fmt.Errorf("el ubigeo %s tiene dos nombres: %q y %q", level[0], nombre, level[1])
+			}
+			if !ok {
+				nombres[level[0]] = level[1]
+				out.Write(level[:])
+			}
+		}
+		out.Write([]string{d.ubigeo, d.distrito})
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// latin1ToUTF8 convierte texto ISO-8859-1 a UTF-8
+func latin1ToUTF8(data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(data) + len(data)/8)
+	for _, b := range data {
+		buf.WriteRune(rune(b))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,17 @@
+// Package blob almacena los archivos generados por los reportes asíncronos
+package blob
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound el archivo no existe
+var ErrNotFound = errors.New("archivo no encontrado")
+
+// Store almacenamiento de archivos por clave
+type Store interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get devuelve el contenido y su tipo MIME
+	Get(ctx context.Context, key string) ([]byte, string, error)
+}
@@ -0,0 +1,69 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// contentTypeSuffix extensión del archivo auxiliar con el tipo MIME
+const contentTypeSuffix = ".content-type"
+
+// FileStore guarda los archivos en un directorio local
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore crea el directorio si no existe
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creando directorio de archivos: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Put guarda el archivo y su tipo MIME
+func (s *FileStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creando directorio de archivos: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error guardando archivo: %w", err)
+	}
+	if err := os.WriteFile(path+contentTypeSuffix, []byte(contentType), 0o644); err != nil {
+		return fmt.Errorf("error guardando archivo: %w", err)
+	}
+	return nil
+}
+
+// Get lee el archivo guardado
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error leyendo archivo: %w", err)
+	}
+	contentType, _ := os.ReadFile(path + contentTypeSuffix)
+	return data, string(contentType), nil
+}
+
+// path evita que una clave apunte fuera del directorio
+func (s *FileStore) path(key string) (string, error) {
+	if !filepath.IsLocal(key) {
+		return "", fmt.Errorf("clave de archivo %q inválida", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
@@ -0,0 +1,63 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// GCSStore guarda los archivos en un bucket de Cloud Storage
+type GCSStore struct {
+	bucket *storage.BucketHandle
+}
+
+// NewGCSStore crea el cliente de Cloud Storage con las credenciales de la cuenta de servicio
+func NewGCSStore(ctx context.Context, bucket, credentials string) (*GCSStore, error) {
+	var opts []option.ClientOption
+	if credentials != "" {
+		opts = append(opts, option.WithCredentialsJSON([]byte(credentials)))
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creando cliente de Cloud Storage: %w", err)
+	}
+	return &GCSStore{bucket: client.Bucket(bucket)}, nil
+}
+
+// Put sube el archivo al bucket
+func (s *GCSStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	w := s.bucket.Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("error subiendo archivo: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error subiendo archivo: %w", err)
+	}
+	return nil
+}
+
+// Get descarga el archivo del bucket
+func (s *GCSStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	r, err := s.bucket.Object(key).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error descargando archivo: %w", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("error descargando archivo: %w", err)
+	}
+	return data, r.Attrs.ContentType, nil
+}
@@ -0,0 +1,45 @@
+package blob
+
+import (
+	"context"
+	"sync"
+)
+
+type memoryObject struct {
+	data        []byte
+	contentType string
+}
+
+// MemoryStore guarda los archivos en memoria; solo sirve dentro de una misma instancia
+type MemoryStore struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+// NewMemoryStore crea un almacenamiento en memoria vacío
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		objects: make(map[string]memoryObject),
+	}
+}
+
+// Put guarda el archivo
+func (s *MemoryStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = memoryObject{data: data, contentType: contentType}
+	return nil
+}
+
+// Get devuelve el archivo guardado
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, "", ErrNotFound
+	}
+	return obj.data, obj.contentType, nil
+}
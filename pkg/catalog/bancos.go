@@ -0,0 +1,57 @@
+// Package catalog listas de referencia con los valores válidos de los campos codificados
+package catalog
+
+import "strings"
+
+// Banco entidad financiera; CodigoCCI son los 3 primeros dígitos del CCI de sus cuentas
+type Banco struct {
+	Codigo    string   `json:"codigo"`
+	Nombre    string   `json:"nombre"`
+	CodigoCCI string   `json:"codigo_cci"`
+	Alias     []string `json:"-"`
+}
+
+// Bancos entidades con las que se registran cuentas sueldo y CTS, con su código de entidad
+// en la Cámara de Compensación Electrónica
+var Bancos = []Banco{
+	{Codigo: "BCP", Nombre: "Banco de Crédito del Perú", CodigoCCI: "002", Alias: []string{"CREDITO", "BANCO DE CREDITO"}},
+	{Codigo: "INTERBANK", Nombre: "Interbank", CodigoCCI: "003", Alias: []string{"BANCO INTERNACIONAL DEL PERU", "IBK"}},
+	{Codigo: "SCOTIABANK", Nombre: "Scotiabank Perú", CodigoCCI: "009", Alias: []string{"SCOTIA"}},
+	{Codigo: "BBVA", Nombre: "BBVA Perú", CodigoCCI: "011", Alias: []string{"BBVA CONTINENTAL", "CONTINENTAL"}},
+	{Codigo: "NACION", Nombre: "Banco de la Nación", CodigoCCI: "018", Alias: []string{"BANCO DE LA NACION", "BN"}},
+	{Codigo: "COMERCIO", Nombre: "Banco de Comercio", CodigoCCI: "023"},
+	{Codigo: "PICHINCHA", Nombre: "Banco Pichincha", CodigoCCI: "035", Alias: []string{"FINANCIERO", "BANCO FINANCIERO"}},
+	{Codigo: "BANBIF", Nombre: "Banco Interamericano de Finanzas", CodigoCCI: "038", Alias: []string{"BIF"}},
+	{Codigo: "MIBANCO", Nombre: "Mibanco", CodigoCCI: "049"},
+}
+
+// accents reemplazos de las letras con tilde para comparar nombres
+var accents = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U", "Ñ", "N",
+)
+
+// BancoByCodigo busca un banco por su código
+func BancoByCodigo(codigo string) (Banco, bool) {
+	for _, b := range Bancos {
+		if b.Codigo == codigo {
+			return b, true
+		}
+	}
+	return Banco{}, false
+}
+
+// FindBanco busca un banco por su código, nombre o un nombre alternativo, sin distinguir
+// mayúsculas ni tildes; sirve para convertir los nombres escritos a mano
+func FindBanco(value string) (Banco, bool) {
+	key := normalize(value)
+	if key == "" {
+		return Banco{}, false
+	}
+	for _, b := range Bancos {
+		if matches(key, b.Codigo, b.Nombre, b.Alias) {
+			return b, true
+		}
+	}
+	return Banco{}, false
+}
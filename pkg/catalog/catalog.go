@@ -0,0 +1,241 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Item valor de un catálogo; se guarda el código y se muestra el nombre
+type Item struct {
+	Codigo string   `json:"codigo"`
+	Nombre string   `json:"nombre"`
+	Alias  []string `json:"-"`
+}
+
+// Catalogo lista de referencia; se publica con una versión calculada de su contenido (ver Listas)
+type Catalogo struct {
+	Nombre string
+	Items  []Item
+}
+
+// ByCodigo busca un valor por su código
+func (c *Catalogo) ByCodigo(codigo string) (Item, bool) {
+	for _, item := range c.Items {
+		if item.Codigo == codigo {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// Find busca un valor por su código, nombre o un nombre alternativo, sin distinguir
+// mayúsculas ni tildes
+func (c *Catalogo) Find(value string) (Item, bool) {
+	key := normalize(value)
+	if key == "" {
+		return Item{}, false
+	}
+	for _, item := range c.Items {
+		if matches(key, item.Codigo, item.Nombre, item.Alias) {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// Codigos códigos del catálogo, para los mensajes de error
+func (c *Catalogo) Codigos() []string {
+	codigos := make([]string, len(c.Items))
+	for i, item := range c.Items {
+		codigos[i] = item.Codigo
+	}
+	return codigos
+}
+
+// NombreOf nombre para mostrar del código; si el código no está en el catálogo, como en los
+// registros anteriores a la migración, se devuelve tal cual
+func (c *Catalogo) NombreOf(codigo string) string {
+	if item, ok := c.ByCodigo(codigo); ok {
+		return item.Nombre
+	}
+	return codigo
+}
+
+// Catálogos de los campos que antes eran texto libre
+var (
+	AFP = &Catalogo{
+		Nombre: "afp",
+		Items: []Item{
+			{Codigo: "HABITAT", Nombre: "AFP Habitat", Alias: []string{"AFP HABITAT"}},
+			{Codigo: "INTEGRA", Nombre: "AFP Integra", Alias: []string{"AFP INTEGRA"}},
+			{Codigo: "PRIMA", Nombre: "Prima AFP", Alias: []string{"AFP PRIMA"}},
+			{Codigo: "PROFUTURO", Nombre: "Profuturo AFP", Alias: []string{"AFP PROFUTURO"}},
+		},
+	}
+
+	RegimenSalud = &Catalogo{
+		Nombre: "regimen_salud",
+		Items: []Item{
+			{Codigo: "ESSALUD", Nombre: "EsSalud", Alias: []string{"ES SALUD", "SEGURO SOCIAL"}},
+			{Codigo: "EPS", Nombre: "EsSalud con EPS", Alias: []string{"ESSALUD EPS", "ESSALUD + EPS", "ENTIDAD PRESTADORA DE SALUD"}},
+			{Codigo: "SIS", Nombre: "Seguro Integral de Salud", Alias: []string{"SEGURO INTEGRAL"}},
+			{Codigo: "PRIVADO", Nombre: "Seguro privado", Alias: []string{"SEGURO PRIVADO"}},
+			{Codigo: "NINGUNO", Nombre: "Ninguno", Alias: []string{"SIN SEGURO"}},
+		},
+	}
+
+	Parentesco = &Catalogo{
+		Nombre: "parentesco",
+		Items: []Item{
+			{Codigo: "PADRE", Nombre: "Padre", Alias: []string{"PAPA"}},
+			{Codigo: "MADRE", Nombre: "Madre", Alias: []string{"MAMA"}},
+			{Codigo: "CONYUGE", Nombre: "Cónyuge", Alias: []string{"ESPOSO", "ESPOSA"}},
+			{Codigo: "CONVIVIENTE", Nombre: "Conviviente", Alias: []string{"PAREJA"}},
+			{Codigo: "HIJO", Nombre: "Hijo(a)", Alias: []string{"HIJA"}},
+			{Codigo: "HERMANO", Nombre: "Hermano(a)", Alias: []string{"HERMANA"}},
+			{Codigo: "ABUELO", Nombre: "Abuelo(a)", Alias: []string{"ABUELA"}},
+			{Codigo: "TIO", Nombre: "Tío(a)", Alias: []string{"TIA"}},
+			{Codigo: "PRIMO", Nombre: "Primo(a)", Alias: []string{"PRIMA"}},
+			{Codigo: "SOBRINO", Nombre: "Sobrino(a)", Alias: []string{"SOBRINA"}},
+			{Codigo: "AMIGO", Nombre: "Amigo(a)", Alias: []string{"AMIGA"}},
+			{Codigo: "OTRO", Nombre: "Otro"},
+		},
+	}
+
+	NivelEducativo = &Catalogo{
+		Nombre: "nivel_educativo",
+		Items: []Item{
+			{Codigo: "INICIAL", Nombre: "Inicial"},
+			{Codigo: "PRIMARIA", Nombre: "Primaria"},
+			{Codigo: "SECUNDARIA", Nombre: "Secundaria"},
+			{Codigo: "TECNICO", Nombre: "Superior técnico", Alias: []string{"TECNICA", "SUPERIOR TECNICA", "TECNICO SUPERIOR"}},
+			{Codigo: "UNIVERSITARIO", Nombre: "Superior universitario", Alias: []string{"UNIVERSITARIA", "SUPERIOR UNIVERSITARIA", "UNIVERSIDAD"}},
+			{Codigo: "MAESTRIA", Nombre: "Maestría", Alias: []string{"MAGISTER"}},
+			{Codigo: "DOCTORADO", Nombre: "Doctorado"},
+		},
+	}
+
+	TipoDocumento = &Catalogo{
+		Nombre: "tipo_documento",
+		Items: []Item{
+			{Codigo: "DNI", Nombre: "DNI", Alias: []string{"DOCUMENTO NACIONAL DE IDENTIDAD"}},
+			{Codigo: "CE", Nombre: "Carné de extranjería", Alias: []string{"C.E.", "CARNET DE EXTRANJERIA"}},
+			{Codigo: "PTP", Nombre: "Permiso Temporal de Permanencia", Alias: []string{"CPP", "CARNE DE PERMISO TEMPORAL DE PERMANENCIA"}},
+			{Codigo: "PASAPORTE", Nombre: "Pasaporte", Alias: []string{"PAS"}},
+		},
+	}
+
+	TipoVia = &Catalogo{
+		Nombre: "tipo_via",
+		Items: []Item{
+			{Codigo: "AV", Nombre: "Avenida", Alias: []string{"AV.", "AVDA"}},
+			{Codigo: "JR", Nombre: "Jirón", Alias: []string{"JR."}},
+			{Codigo: "CAL", Nombre: "Calle", Alias: []string{"CA", "CALL"}},
+			{Codigo: "PJE", Nombre: "Pasaje", Alias: []string{"PJE.", "PSJE"}},
+			{Codigo: "ALM", Nombre: "Alameda"},
+			{Codigo: "MAL", Nombre: "Malecón"},
+			{Codigo: "OVA", Nombre: "Óvalo"},
+			{Codigo: "PRQ", Nombre: "Parque"},
+			{Codigo: "PLZ", Nombre: "Plaza"},
+			{Codigo: "CAR", Nombre: "Carretera", Alias: []string{"CARR"}},
+			{Codigo: "PROL", Nombre: "Prolongación", Alias: []string{"PROLONG"}},
+			{Codigo: "CAM", Nombre: "Camino"},
+			{Codigo: "TRO", Nombre: "Trocha"},
+			{Codigo: "BLK", Nombre: "Block", Alias: []string{"BLOCK"}},
+			{Codigo: "OTR", Nombre: "Otros"},
+		},
+	}
+
+	NivelIdioma = &Catalogo{
+		Nombre: "nivel_idioma",
+		Items: []Item{
+			{Codigo: "BASICO", Nombre: "Básico", Alias: []string{"BAJO", "ELEMENTAL"}},
+			{Codigo: "INTERMEDIO", Nombre: "Intermedio", Alias: []string{"REGULAR", "MEDIO"}},
+			{Codigo: "AVANZADO", Nombre: "Avanzado", Alias: []string{"ALTO", "BUENO", "MUY BUENO"}},
+			{Codigo: "NATIVO", Nombre: "Nativo", Alias: []string{"LENGUA MATERNA"}},
+		},
+	}
+)
+
+// catalogos catálogos publicados además del de bancos
+var catalogos = []*Catalogo{AFP, RegimenSalud, Parentesco, NivelEducativo, NivelIdioma, TipoVia, TipoDocumento}
+
+// Lista catálogo tal como se publica en /api/catalogos. Version es un resumen de Items: cambia
+// cada vez que se agregan, quitan o renombran valores para que el frontend sepa cuándo
+// refrescar su copia, sin depender de que alguien actualice una fecha a mano.
+type Lista struct {
+	Nombre  string `json:"nombre"`
+	Version string `json:"version"`
+	Items   any    `json:"items"`
+}
+
+// listas catálogos publicados; el contenido es parte del binario, así que las versiones se
+// calculan una sola vez
+var listas = sync.OnceValue(func() []Lista {
+	listas := []Lista{{Nombre: "bancos", Version: contentVersion(Bancos), Items: Bancos}}
+	for _, c := range catalogos {
+		listas = append(listas, Lista{Nombre: c.Nombre, Version: contentVersion(c.Items), Items: c.Items})
+	}
+	return listas
+})
+
+// Listas todos los catálogos publicados
+func Listas() []Lista {
+	return slices.Clone(listas())
+}
+
+// ListaByNombre busca un catálogo publicado por su nombre
+func ListaByNombre(nombre string) (Lista, bool) {
+	for _, l := range Listas() {
+		if l.Nombre == nombre {
+			return l, true
+		}
+	}
+	return Lista{}, false
+}
+
+// Version resumen de las versiones de las listas; cambia si cambia cualquiera de ellas
+func Version(listas []Lista) string {
+	h := sha256.New()
+	for _, l := range listas {
+		h.Write([]byte(l.Nombre + "@" + l.Version + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// contentVersion resumen del contenido tal como se publica en JSON
+func contentVersion(items any) string {
+	data, err := json.Marshal(items)
+	if err != nil {
+		panic(err)
+	}
+	return hashVersion(data)
+}
+
+// hashVersion primeros 16 dígitos hexadecimales del SHA-256 de data
+func hashVersion(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// matches indica si la clave normalizada es el código, el nombre o un alias del valor
+func matches(key, codigo, nombre string, alias []string) bool {
+	if key == codigo || key == normalize(nombre) {
+		return true
+	}
+	for _, a := range alias {
+		if key == a {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize mayúsculas sin tildes ni espacios repetidos
+func normalize(value string) string {
+	return strings.ToUpper(accents.Replace(strings.Join(strings.Fields(value), " ")))
+}
@@ -0,0 +1,33 @@
+package catalog
+
+import "testing"
+
+func TestContentVersionCambiaConElContenido(t *testing.T) {
+	items := []Item{{Codigo: "AV", Nombre: "Avenida"}, {Codigo: "JR", Nombre: "Jirón"}}
+	want := contentVersion(items)
+	if got := contentVersion([]Item{{Codigo: "AV", Nombre: "Avenida"}, {Codigo: "JR", Nombre: "Jirón"}}); got != want {
+		t.Fatalf("la versión cambió sin cambiar el contenido: %s != %s", got, want)
+	}
+
+	items[1].Nombre = "Jiron"
+	if contentVersion(items) == want {
+		t.Fatal("la versión no cambió al renombrar un valor")
+	}
+	if contentVersion(items[:1]) == want {
+		t.Fatal("la versión no cambió al quitar un valor")
+	}
+}
+
+func TestListasVersion(t *testing.T) {
+	listas := Listas()
+	seen := make(map[string]bool, len(listas))
+	for _, l := range listas {
+		if len(l.Version) != 16 || seen[l.Version] {
+			t.Errorf("versión inválida o repetida para %s: %q", l.Nombre, l.Version)
+		}
+		seen[l.Version] = true
+	}
+	if Version(listas) != Version(Listas()) {
+		t.Fatal("la versión de los catálogos no es estable")
+	}
+}
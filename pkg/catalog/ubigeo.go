@@ -0,0 +1,151 @@
+package catalog
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// UbigeoVersion versión de la tabla de ubigeo embebida: un resumen del archivo, así cambia
+// al reemplazarlo sin tener que actualizar una fecha
+var UbigeoVersion = sync.OnceValue(func() string {
+	return hashVersion([]byte(ubigeoCSV))
+})
+
+//go:embed ubigeo.csv
+var ubigeoCSV string
+
+// Ubigeo departamento, provincia o distrito; el código tiene 2, 4 o 6 dígitos según el nivel
+type Ubigeo struct {
+	Codigo string `json:"codigo"`
+	Nombre string `json:"nombre"`
+}
+
+// ubigeoTable tabla de ubigeo indexada por código y con los hijos de cada nivel en el orden
+// del archivo
+type ubigeoTable struct {
+	byCodigo map[string]Ubigeo
+	children map[string][]Ubigeo
+}
+
+// ubigeos carga la tabla embebida una sola vez; el archivo es parte del binario, así que un
+// error de formato es un error de programación
+var ubigeos = sync.OnceValue(func() *ubigeoTable {
+	table, err := parseUbigeo(strings.NewReader(ubigeoCSV))
+	if err != nil {
+		panic(err)
+	}
+	return table
+})
+
+// parseUbigeo lee el CSV ubigeo,nombre; las líneas que empiezan con # son comentarios
+func parseUbigeo(r io.Reader) (*ubigeoTable, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo tabla de ubigeo: %w", err)
+	}
+
+	table := &ubigeoTable{
+		byCodigo: make(map[string]Ubigeo, len(records)),
+		children: make(map[string][]Ubigeo),
+	}
+	for i, record := range records[1:] {
+		u := Ubigeo{Codigo: strings.TrimSpace(record[0]), Nombre: strings.TrimSpace(record[1])}
+		if n := len(u.Codigo); n != 2 && n != 4 && n != 6 || strings.Trim(u.Codigo, "0123456789") != "" {
+			return nil, fmt.Errorf("tabla de ubigeo, registro %d: código %q inválido", i+2, u.Codigo)
+		}
+		parent := u.Codigo[:len(u.Codigo)-2]
+		if _, ok := table.byCodigo[parent]; parent != "" && !ok {
+			return nil, fmt.Errorf("tabla de ubigeo, registro %d: %s no tiene un nivel superior", i+2, u.Codigo)
+		}
+		table.byCodigo[u.Codigo] = u
+		table.children[parent] = append(table.children[parent], u)
+	}
+	return table, nil
+}
+
+// Departamentos lista los departamentos
+func Departamentos() []Ubigeo {
+	return ubigeos().children[""]
+}
+
+// Provincias lista las provincias del departamento indicado por su código de 2 dígitos
+func Provincias(departamento string) []Ubigeo {
+	if len(departamento) != 2 {
+		return nil
+	}
+	return ubigeos().children[departamento]
+}
+
+// Distritos lista los distritos de la provincia indicada por su código de 4 dígitos
+func Distritos(provincia string) []Ubigeo {
+	if len(provincia) != 4 {
+		return nil
+	}
+	return ubigeos().children[provincia]
+}
+
+// UbigeoByCodigo busca un departamento, provincia o distrito por su código
+func UbigeoByCodigo(codigo string) (Ubigeo, bool) {
+	u, ok := ubigeos().byCodigo[codigo]
+	return u, ok
+}
+
+// UbigeoNombres nombres del departamento, la provincia y el distrito del código de 6 dígitos;
+// el distrito tiene que estar en la tabla
+func UbigeoNombres(codigo string) (departamento, provincia, distrito string, ok bool) {
+	if len(codigo) != 6 {
+		return "", "", "", false
+	}
+	table := ubigeos()
+	dist, ok := table.byCodigo[codigo]
+	if !ok {
+		return "", "", "", false
+	}
+	return table.byCodigo[codigo[:2]].Nombre, table.byCodigo[codigo[:4]].Nombre, dist.Nombre, true
+}
+
+// FindDistrito busca el distrito por los nombres del departamento, la provincia y el
+// distrito, sin distinguir mayúsculas ni tildes. Devuelve el distrito si la combinación existe.
+func FindDistrito(departamento, provincia, distrito string) (Ubigeo, bool) {
+	prov, ok := FindProvincia(departamento, provincia)
+	if !ok {
+		return Ubigeo{}, false
+	}
+	return findChild(prov.Codigo, distrito)
+}
+
+// FindProvincia busca la provincia por los nombres del departamento y la provincia
+func FindProvincia(departamento, provincia string) (Ubigeo, bool) {
+	dep, ok := findChild("", departamento)
+	if !ok {
+		return Ubigeo{}, false
+	}
+	return findChild(dep.Codigo, provincia)
+}
+
+// SameNombre compara dos nombres sin distinguir mayúsculas, tildes ni espacios repetidos
+func SameNombre(a, b string) bool {
+	return normalize(a) == normalize(b)
+}
+
+// findChild busca por nombre entre los hijos del código indicado
+func findChild(parent, nombre string) (Ubigeo, bool) {
+	key := normalize(nombre)
+	if key == "" {
+		return Ubigeo{}, false
+	}
+	for _, u := range ubigeos().children[parent] {
+		if normalize(u.Nombre) == key {
+			return u, true
+		}
+	}
+	return Ubigeo{}, false
+}
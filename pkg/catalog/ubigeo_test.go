@@ -0,0 +1,63 @@
+package catalog
+
+import "testing"
+
+func TestUbigeoTable(t *testing.T) {
+	if n := len(Departamentos()); n != 25 {
+		t.Errorf("departamentos = %d, want 25", n)
+	}
+	provincias := 0
+	for _, dep := range Departamentos() {
+		provincias += len(Provincias(dep.Codigo))
+	}
+	if provincias != 196 {
+		t.Errorf("provincias = %d, want 196", provincias)
+	}
+
+}
+
+func TestUbigeoNombres(t *testing.T) {
+	tests := []struct {
+		codigo          string
+		dep, prov, dist string
+		ok              bool
+	}{
+		{"080108", "Cusco", "Cusco", "Wanchaq", true},
+		{"160101", "Loreto", "Maynas", "Iquitos", true},
+		{"250101", "Ucayali", "Coronel Portillo", "Callería", true},
+		{"170301", "Madre de Dios", "Tahuamanu", "Iñapari", true},
+		{"040101", "Arequipa", "Arequipa", "Arequipa", true},
+		// El distrito tiene que estar en la tabla
+		{"080109", "", "", "", false},
+		{"010199", "", "", "", false},
+		{"150199", "", "", "", false},
+		{"169901", "", "", "", false},
+		{"160200", "", "", "", false},
+		{"1602", "", "", "", false},
+		{"16020A", "", "", "", false},
+	}
+	for _, tt := range tests {
+		dep, prov, dist, ok := UbigeoNombres(tt.codigo)
+		if ok != tt.ok || dep != tt.dep || prov != tt.prov || dist != tt.dist {
+			t.Errorf("UbigeoNombres(%q) = %q, %q, %q, %v; want %q, %q, %q, %v",
+				tt.codigo, dep, prov, dist, ok, tt.dep, tt.prov, tt.dist, tt.ok)
+		}
+	}
+}
+
+func TestFindDistrito(t *testing.T) {
+	u, ok := FindDistrito("cusco", "CUSCO", "wanchaq")
+	if !ok || u.Codigo != "080108" {
+		t.Errorf("FindDistrito Wanchaq = %+v, %v", u, ok)
+	}
+	u, ok = FindDistrito("Loreto", "Maynas", "iquitos")
+	if !ok || u.Codigo != "160101" {
+		t.Errorf("FindDistrito Iquitos = %+v, %v", u, ok)
+	}
+	if _, ok := FindDistrito("Loreto", "Maynas", "Yurimaguas"); ok {
+		t.Error("Yurimaguas no es distrito de Maynas")
+	}
+	if p, ok := FindProvincia("loreto", "alto amazonas"); !ok || p.Codigo != "1602" {
+		t.Errorf("FindProvincia Alto Amazonas = %+v, %v", p, ok)
+	}
+}
@@ -1,42 +1,615 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
 	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// Backends de almacenamiento soportados
+const (
+	StorageFirestore = "firestore"
+	StorageMemory    = "memory"
 )
 
 // Config contiene toda la configuración de la aplicación
 type Config struct {
-	Firebase FirebaseConfig
+	Firebase FirebaseConfig `json:"firebase" yaml:"firebase"`
+	Timeouts TimeoutsConfig `json:"timeouts" yaml:"timeouts"`
+	Limits   LimitsConfig   `json:"limits" yaml:"limits"`
+	CORS     CORSConfig     `json:"cors" yaml:"cors"`
+	Storage  StorageConfig  `json:"storage" yaml:"storage"`
+	Branding BrandingConfig `json:"branding" yaml:"branding"`
+	// Tenants branding por empresa; los campos vacíos se heredan de Branding
+	Tenants   map[string]BrandingConfig `json:"tenants" yaml:"tenants"`
+	Features  FeaturesConfig            `json:"features" yaml:"features"`
+	RateLimit RateLimitConfig           `json:"rate_limit" yaml:"rate_limit"`
+	Proxy     ProxyConfig               `json:"proxy" yaml:"proxy"`
+	Jobs      JobsConfig                `json:"jobs" yaml:"jobs"`
+	Blob      BlobConfig                `json:"blob" yaml:"blob"`
+	Verify    VerifyConfig              `json:"verify" yaml:"verify"`
+	Signing   SigningConfig             `json:"signing" yaml:"signing"`
+	// PDFSecurity protección aplicada a todos los PDF; la petición solo puede agregar protección
+	PDFSecurity PDFSecurityConfig `json:"pdf_security" yaml:"pdf_security"`
+	// Certificados plantillas y firmante de los certificados y constancias de trabajo
+	Certificados CertificadosConfig `json:"certificados" yaml:"certificados"`
+	LogLevel     string             `json:"log_level" yaml:"log_level"`
 }
 
 // FirebaseConfig configuración de Firebase
 type FirebaseConfig struct {
-	ProjectID   string
-	Credentials string
+	ProjectID   string `json:"project_id" yaml:"project_id"`
+	Credentials string `json:"credentials" yaml:"credentials"`
+	// EmulatorHost si está definido se conecta al emulador de Firestore sin credenciales
+	EmulatorHost string `json:"emulator_host" yaml:"emulator_host"`
 }
 
-// Load carga la configuración desde variables de entorno
-func Load() (*Config, error) {
-	firebaseProjectID := os.Getenv("FIREBASE_PROJECT_ID")
-	firebaseCredentials := os.Getenv("FIREBASE_CREDENTIALS")
+// TimeoutsConfig tiempos máximos de las operaciones
+type TimeoutsConfig struct {
+	Request Duration `json:"request" yaml:"request"`
+	PDF     Duration `json:"pdf" yaml:"pdf"`
+	Import  Duration `json:"import" yaml:"import"`
+	Export  Duration `json:"export" yaml:"export"`
+}
+
+// LimitsConfig límites de los listados y del tamaño de las peticiones
+type LimitsConfig struct {
+	ListDefault       int   `json:"list_default" yaml:"list_default"`
+	ListMax           int   `json:"list_max" yaml:"list_max"`
+	MaxBodyBytes      int64 `json:"max_body_bytes" yaml:"max_body_bytes"`
+	MaxFotoBytes      int   `json:"max_foto_bytes" yaml:"max_foto_bytes"`
+	MaxHijos          int   `json:"max_hijos" yaml:"max_hijos"`
+	MaxCapacitaciones int   `json:"max_capacitaciones" yaml:"max_capacitaciones"`
+	MaxExperiencia    int   `json:"max_experiencia" yaml:"max_experiencia"`
+	MaxImportRows     int   `json:"max_import_rows" yaml:"max_import_rows"`
+	PDFConcurrency    int   `json:"pdf_concurrency" yaml:"pdf_concurrency"`
+}
+
+// RequestLimits devuelve los límites usados al validar el registro de usuarios
+func (l LimitsConfig) RequestLimits() models.RequestLimits {
+	return models.RequestLimits{
+		MaxHijos:          l.MaxHijos,
+		MaxCapacitaciones: l.MaxCapacitaciones,
+		MaxExperiencia:    l.MaxExperiencia,
+		MaxFotoBytes:      l.MaxFotoBytes,
+	}
+}
 
-	if firebaseCredentials == "" {
-		return nil, fmt.Errorf("FIREBASE_CREDENTIALS no está configurado")
+// CORSConfig orígenes permitidos para CORS
+type CORSConfig struct {
+	AllowedOrigins []string `json:"allowed_origins" yaml:"allowed_origins"`
+}
+
+// StorageConfig backend de persistencia
+type StorageConfig struct {
+	Backend string `json:"backend" yaml:"backend"`
+}
+
+// BrandingConfig datos de la empresa y plantilla usados en los documentos
+type BrandingConfig struct {
+	CompanyName string `json:"company_name" yaml:"company_name"`
+	RUC         string `json:"ruc" yaml:"ruc"`
+	LogoPath    string `json:"logo_path" yaml:"logo_path"`
+	// PrimaryColor y TextColor en formato hexadecimal (#228B22)
+	PrimaryColor string `json:"primary_color" yaml:"primary_color"`
+	TextColor    string `json:"text_color" yaml:"text_color"`
+	FontFamily   string `json:"font_family" yaml:"font_family"`
+	// FontFiles archivos TTF por estilo (normal, bold, italic, bold_italic) registrados con FontFamily
+	FontFiles  map[string]string `json:"font_files" yaml:"font_files"`
+	FooterText string            `json:"footer_text" yaml:"footer_text"`
+	// Sections secciones de la ficha a incluir; vacío incluye todas
+	Sections []string `json:"sections" yaml:"sections"`
+}
+
+// Merge completa los campos vacíos con los valores de base
+func (b BrandingConfig) Merge(base BrandingConfig) BrandingConfig {
+	merged := base
+	if b.CompanyName != "" {
+		merged.CompanyName = b.CompanyName
+	}
+	if b.RUC != "" {
+		merged.RUC = b.RUC
+	}
+	if b.LogoPath != "" {
+		merged.LogoPath = b.LogoPath
+	}
+	if b.PrimaryColor != "" {
+		merged.PrimaryColor = b.PrimaryColor
 	}
+	if b.TextColor != "" {
+		merged.TextColor = b.TextColor
+	}
+	if b.FontFamily != "" {
+		merged.FontFamily = b.FontFamily
+		merged.FontFiles = b.FontFiles
+	}
+	if b.FooterText != "" {
+		merged.FooterText = b.FooterText
+	}
+	if len(b.Sections) > 0 {
+		merged.Sections = b.Sections
+	}
+	return merged
+}
+
+// FeaturesConfig funcionalidades que se pueden activar o desactivar
+type FeaturesConfig struct {
+	Registro bool `json:"registro" yaml:"registro"`
+	PDF      bool `json:"pdf" yaml:"pdf"`
+	Metrics  bool `json:"metrics" yaml:"metrics"`
+}
+
+// RateLimitConfig límite de peticiones por IP (token bucket)
+type RateLimitConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// RequestsPerMinute ritmo de recarga del bucket
+	RequestsPerMinute int `json:"requests_per_minute" yaml:"requests_per_minute"`
+	// Burst capacidad máxima del bucket
+	Burst int `json:"burst" yaml:"burst"`
+	// Backend memory (por instancia) o firestore (compartido entre instancias)
+	Backend string `json:"backend" yaml:"backend"`
+}
+
+// ProxyConfig proxies delante de la API; de ellos se acepta la IP del cliente que indican
+// X-Real-IP y X-Forwarded-For, usada por el rate limit y la marca de agua de los PDF
+type ProxyConfig struct {
+	// Trusted IPs o rangos CIDR de los proxies de confianza
+	Trusted []string `json:"trusted" yaml:"trusted"`
+	// Vercel acepta los headers de cualquier petición porque Vercel los reescribe en su borde;
+	// por defecto se activa si está definida la variable VERCEL que agrega Vercel
+	Vercel bool `json:"vercel" yaml:"vercel"`
+}
+
+// JobsConfig reportes asíncronos
+type JobsConfig struct {
+	// InProcess ejecuta los trabajos en el mismo proceso al encolarlos. Solo sirve en desarrollo
+	// local o en un servidor de un solo proceso: en Vercel la instancia se congela después de
+	// responder y el trabajo no avanza; ahí los procesa el endpoint de cron.
+	InProcess bool `json:"in_process" yaml:"in_process"`
+	// Timeout tiempo máximo de ejecución de un trabajo; pasado este tiempo se reintenta
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+	// MaxAttempts intentos antes de marcar el trabajo como fallido
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts"`
+	// ResultTTL tiempo durante el que se puede descargar el resultado
+	ResultTTL Duration `json:"result_ttl" yaml:"result_ttl"`
+	// CronSecret token que envía Vercel en Authorization: Bearer al llamar al cron
+	CronSecret string `json:"cron_secret" yaml:"cron_secret"`
+}
+
+// Backends de almacenamiento de archivos soportados
+const (
+	BlobMemory = "memory"
+	BlobFile   = "file"
+	BlobGCS    = "gcs"
+)
+
+// BlobConfig almacenamiento de los archivos generados por los reportes
+type BlobConfig struct {
+	// Backend memory (por instancia), file (directorio local) o gcs (Cloud Storage); vacío
+	// deshabilita los reportes. memory solo sirve con STORAGE_BACKEND memory: en Vercel cada
+	// función es un proceso distinto y la descarga no vería el archivo generado por el cron.
+	Backend string `json:"backend" yaml:"backend"`
+	Dir     string `json:"dir" yaml:"dir"`
+	Bucket  string `json:"bucket" yaml:"bucket"`
+}
+
+// minVerifySecretLength longitud mínima de la clave con la que se firman los QR
+const minVerifySecretLength = 32
 
+// VerifyConfig código QR de verificación de las fichas
+type VerifyConfig struct {
+	// Secret clave HMAC con la que se firman los tokens; vacío deshabilita el QR
+	Secret string `json:"secret" yaml:"secret"`
+	// BaseURL URL pública del despliegue a la que apunta el QR
+	BaseURL string `json:"base_url" yaml:"base_url"`
+}
+
+// Enabled indica si las fichas incluyen el QR de verificación
+func (v VerifyConfig) Enabled() bool {
+	return v.Secret != ""
+}
+
+// SigningConfig firma digital (PAdES) de los PDF. El certificado y la clave se indican en
+// PEM o como rutas a archivos (desarrollo); vacío deshabilita la firma.
+type SigningConfig struct {
+	Cert     string `json:"cert" yaml:"cert"`
+	Key      string `json:"key" yaml:"key"`
+	CertFile string `json:"cert_file" yaml:"cert_file"`
+	KeyFile  string `json:"key_file" yaml:"key_file"`
+	// Name firmante mostrado por el lector; vacío usa el CN del certificado
+	Name        string `json:"name" yaml:"name"`
+	Reason      string `json:"reason" yaml:"reason"`
+	Location    string `json:"location" yaml:"location"`
+	ContactInfo string `json:"contact_info" yaml:"contact_info"`
+}
+
+// Enabled indica si los PDF se firman
+func (s SigningConfig) Enabled() bool {
+	return s.Cert != "" || s.CertFile != ""
+}
+
+// minOwnerPasswordLength longitud mínima de la contraseña de propietario de los PDF
+const minOwnerPasswordLength = 12
+
+// PDFSecurityConfig marca de agua, contraseña y permisos de los PDF. La contraseña y las
+// restricciones de impresión y copia requieren cifrar el documento, y un PDF cifrado no se
+// puede firmar: no se pueden configurar junto con la firma.
+type PDFSecurityConfig struct {
+	Watermark     bool   `json:"watermark" yaml:"watermark"`
+	WatermarkText string `json:"watermark_text" yaml:"watermark_text"`
+	// PasswordDNI cifra cada ficha con el número de documento del trabajador como contraseña de apertura
+	PasswordDNI bool `json:"password_dni" yaml:"password_dni"`
+	// OwnerPassword permite quitar las restricciones; vacío usa una aleatoria por documento
+	OwnerPassword string `json:"owner_password" yaml:"owner_password"`
+	RestrictPrint bool   `json:"restrict_print" yaml:"restrict_print"`
+	RestrictCopy  bool   `json:"restrict_copy" yaml:"restrict_copy"`
+}
+
+// CertificadosConfig plantillas de los certificados y constancias de trabajo. Las plantillas
+// usan marcadores como {trabajador}, {tipo_documento}, {numero_documento}, {puesto}, {fecha_ingreso} y {fecha_cese}.
+type CertificadosConfig struct {
+	// Certificado y Constancia texto de la carta; vacío usa la plantilla por defecto
+	Certificado   string `json:"certificado" yaml:"certificado"`
+	Constancia    string `json:"constancia" yaml:"constancia"`
+	Ciudad        string `json:"ciudad" yaml:"ciudad"`
+	Firmante      string `json:"firmante" yaml:"firmante"`
+	CargoFirmante string `json:"cargo_firmante" yaml:"cargo_firmante"`
+}
+
+// Duration time.Duration que se puede leer como texto ("10s", "1m")
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText implementa encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalText implementa encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// ValidationErrors lista de problemas encontrados en la configuración
+type ValidationErrors []string
+
+func (e ValidationErrors) Error() string {
+	return "configuración inválida: " + strings.Join(e, "; ")
+}
+
+// Default devuelve la configuración por defecto
+func Default() *Config {
 	return &Config{
-		Firebase: FirebaseConfig{
-			ProjectID:   firebaseProjectID,
-			Credentials: firebaseCredentials,
+		Timeouts: TimeoutsConfig{
+			Request: Duration{10 * time.Second},
+			PDF:     Duration{20 * time.Second},
+			Import:  Duration{60 * time.Second},
+			Export:  Duration{60 * time.Second},
+		},
+		Limits: LimitsConfig{
+			ListDefault:       100,
+			ListMax:           500,
+			MaxBodyBytes:      6 << 20,
+			MaxFotoBytes:      2 << 20,
+			MaxHijos:          15,
+			MaxCapacitaciones: 50,
+			MaxExperiencia:    30,
+			MaxImportRows:     1000,
+			PDFConcurrency:    4,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+		},
+		Storage: StorageConfig{
+			Backend: StorageFirestore,
 		},
-	}, nil
+		Branding: BrandingConfig{
+			CompanyName: "RAINFOREST ENTERPRISE",
+		},
+		Features: FeaturesConfig{
+			Registro: true,
+			PDF:      true,
+			Metrics:  true,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           true,
+			RequestsPerMinute: 60,
+			Burst:             20,
+			Backend:           StorageMemory,
+		},
+		Proxy: ProxyConfig{
+			Vercel: os.Getenv("VERCEL") != "",
+		},
+		Jobs: JobsConfig{
+			Timeout:     Duration{4 * time.Minute},
+			MaxAttempts: 3,
+			ResultTTL:   Duration{24 * time.Hour},
+		},
+		LogLevel: "info",
+	}
+}
+
+// Load carga la configuración: valores por defecto, archivo opcional (CONFIG_FILE)
+// y variables de entorno, en ese orden de prioridad. Toda variable admite la
+// variante *_FILE con la ruta a un archivo secreto. Devuelve todos los problemas
+// encontrados en un solo error.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	var problems ValidationErrors
+
+	if path, ok, err := lookup("CONFIG_FILE"); err != nil {
+		problems = append(problems, err.Error())
+	} else if ok {
+		if err := cfg.loadFile(path); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	problems = append(problems, cfg.loadEnv()...)
+
+	if err := cfg.Validate(); err != nil {
+		if vErrs, ok := err.(ValidationErrors); ok {
+			problems = append(problems, vErrs...)
+		} else {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	return cfg, nil
 }
 
-// Validate valida que la configuración sea correcta
+// Validate valida que la configuración sea correcta y reporta todos los problemas a la vez
 func (c *Config) Validate() error {
-	if c.Firebase.Credentials == "" {
-		return fmt.Errorf("firebase credentials son requeridas")
+	var problems ValidationErrors
+
+	switch c.Storage.Backend {
+	case StorageFirestore:
+		if !c.UseEmulator() {
+			if c.Firebase.Credentials == "" {
+				problems = append(problems, "FIREBASE_CREDENTIALS no está configurado")
+			} else if !json.Valid([]byte(c.Firebase.Credentials)) {
+				problems = append(problems, "FIREBASE_CREDENTIALS no es un JSON válido")
+			}
+		}
+	case StorageMemory:
+	default:
+		problems = append(problems, fmt.Sprintf("STORAGE_BACKEND %q no soportado (firestore, memory)", c.Storage.Backend))
+	}
+
+	if c.Timeouts.Request.Duration <= 0 {
+		problems = append(problems, "REQUEST_TIMEOUT debe ser mayor a 0")
+	}
+	if c.Timeouts.PDF.Duration <= 0 {
+		problems = append(problems, "PDF_TIMEOUT debe ser mayor a 0")
+	}
+	if c.Timeouts.Import.Duration <= 0 {
+		problems = append(problems, "IMPORT_TIMEOUT debe ser mayor a 0")
+	}
+	if c.Timeouts.Export.Duration <= 0 {
+		problems = append(problems, "EXPORT_TIMEOUT debe ser mayor a 0")
+	}
+
+	if c.Limits.ListDefault <= 0 {
+		problems = append(problems, "LIST_DEFAULT_LIMIT debe ser mayor a 0")
+	}
+	if c.Limits.ListMax < c.Limits.ListDefault {
+		problems = append(problems, "LIST_MAX_LIMIT debe ser mayor o igual a LIST_DEFAULT_LIMIT")
+	}
+
+	if c.Limits.MaxBodyBytes <= 0 {
+		problems = append(problems, "MAX_BODY_BYTES debe ser mayor a 0")
+	}
+	if c.Limits.MaxFotoBytes <= 0 {
+		problems = append(problems, "MAX_FOTO_BYTES debe ser mayor a 0")
+	}
+	if c.Limits.MaxHijos < 0 || c.Limits.MaxCapacitaciones < 0 || c.Limits.MaxExperiencia < 0 {
+		problems = append(problems, "MAX_HIJOS, MAX_CAPACITACIONES y MAX_EXPERIENCIA no pueden ser negativos")
+	}
+	if c.Limits.MaxImportRows <= 0 {
+		problems = append(problems, "MAX_IMPORT_ROWS debe ser mayor a 0")
+	}
+	if c.Limits.PDFConcurrency <= 0 {
+		problems = append(problems, "PDF_CONCURRENCY debe ser mayor a 0")
+	}
+
+	if c.RateLimit.Enabled {
+		if c.RateLimit.RequestsPerMinute <= 0 {
+			problems = append(problems, "RATE_LIMIT_RPM debe ser mayor a 0")
+		}
+		if c.RateLimit.Burst <= 0 {
+			problems = append(problems, "RATE_LIMIT_BURST debe ser mayor a 0")
+		}
+		switch c.RateLimit.Backend {
+		case StorageMemory:
+		case StorageFirestore:
+			if c.Storage.Backend != StorageFirestore {
+				problems = append(problems, "RATE_LIMIT_BACKEND firestore requiere STORAGE_BACKEND firestore")
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("RATE_LIMIT_BACKEND %q no soportado (memory, firestore)", c.RateLimit.Backend))
+		}
+	}
+
+	for _, proxy := range c.Proxy.Trusted {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				problems = append(problems, fmt.Sprintf("TRUSTED_PROXIES: %q no es una IP ni un rango CIDR", proxy))
+			}
+		}
+	}
+
+	if c.Jobs.Timeout.Duration <= 0 {
+		problems = append(problems, "JOBS_TIMEOUT debe ser mayor a 0")
+	}
+	if c.Jobs.MaxAttempts <= 0 {
+		problems = append(problems, "JOBS_MAX_ATTEMPTS debe ser mayor a 0")
+	}
+	if c.Jobs.ResultTTL.Duration <= 0 {
+		problems = append(problems, "JOBS_RESULT_TTL debe ser mayor a 0")
+	}
+	switch c.Blob.Backend {
+	case "":
+	case BlobMemory:
+		if c.Storage.Backend != StorageMemory {
+			problems = append(problems, "BLOB_BACKEND memory requiere STORAGE_BACKEND memory (use gcs con firestore)")
+		}
+	case BlobFile:
+		if c.Blob.Dir == "" {
+			problems = append(problems, "BLOB_DIR es requerido con BLOB_BACKEND file")
+		}
+	case BlobGCS:
+		if c.Blob.Bucket == "" {
+			problems = append(problems, "BLOB_BUCKET es requerido con BLOB_BACKEND gcs")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("BLOB_BACKEND %q no soportado (memory, file, gcs)", c.Blob.Backend))
+	}
+
+	if c.Verify.Enabled() {
+		if len(c.Verify.Secret) < minVerifySecretLength {
+			problems = append(problems, fmt.Sprintf("VERIFY_SECRET debe tener al menos %d caracteres", minVerifySecretLength))
+		}
+		if u, err := url.Parse(c.Verify.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, "VERIFY_BASE_URL debe ser una URL http(s) válida")
+		}
+	}
+
+	if c.Signing.Cert != "" && c.Signing.CertFile != "" {
+		problems = append(problems, "SIGN_CERT y signing.cert_file no pueden usarse a la vez")
+	}
+	if c.Signing.Key != "" && c.Signing.KeyFile != "" {
+		problems = append(problems, "SIGN_KEY y signing.key_file no pueden usarse a la vez")
+	}
+	if c.Signing.Enabled() != (c.Signing.Key != "" || c.Signing.KeyFile != "") {
+		problems = append(problems, "la firma de PDF requiere el certificado y la clave privada")
+	}
+
+	if c.Signing.Enabled() && (c.PDFSecurity.PasswordDNI || c.PDFSecurity.RestrictPrint || c.PDFSecurity.RestrictCopy) {
+		problems = append(problems, "la firma de PDF no se puede usar con PDF_PASSWORD_DNI, PDF_RESTRICT_PRINT ni PDF_RESTRICT_COPY: los PDF cifrados no se pueden firmar")
+	}
+	if c.PDFSecurity.OwnerPassword != "" && len(c.PDFSecurity.OwnerPassword) < minOwnerPasswordLength {
+		problems = append(problems, fmt.Sprintf("PDF_OWNER_PASSWORD debe tener al menos %d caracteres", minOwnerPasswordLength))
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		problems = append(problems, "CORS_ALLOWED_ORIGINS no puede estar vacío")
+	}
+
+	problems = append(problems, validateBranding("branding", c.Branding)...)
+	for name, tenant := range c.Tenants {
+		if strings.TrimSpace(name) == "" {
+			problems = append(problems, "tenants: el nombre del tenant no puede estar vacío")
+		}
+		problems = append(problems, validateBranding("tenants."+name, tenant.Merge(c.Branding))...)
+	}
+
+	if _, err := parseLogLevel(c.LogLevel); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if len(problems) > 0 {
+		return problems
 	}
 	return nil
 }
+
+// BrandingFor devuelve el branding del tenant indicado; vacío devuelve el branding por defecto
+func (c *Config) BrandingFor(tenant string) (BrandingConfig, bool) {
+	if tenant == "" {
+		return c.Branding, true
+	}
+	branding, ok := c.Tenants[tenant]
+	if !ok {
+		return BrandingConfig{}, false
+	}
+	return branding.Merge(c.Branding), true
+}
+
+// fontFamilies familias de fuente incluidas en el generador de PDF
+var fontFamilies = map[string]bool{"arial": true, "helvetica": true, "courier": true}
+
+// hexColor formato de color #RRGGBB
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// validateBranding valida un branding y devuelve los problemas con el prefijo indicado
+func validateBranding(prefix string, b BrandingConfig) ValidationErrors {
+	var problems ValidationErrors
+
+	if strings.TrimSpace(b.CompanyName) == "" {
+		problems = append(problems, prefix+": company_name (COMPANY_NAME) es requerido")
+	}
+	if b.LogoPath != "" {
+		if _, err := os.Stat(b.LogoPath); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: logo_path (COMPANY_LOGO_PATH) no accesible: %v", prefix, err))
+		}
+	}
+	if b.PrimaryColor != "" && !hexColor.MatchString(b.PrimaryColor) {
+		problems = append(problems, fmt.Sprintf("%s: primary_color %q debe tener el formato #RRGGBB", prefix, b.PrimaryColor))
+	}
+	if b.TextColor != "" && !hexColor.MatchString(b.TextColor) {
+		problems = append(problems, fmt.Sprintf("%s: text_color %q debe tener el formato #RRGGBB", prefix, b.TextColor))
+	}
+
+	if len(b.FontFiles) > 0 {
+		if b.FontFamily == "" {
+			problems = append(problems, prefix+": font_files requiere font_family")
+		}
+		if _, ok := b.FontFiles["normal"]; !ok {
+			problems = append(problems, prefix+": font_files debe incluir el estilo normal")
+		}
+		for _, path := range b.FontFiles {
+			if _, err := os.Stat(path); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: fuente %q no accesible: %v", prefix, path, err))
+			}
+		}
+	} else if b.FontFamily != "" && !fontFamilies[strings.ToLower(b.FontFamily)] {
+		problems = append(problems, fmt.Sprintf("%s: font_family %q no soportada sin font_files (arial, helvetica, courier)", prefix, b.FontFamily))
+	}
+
+	return problems
+}
+
+// UseEmulator indica si se debe usar el emulador de Firestore
+func (c *Config) UseEmulator() bool {
+	return c.Firebase.EmulatorHost != ""
+}
+
+// SlogLevel devuelve el nivel de log configurado
+func (c *Config) SlogLevel() slog.Level {
+	level, _ := parseLogLevel(c.LogLevel)
+	return level
+}
+
+// parseLogLevel convierte el nivel de log textual a slog.Level
+func parseLogLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("LOG_LEVEL %q no soportado (debug, info, warn, error)", value)
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lookup obtiene una variable de entorno o, si existe KEY_FILE, el contenido del archivo
+func lookup(key string) (string, bool, error) {
+	if path, ok := os.LookupEnv(key + "_FILE"); ok && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE no se pudo leer: %w", key, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	}
+
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return "", false, nil
+	}
+	return value, true, nil
+}
+
+// loadFile carga la configuración desde un archivo YAML o JSON
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("CONFIG_FILE no se pudo leer: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".json":
+		err = json.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("CONFIG_FILE %q debe tener extensión .yaml, .yml o .json", path)
+	}
+	if err != nil {
+		return fmt.Errorf("CONFIG_FILE %q inválido: %w", path, err)
+	}
+
+	return nil
+}
+
+// envLoader acumula los errores de lectura de variables de entorno
+type envLoader struct {
+	problems ValidationErrors
+}
+
+func (l *envLoader) value(key string) (string, bool) {
+	value, ok, err := lookup(key)
+	if err != nil {
+		l.problems = append(l.problems, err.Error())
+		return "", false
+	}
+	return value, ok
+}
+
+func (l *envLoader) string(key string, dst *string) {
+	if value, ok := l.value(key); ok {
+		*dst = value
+	}
+}
+
+func (l *envLoader) int(key string, dst *int) {
+	if value, ok := l.value(key); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("%s debe ser un número entero: %q", key, value))
+			return
+		}
+		*dst = parsed
+	}
+}
+
+func (l *envLoader) int64(key string, dst *int64) {
+	if value, ok := l.value(key); ok {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("%s debe ser un número entero: %q", key, value))
+			return
+		}
+		*dst = parsed
+	}
+}
+
+func (l *envLoader) bool(key string, dst *bool) {
+	if value, ok := l.value(key); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("%s debe ser true o false: %q", key, value))
+			return
+		}
+		*dst = parsed
+	}
+}
+
+func (l *envLoader) duration(key string, dst *Duration) {
+	if value, ok := l.value(key); ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("%s debe ser una duración (ej. 10s): %q", key, value))
+			return
+		}
+		dst.Duration = parsed
+	}
+}
+
+func (l *envLoader) list(key string, dst *[]string) {
+	if value, ok := l.value(key); ok {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
+
+// loadEnv sobrescribe la configuración con las variables de entorno definidas
+func (c *Config) loadEnv() ValidationErrors {
+	l := &envLoader{}
+
+	l.string("FIREBASE_PROJECT_ID", &c.Firebase.ProjectID)
+	l.string("FIREBASE_CREDENTIALS", &c.Firebase.Credentials)
+	l.string("FIRESTORE_EMULATOR_HOST", &c.Firebase.EmulatorHost)
+
+	l.duration("REQUEST_TIMEOUT", &c.Timeouts.Request)
+	l.duration("PDF_TIMEOUT", &c.Timeouts.PDF)
+	l.duration("IMPORT_TIMEOUT", &c.Timeouts.Import)
+	l.duration("EXPORT_TIMEOUT", &c.Timeouts.Export)
+
+	l.int("LIST_DEFAULT_LIMIT", &c.Limits.ListDefault)
+	l.int("LIST_MAX_LIMIT", &c.Limits.ListMax)
+
+	l.int64("MAX_BODY_BYTES", &c.Limits.MaxBodyBytes)
+	l.int("MAX_FOTO_BYTES", &c.Limits.MaxFotoBytes)
+	l.int("MAX_HIJOS", &c.Limits.MaxHijos)
+	l.int("MAX_CAPACITACIONES", &c.Limits.MaxCapacitaciones)
+	l.int("MAX_EXPERIENCIA", &c.Limits.MaxExperiencia)
+	l.int("MAX_IMPORT_ROWS", &c.Limits.MaxImportRows)
+	l.int("PDF_CONCURRENCY", &c.Limits.PDFConcurrency)
+
+	l.list("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+
+	l.string("STORAGE_BACKEND", &c.Storage.Backend)
+
+	l.string("COMPANY_NAME", &c.Branding.CompanyName)
+	l.string("COMPANY_RUC", &c.Branding.RUC)
+	l.string("COMPANY_LOGO_PATH", &c.Branding.LogoPath)
+	l.string("COMPANY_PRIMARY_COLOR", &c.Branding.PrimaryColor)
+	l.string("COMPANY_TEXT_COLOR", &c.Branding.TextColor)
+	l.string("COMPANY_FONT_FAMILY", &c.Branding.FontFamily)
+	l.string("COMPANY_FOOTER_TEXT", &c.Branding.FooterText)
+	l.list("COMPANY_SECTIONS", &c.Branding.Sections)
+
+	l.bool("FEATURE_REGISTRO", &c.Features.Registro)
+	l.bool("FEATURE_PDF", &c.Features.PDF)
+	l.bool("FEATURE_METRICS", &c.Features.Metrics)
+
+	l.bool("RATE_LIMIT_ENABLED", &c.RateLimit.Enabled)
+	l.int("RATE_LIMIT_RPM", &c.RateLimit.RequestsPerMinute)
+	l.int("RATE_LIMIT_BURST", &c.RateLimit.Burst)
+	l.string("RATE_LIMIT_BACKEND", &c.RateLimit.Backend)
+
+	l.list("TRUSTED_PROXIES", &c.Proxy.Trusted)
+	l.bool("PROXY_VERCEL", &c.Proxy.Vercel)
+
+	l.bool("JOBS_IN_PROCESS", &c.Jobs.InProcess)
+	l.duration("JOBS_TIMEOUT", &c.Jobs.Timeout)
+	l.int("JOBS_MAX_ATTEMPTS", &c.Jobs.MaxAttempts)
+	l.duration("JOBS_RESULT_TTL", &c.Jobs.ResultTTL)
+	l.string("CRON_SECRET", &c.Jobs.CronSecret)
+
+	l.string("BLOB_BACKEND", &c.Blob.Backend)
+	l.string("BLOB_DIR", &c.Blob.Dir)
+	l.string("BLOB_BUCKET", &c.Blob.Bucket)
+
+	l.string("VERIFY_SECRET", &c.Verify.Secret)
+	l.string("VERIFY_BASE_URL", &c.Verify.BaseURL)
+
+	l.string("SIGN_CERT", &c.Signing.Cert)
+	l.string("SIGN_KEY", &c.Signing.Key)
+	l.string("SIGN_NAME", &c.Signing.Name)
+	l.string("SIGN_REASON", &c.Signing.Reason)
+	l.string("SIGN_LOCATION", &c.Signing.Location)
+	l.string("SIGN_CONTACT_INFO", &c.Signing.ContactInfo)
+
+	l.bool("PDF_WATERMARK", &c.PDFSecurity.Watermark)
+	l.string("PDF_WATERMARK_TEXT", &c.PDFSecurity.WatermarkText)
+	l.bool("PDF_PASSWORD_DNI", &c.PDFSecurity.PasswordDNI)
+	l.string("PDF_OWNER_PASSWORD", &c.PDFSecurity.OwnerPassword)
+	l.bool("PDF_RESTRICT_PRINT", &c.PDFSecurity.RestrictPrint)
+	l.bool("PDF_RESTRICT_COPY", &c.PDFSecurity.RestrictCopy)
+
+	l.string("CERTIFICADO_TEMPLATE", &c.Certificados.Certificado)
+	l.string("CONSTANCIA_TEMPLATE", &c.Certificados.Constancia)
+	l.string("CERTIFICADO_CIUDAD", &c.Certificados.Ciudad)
+	l.string("CERTIFICADO_FIRMANTE", &c.Certificados.Firmante)
+	l.string("CERTIFICADO_CARGO_FIRMANTE", &c.Certificados.CargoFirmante)
+
+	l.string("LOG_LEVEL", &c.LogLevel)
+
+	return l.problems
+}
@@ -3,15 +3,20 @@ package database
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 
 	"cloud.google.com/go/firestore"
 	firebase "firebase.google.com/go/v4"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 
 	"github.com/luispfcanales/rainforestapp/pkg/config"
 )
 
+// emulatorProjectID proyecto usado con el emulador cuando no se configura uno
+const emulatorProjectID = "demo-rainforest"
+
 var (
 	firestoreClient *firestore.Client
 	once            sync.Once
@@ -29,6 +34,10 @@ func GetFirestoreClient(ctx context.Context, cfg *config.Config) (*firestore.Cli
 
 // initializeFirestore inicializa el cliente de Firestore
 func initializeFirestore(ctx context.Context, cfg *config.Config) (*firestore.Client, error) {
+	if cfg.UseEmulator() {
+		return initializeEmulator(ctx, cfg)
+	}
+
 	// Configurar Firebase
 	firebaseConfig := &firebase.Config{
 		ProjectID: cfg.Firebase.ProjectID,
@@ -49,6 +58,42 @@ func initializeFirestore(ctx context.Context, cfg *config.Config) (*firestore.Cl
 	return client, nil
 }
 
+// initializeEmulator conecta al emulador de Firestore sin credenciales
+func initializeEmulator(ctx context.Context, cfg *config.Config) (*firestore.Client, error) {
+	// El SDK detecta el emulador a través de la variable de entorno, que puede
+	// no estar definida si la configuración provino de un archivo
+	if err := os.Setenv("FIRESTORE_EMULATOR_HOST", cfg.Firebase.EmulatorHost); err != nil {
+		return nil, fmt.Errorf("error configurando emulador: %w", err)
+	}
+
+	projectID := cfg.Firebase.ProjectID
+	if projectID == "" {
+		projectID = emulatorProjectID
+	}
+
+	client, err := firestore.NewClient(ctx, projectID, option.WithoutAuthentication())
+	if err != nil {
+		return nil, fmt.Errorf("error conectando al emulador de Firestore: %w", err)
+	}
+
+	return client, nil
+}
+
+// Ping verifica que el cliente de Firestore pueda leer realizando una consulta mínima
+func Ping(ctx context.Context, client *firestore.Client) error {
+	if client == nil {
+		return fmt.Errorf("cliente Firestore no inicializado")
+	}
+
+	iter := client.Collection("usuarios").Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	if _, err := iter.Next(); err != nil && err != iterator.Done {
+		return fmt.Errorf("error leyendo Firestore: %w", err)
+	}
+	return nil
+}
+
 // Close cierra la conexión de Firestore
 func Close() error {
 	if firestoreClient != nil {
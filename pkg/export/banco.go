@@ -0,0 +1,207 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// AperturaTXTContentType tipo MIME del archivo de apertura masiva de ancho fijo
+const AperturaTXTContentType = "text/plain; charset=us-ascii"
+
+// aperturaColumn campo del registro de apertura masiva; width es el ancho en el archivo de
+// ancho fijo
+type aperturaColumn struct {
+	name  string
+	width int
+	value func(u *models.Usuario, tipo string) string
+}
+
+// aperturaColumns registro de apertura masiva de cuentas. Es un formato interno de la
+// aplicación, no el layout de carga masiva de BCP: el banco entrega el suyo a cada empresa
+// con el convenio de pago de haberes y no lo publica, así que el archivo hay que adaptarlo a
+// ese layout antes de subirlo al portal del banco. En el archivo de ancho fijo cada registro
+// mide 253 caracteres: texto en mayúsculas sin tildes alineado a la izquierda y completado
+// con espacios, fechas AAAAMMDD.
+//
+//	pos  ancho  campo
+//	  1      1  tipo_documento   1 = DNI, 2 = C.E., 3 = PTP, 4 = pasaporte
+//	  2     12  numero_documento
+//	 14     20  apellido_paterno
+//	 34     20  apellido_materno
+//	 54     30  nombres
+//	 84      8  fecha_nacimiento
+//	 92      1  sexo             M / F
+//	 93      1  tipo_cuenta      S = sueldo, C = CTS
+//	 94      3  moneda           PEN
+//	 97     60  direccion
+//	157      9  celular
+//	166     50  email
+//	216      8  fecha_ingreso
+//	224     30  lugar_trabajo
+var aperturaColumns = []aperturaColumn{
+	{"tipo_documento", 1, func(u *models.Usuario, _ string) string { return documentoCodes[u.Documento().Tipo] }},
+	{"numero_documento", 12, func(u *models.Usuario, _ string) string { return u.Documento().Numero }},
+	{"apellido_paterno", 20, func(u *models.Usuario, _ string) string { return u.ApellidoPaterno }},
+	{"apellido_materno", 20, func(u *models.Usuario, _ string) string { return u.ApellidoMaterno }},
+	{"nombres", 30, func(u *models.Usuario, _ string) string { return u.Nombres }},
+	{"fecha_nacimiento", 8, func(u *models.Usuario, _ string) string { return compactDate(u.FechaNacimiento) }},
+	{"sexo", 1, func(u *models.Usuario, _ string) string { return sexoCode(u.Sexo) }},
+	{"tipo_cuenta", 1, func(_ *models.Usuario, tipo string) string { return cuentaCodes[tipo] }},
+	{"moneda", 3, func(*models.Usuario, string) string { return "PEN" }},
+	{"direccion", 60, func(u *models.Usuario, _ string) string { return u.DireccionDomicilio }},
+	{"celular", 9, func(u *models.Usuario, _ string) string { return celular(u.Telefono) }},
+	{"email", 50, func(u *models.Usuario, _ string) string { return u.Email }},
+	{"fecha_ingreso", 8, func(u *models.Usuario, _ string) string { return compactDate(u.FechaIngreso) }},
+	{"lugar_trabajo", 30, func(u *models.Usuario, _ string) string { return u.LugarTrabajo }},
+}
+
+// documentoCodes código del tipo de documento en el archivo
+var documentoCodes = map[string]string{
+	models.DocumentoDNI:       "1",
+	models.DocumentoCE:        "2",
+	models.DocumentoPTP:       "3",
+	models.DocumentoPasaporte: "4",
+}
+
+// cuentaCodes código del tipo de cuenta en el archivo
+var cuentaCodes = map[string]string{
+	models.CuentaSueldo: "S",
+	models.CuentaCTS:    "C",
+}
+
+// AperturaCSVWriter escribe el archivo de apertura masiva en CSV con encabezado
+type AperturaCSVWriter struct {
+	w      *csv.Writer
+	tipo   string
+	header bool
+	record []string
+}
+
+// NewAperturaCSVWriter crea un AperturaCSVWriter para el tipo de cuenta indicado
+func NewAperturaCSVWriter(w io.Writer, tipo string) *AperturaCSVWriter {
+	return &AperturaCSVWriter{
+		w:      csv.NewWriter(w),
+		tipo:   tipo,
+		record: make([]string, len(aperturaColumns)),
+	}
+}
+
+// Write escribe el encabezado en la primera llamada y luego el registro del trabajador
+func (c *AperturaCSVWriter) Write(u *models.Usuario) error {
+	if !c.header {
+		if err := c.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	for i, col := range aperturaColumns {
+		c.record[i] = bankText(col.value(u, c.tipo))
+	}
+	return c.w.Write(c.record)
+}
+
+// Flush envía los registros pendientes; si no hubo registros escribe solo el encabezado
+func (c *AperturaCSVWriter) Flush() error {
+	if !c.header {
+		if err := c.writeHeader(); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *AperturaCSVWriter) writeHeader() error {
+	c.header = true
+	for i, col := range aperturaColumns {
+		c.record[i] = col.name
+	}
+	return c.w.Write(c.record)
+}
+
+// AperturaTXTWriter escribe el archivo de apertura masiva de ancho fijo, un registro por
+// línea terminada en CRLF y sin encabezado
+type AperturaTXTWriter struct {
+	w    *bufio.Writer
+	tipo string
+	line []byte
+}
+
+// NewAperturaTXTWriter crea un AperturaTXTWriter para el tipo de cuenta indicado
+func NewAperturaTXTWriter(w io.Writer, tipo string) *AperturaTXTWriter {
+	return &AperturaTXTWriter{
+		w:    bufio.NewWriter(w),
+		tipo: tipo,
+	}
+}
+
+// Write escribe el registro del trabajador con cada campo recortado o completado a su ancho
+func (t *AperturaTXTWriter) Write(u *models.Usuario) error {
+	t.line = t.line[:0]
+	for _, col := range aperturaColumns {
+		value := bankText(col.value(u, t.tipo))
+		if len(value) > col.width {
+			value = value[:col.width]
+		}
+		t.line = append(t.line, value...)
+		t.line = append(t.line, strings.Repeat(" ", col.width-len(value))...)
+	}
+	t.line = append(t.line, '\r', '\n')
+	_, err := t.w.Write(t.line)
+	return err
+}
+
+// Flush envía los registros pendientes
+func (t *AperturaTXTWriter) Flush() error {
+	return t.w.Flush()
+}
+
+// bankText deja el texto en mayúsculas ASCII sin tildes, sin saltos de línea ni espacios repetidos
+func bankText(value string) string {
+	value = strings.ToUpper(accents.Replace(strings.Join(strings.Fields(value), " ")))
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// compactDate fecha de texto del usuario en formato AAAAMMDD; vacío si no se reconoce
+func compactDate(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("20060102")
+		}
+	}
+	return ""
+}
+
+// sexoCode M o F según el sexo registrado
+func sexoCode(sexo string) string {
+	switch strings.ToUpper(strings.TrimSpace(sexo)) {
+	case "M", "MASCULINO":
+		return "M"
+	case "F", "FEMENINO":
+		return "F"
+	}
+	return ""
+}
+
+// celular últimos 9 dígitos del teléfono, sin el código de país ni separadores
+func celular(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, value)
+	return value[max(len(value)-9, 0):]
+}
@@ -0,0 +1,199 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// Tipos MIME de las exportaciones por streaming
+const (
+	CSVContentType    = "text/csv; charset=utf-8"
+	NDJSONContentType = "application/x-ndjson"
+)
+
+// itemSeparator separa los valores de los elementos de una lista en una misma celda
+const itemSeparator = " | "
+
+// fileFields campos con archivos en base64 que no se incluyen en las exportaciones
+var fileFields = map[string]bool{
+	"foto":                    true,
+	"copia_dni":               true,
+	"constancia_estado_civil": true,
+}
+
+// UsuarioWriter escribe usuarios uno a uno en el formato de la exportación
+type UsuarioWriter interface {
+	Write(u *models.Usuario) error
+	// Flush envía al writer subyacente los datos pendientes
+	Flush() error
+}
+
+// csvColumn columna del CSV; value obtiene el valor desde el struct
+type csvColumn struct {
+	name  string
+	value func(v reflect.Value) string
+}
+
+// csvColumns columnas del CSV con los nombres JSON aplanados: los objetos anidados usan
+// "objeto.campo" y las listas "lista.campo" con los valores de cada elemento separados por " | "
+var csvColumns = flatten(reflect.TypeOf(models.Usuario{}), "")
+
+// flatten obtiene las columnas de un struct recorriendo sus campos anidados
+func flatten(t reflect.Type, prefix string) []csvColumn {
+	var columns []csvColumn
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || fileFields[name] {
+			continue
+		}
+		name = prefix + name
+
+		switch {
+		case field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct:
+			for _, sub := range flatten(field.Type.Elem(), name+".") {
+				columns = append(columns, csvColumn{name: sub.name, value: func(v reflect.Value) string {
+					p := v.Field(i)
+					if p.IsNil() {
+						return ""
+					}
+					return sub.value(p.Elem())
+				}})
+			}
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			for _, sub := range flatten(field.Type.Elem(), name+".") {
+				columns = append(columns, csvColumn{name: sub.name, value: func(v reflect.Value) string {
+					items := v.Field(i)
+					values := make([]string, items.Len())
+					for j := range values {
+						values[j] = sub.value(items.Index(j))
+					}
+					return strings.Join(values, itemSeparator)
+				}})
+			}
+		default:
+			columns = append(columns, csvColumn{name: name, value: func(v reflect.Value) string {
+				return formatScalar(v.Field(i))
+			}})
+		}
+	}
+
+	return columns
+}
+
+// formatScalar convierte un valor simple a texto
+func formatScalar(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case string:
+		return value
+	case bool:
+		return siNo(value)
+	case int:
+		return strconv.Itoa(value)
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		return value.Format(time.RFC3339)
+	}
+	return ""
+}
+
+// CSVWriter escribe usuarios en CSV con encabezado y columnas aplanadas
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+	record []string
+}
+
+// NewCSVWriter crea un CSVWriter. Se agrega el BOM de UTF-8 para que Excel muestre las tildes.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{
+		w:      csv.NewWriter(w),
+		record: make([]string, len(csvColumns)),
+	}
+}
+
+// Write escribe el encabezado en la primera llamada y luego la fila del usuario
+func (c *CSVWriter) Write(u *models.Usuario) error {
+	if !c.header {
+		if err := c.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	v := reflect.ValueOf(u).Elem()
+	for i, col := range csvColumns {
+		c.record[i] = col.value(v)
+	}
+	return c.w.Write(c.record)
+}
+
+// Flush envía las filas pendientes; si no hubo filas escribe solo el encabezado
+func (c *CSVWriter) Flush() error {
+	if !c.header {
+		if err := c.writeHeader(); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *CSVWriter) writeHeader() error {
+	c.header = true
+	for i, col := range csvColumns {
+		c.record[i] = col.name
+	}
+	c.record[0] = "\ufeff" + c.record[0]
+	return c.w.Write(c.record)
+}
+
+// NDJSONWriter escribe un usuario en JSON por línea
+type NDJSONWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter crea un NDJSONWriter
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+// Write escribe el usuario sin los archivos adjuntos
+func (n *NDJSONWriter) Write(u *models.Usuario) error {
+	return n.enc.Encode(withoutFiles(u))
+}
+
+// Flush no tiene datos pendientes: cada línea se escribe al llamar a Write
+func (n *NDJSONWriter) Flush() error {
+	return nil
+}
+
+// withoutFiles devuelve una copia del usuario sin la foto ni las copias de documentos
+func withoutFiles(u *models.Usuario) *models.Usuario {
+	c := *u
+	c.Foto = ""
+	c.ConstanciaEstadoCivil = ""
+
+	if u.DatosConyuge != nil {
+		conyuge := *u.DatosConyuge
+		conyuge.CopiaDni = ""
+		c.DatosConyuge = &conyuge
+	}
+	if len(u.Hijos) > 0 {
+		c.Hijos = make([]models.Hijo, len(u.Hijos))
+		for i, h := range u.Hijos {
+			h.CopiaDni = ""
+			c.Hijos[i] = h
+		}
+	}
+	return &c
+}
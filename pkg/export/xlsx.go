@@ -0,0 +1,347 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXContentType tipo MIME de los libros de Excel
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// dateLayouts formatos de fecha aceptados en los campos de texto del usuario
+var dateLayouts = []string{"2006-01-02", "02/01/2006", time.RFC3339}
+
+// Formatos numéricos de las columnas de fecha
+const (
+	dateFormat     = "dd/mm/yyyy"
+	dateTimeFormat = "dd/mm/yyyy hh:mm"
+)
+
+// column columna de una hoja del libro; format es el formato numérico de las celdas
+type column struct {
+	title  string
+	width  float64
+	format string
+}
+
+// sheet hoja del libro; rows devuelve las filas que aporta cada usuario
+type sheet struct {
+	name    string
+	columns []column
+	rows    func(u *models.Usuario) [][]any
+}
+
+// sheets hojas del libro: la principal con los datos planos y una por cada lista, relacionadas por el documento del trabajador
+var sheets = []sheet{
+	{
+		name: "Personal",
+		columns: []column{
+			{title: "Tipo Documento", width: 10},
+			{title: "N° Documento", width: 14},
+			{title: "Apellido Paterno", width: 18},
+			{title: "Apellido Materno", width: 18},
+			{title: "Nombres", width: 22},
+			{title: "Sexo", width: 8},
+			{title: "Fecha Nacimiento", width: 14, format: dateFormat},
+			{title: "Licencia Conducir", width: 14},
+			{title: "Categoría Licencia", width: 12},
+			{title: "Distrito Nacimiento", width: 16},
+			{title: "Provincia Nacimiento", width: 16},
+			{title: "Departamento Nacimiento", width: 16},
+			{title: "Ubigeo Nacimiento", width: 10},
+			{title: "Dirección Domicilio", width: 30},
+			{title: "Ubigeo Domicilio", width: 10},
+			{title: "Fecha Ingreso", width: 14, format: dateFormat},
+			{title: "Lugar Trabajo", width: 20},
+			{title: "Puesto Actual", width: 20},
+			{title: "Teléfono", width: 12},
+			{title: "Email", width: 26},
+			{title: "Situación Contractual", width: 18},
+			{title: "Régimen Pensionario", width: 16},
+			{title: "AFP", width: 12},
+			{title: "CUSPP", width: 14},
+			{title: "Régimen Salud", width: 14},
+			{title: "Grupo Sanguíneo", width: 10},
+			{title: "Estado Civil", width: 12},
+			{title: "Contacto Nombre", width: 24},
+			{title: "Contacto Parentesco", width: 14},
+			{title: "Contacto Celular", width: 12},
+			{title: "Contacto Teléfono Fijo", width: 12},
+			{title: "Contacto Dirección", width: 30},
+			{title: "Cónyuge", width: 26},
+			{title: "DNI Cónyuge", width: 12},
+			{title: "Autoriza BCP", width: 10},
+			{title: "Autoriza Otro Banco", width: 10},
+			{title: "Otro Banco", width: 14},
+			{title: "Cuenta Otro Banco", width: 18},
+			{title: "CCI Otro Banco", width: 22},
+			{title: "Autoriza CTS BCP", width: 10},
+			{title: "Fecha Registro", width: 16, format: dateTimeFormat},
+		},
+		rows: func(u *models.Usuario) [][]any {
+			var conyuge, conyugeDni string
+			if u.DatosConyuge != nil {
+				conyuge, conyugeDni = u.DatosConyuge.ApellidosNombres, u.DatosConyuge.Dni
+			}
+			return [][]any{{
+				u.Documento().Tipo, u.Documento().Numero, u.ApellidoPaterno, u.ApellidoMaterno, u.Nombres, u.Sexo,
+				dateValue(u.FechaNacimiento), u.LicenciaConducir, u.CategoriaLicencia,
+				u.LugarNacimientoDistrito, u.LugarNacimientoProvincia, u.LugarNacimientoDepartamento, u.UbigeoNacimiento,
+				u.DireccionDomicilio, ubigeoDomicilio(u), dateValue(u.FechaIngreso), u.LugarTrabajo, u.PuestoActual,
+				u.Telefono, u.Email, u.SituacionContractual, u.RegimenPensionario, u.AfpNombre,
+				u.Cuspp, u.RegimenSalud, u.GrupoSanguineo, u.EstadoCivil, u.ContactoNombre,
+				u.ContactoParentesco, u.ContactoCelular, u.ContactoTelefonoFijo, u.ContactoDireccion,
+				conyuge, conyugeDni, siNo(u.AutorizaBcp), siNo(u.AutorizaOtroBanco), u.OtroBancoNombre,
+				u.OtroBancoCuenta, u.OtroBancoCci, siNo(u.AutorizaCtsBcp), u.CreatedAt,
+			}}
+		},
+	},
+	{
+		name: "CuentasBancarias",
+		columns: []column{
+			{title: "Documento Trabajador", width: 18},
+			{title: "Propósito", width: 10},
+			{title: "Banco", width: 14},
+			{title: "N° Cuenta", width: 22},
+			{title: "CCI", width: 24},
+			{title: "Moneda", width: 8},
+			{title: "Activa", width: 8},
+		},
+		rows: func(u *models.Usuario) [][]any {
+			rows := make([][]any, 0, len(u.CuentasBancarias))
+			for _, c := range u.CuentasBancarias {
+				rows = append(rows, []any{u.Documento().String(), c.Proposito, c.Banco, c.NumeroCuenta, c.Cci, c.Moneda, siNo(c.Activa)})
+			}
+			return rows
+		},
+	},
+	{
+		name: "Hijos",
+		columns: []column{
+			{title: "Documento Trabajador", width: 18},
+			{title: "Apellidos y Nombres", width: 30},
+			{title: "DNI", width: 12},
+			{title: "Fecha Nacimiento", width: 14, format: dateFormat},
+			{title: "Edad", width: 8},
+			{title: "Dirección", width: 30},
+		},
+		rows: func(u *models.Usuario) [][]any {
+			rows := make([][]any, 0, len(u.Hijos))
+			for _, h := range u.Hijos {
+				rows = append(rows, []any{u.Documento().String(), h.ApellidosNombres, h.Dni, dateValue(h.FechaNacimiento), h.Edad, h.Direccion})
+			}
+			return rows
+		},
+	},
+	{
+		name: "EducacionSuperior",
+		columns: []column{
+			{title: "Documento Trabajador", width: 18},
+			{title: "Nivel", width: 14},
+			{title: "Especialidad", width: 24},
+			{title: "Centro de Estudios", width: 30},
+			{title: "Desde", width: 10},
+			{title: "Hasta", width: 10},
+			{title: "Completa", width: 10},
+			{title: "Grado Académico", width: 18},
+		},
+		rows: func(u *models.Usuario) [][]any {
+			rows := make([][]any, 0, len(u.EducacionSuperior))
+			for _, e := range u.EducacionSuperior {
+				rows = append(rows, []any{u.Documento().String(), e.Nivel, e.Especialidad, e.CentroEstudios, e.Desde, e.Hasta, siNo(e.Completa), e.GradoAcademico})
+			}
+			return rows
+		},
+	},
+	{
+		name: "Capacitaciones",
+		columns: []column{
+			{title: "Documento Trabajador", width: 18},
+			{title: "Nombre", width: 34},
+			{title: "Institución", width: 28},
+			{title: "Horas", width: 8},
+		},
+		rows: func(u *models.Usuario) [][]any {
+			rows := make([][]any, 0, len(u.Capacitaciones))
+			for _, c := range u.Capacitaciones {
+				rows = append(rows, []any{u.Documento().String(), c.Nombre, c.Institucion, c.Horas})
+			}
+			return rows
+		},
+	},
+	{
+		name: "ExperienciaLaboral",
+		columns: []column{
+			{title: "Documento Trabajador", width: 18},
+			{title: "Cargo", width: 22},
+			{title: "Empresa", width: 26},
+			{title: "Fecha Ingreso", width: 14, format: dateFormat},
+			{title: "Fecha Cese", width: 14, format: dateFormat},
+			{title: "Tiempo Permanencia", width: 16},
+			{title: "Motivo Cese", width: 26},
+		},
+		rows: func(u *models.Usuario) [][]any {
+			rows := make([][]any, 0, len(u.ExperienciaLaboral))
+			for _, e := range u.ExperienciaLaboral {
+				rows = append(rows, []any{u.Documento().String(), e.Cargo, e.Empresa, dateValue(e.FechaIngreso), dateValue(e.FechaCese), e.TiempoPermanencia, e.MotivoCese})
+			}
+			return rows
+		},
+	},
+	{
+		name: "Idiomas",
+		columns: []column{
+			{title: "Documento Trabajador", width: 18},
+			{title: "Idioma", width: 16},
+			{title: "Lee", width: 10},
+			{title: "Habla", width: 10},
+			{title: "Escribe", width: 10},
+		},
+		rows: func(u *models.Usuario) [][]any {
+			rows := make([][]any, 0, len(u.Idiomas))
+			for _, i := range u.Idiomas {
+				rows = append(rows, []any{u.Documento().String(), i.Idioma, i.Lee, i.Habla, i.Escribe})
+			}
+			return rows
+		},
+	},
+}
+
+// UsuariosXLSX genera un libro de Excel con los usuarios y sus datos relacionados
+func UsuariosXLSX(ctx context.Context, usuarios []*models.Usuario) ([]byte, error) {
+	start := time.Now()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"1F4E79"}},
+		Alignment: &excelize.Alignment{Vertical: "center"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creando estilo de encabezado: %w", err)
+	}
+	formatStyles := make(map[string]int)
+	for _, format := range []string{dateFormat, dateTimeFormat} {
+		style, err := f.NewStyle(&excelize.Style{CustomNumFmt: &format})
+		if err != nil {
+			return nil, fmt.Errorf("error creando estilo de fecha: %w", err)
+		}
+		formatStyles[format] = style
+	}
+
+	for i, s := range sheets {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", s.name); err != nil {
+				return nil, fmt.Errorf("error creando hoja %s: %w", s.name, err)
+			}
+		} else if _, err := f.NewSheet(s.name); err != nil {
+			return nil, fmt.Errorf("error creando hoja %s: %w", s.name, err)
+		}
+
+		if err := writeSheet(f, s, usuarios, headerStyle, formatStyles); err != nil {
+			return nil, fmt.Errorf("error escribiendo hoja %s: %w", s.name, err)
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("error generando XLSX: %w", err)
+	}
+
+	metrics.ExportGenerated("xlsx", time.Since(start), buf.Len())
+
+	return buf.Bytes(), nil
+}
+
+// writeSheet escribe el encabezado y las filas de una hoja con encabezado fijo y filtros
+func writeSheet(f *excelize.File, s sheet, usuarios []*models.Usuario, headerStyle int, formatStyles map[string]int) error {
+	header := make([]any, len(s.columns))
+	for i, c := range s.columns {
+		header[i] = c.title
+
+		name, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetColWidth(s.name, name, name, c.width); err != nil {
+			return err
+		}
+		// El estilo de la columna se aplica antes de escribir para que las celdas lo hereden
+		if c.format != "" {
+			if err := f.SetColStyle(s.name, name, formatStyles[c.format]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := f.SetSheetRow(s.name, "A1", &header); err != nil {
+		return err
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(s.columns))
+	if err := f.SetCellStyle(s.name, "A1", lastCol+"1", headerStyle); err != nil {
+		return err
+	}
+
+	n := 1
+	for _, u := range usuarios {
+		for _, row := range s.rows(u) {
+			n++
+			if err := f.SetSheetRow(s.name, fmt.Sprintf("A%d", n), &row); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := f.SetPanes(s.name, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	return f.AutoFilter(s.name, fmt.Sprintf("A1:%s%d", lastCol, n), nil)
+}
+
+// dateValue convierte una fecha en texto a time.Time para escribirla como fecha; si no se reconoce se mantiene el texto
+func dateValue(value string) any {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return value
+}
+
+// siNo convierte un booleano a "SI"/"NO"
+func siNo(b bool) string {
+	if b {
+		return "SI"
+	}
+	return "NO"
+}
+
+// ubigeoDomicilio ubigeo del domicilio por partes; vacío en los registros con dirección libre
+func ubigeoDomicilio(u *models.Usuario) string {
+	if u.Domicilio == nil {
+		return ""
+	}
+	return u.Domicilio.Ubigeo
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// ZIPContentType tipo MIME de los archivos ZIP
+const ZIPContentType = "application/zip"
+
+// manifestName nombre del manifiesto dentro del ZIP
+const manifestName = "manifiesto.csv"
+
+// GenerateFunc genera el documento PDF de un usuario
+type GenerateFunc func(ctx context.Context, u *models.Usuario) ([]byte, error)
+
+// zipResult documento generado para un usuario
+type zipResult struct {
+	data []byte
+	err  error
+}
+
+// FichasZIP escribe en w un ZIP con la ficha de cada usuario y un manifiesto CSV.
+// Los PDF se generan con como máximo concurrency a la vez y se escriben en el orden
+// de usuarios; un error al generar una ficha se informa en el manifiesto sin detener el ZIP.
+func FichasZIP(ctx context.Context, w io.Writer, usuarios []*models.Usuario, concurrency int, generate GenerateFunc) error {
+	start := time.Now()
+	out := &CountingWriter{W: w}
+	zw := zip.NewWriter(out)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Cada usuario tiene su canal de resultado; el semáforo se libera al escribir el PDF en
+	// el ZIP, así nunca hay más de concurrency documentos en memoria
+	results := make([]chan zipResult, len(usuarios))
+	for i := range results {
+		results[i] = make(chan zipResult, 1)
+	}
+	sem := make(chan struct{}, concurrency)
+
+	go func() {
+		for i, u := range usuarios {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(i int, u *models.Usuario) {
+				data, err := generate(ctx, u)
+				results[i] <- zipResult{data: data, err: err}
+			}(i, u)
+		}
+	}()
+
+	manifest := make([][]string, 0, len(usuarios)+1)
+	manifest = append(manifest, []string{"n", "tipo_documento", "numero_documento", "apellidos_nombres", "lugar_trabajo", "puesto_actual", "archivo", "bytes", "sha256", "estado", "error"})
+
+	names := make(map[string]int, len(usuarios))
+	for i, u := range usuarios {
+		var result zipResult
+		select {
+		case result = <-results[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		name := fichaFileName(u, names)
+		doc := u.Documento()
+		row := []string{strconv.Itoa(i + 1), doc.Tipo, doc.Numero, fullName(u), u.LugarTrabajo, u.PuestoActual, name, "", "", "ok", ""}
+
+		if result.err != nil {
+			row[6], row[9], row[10] = "", "error", result.err.Error()
+		} else {
+			if err := writeZipEntry(zw, name, result.data); err != nil {
+				return err
+			}
+			sum := sha256.Sum256(result.data)
+			row[7], row[8] = strconv.Itoa(len(result.data)), hex.EncodeToString(sum[:])
+		}
+		manifest = append(manifest, row)
+		<-sem
+	}
+
+	entry, err := zw.Create(manifestName)
+	if err != nil {
+		return fmt.Errorf("error creando manifiesto: %w", err)
+	}
+	if _, err := io.WriteString(entry, "\ufeff"); err != nil {
+		return fmt.Errorf("error escribiendo manifiesto: %w", err)
+	}
+	if err := csv.NewWriter(entry).WriteAll(manifest); err != nil {
+		return fmt.Errorf("error escribiendo manifiesto: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error cerrando ZIP: %w", err)
+	}
+
+	metrics.ExportGenerated("zip", time.Since(start), out.N)
+	return nil
+}
+
+func writeZipEntry(zw *zip.Writer, name string, data []byte) error {
+	entry, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error agregando %s al ZIP: %w", name, err)
+	}
+	if _, err := entry.Write(data); err != nil {
+		return fmt.Errorf("error escribiendo %s en el ZIP: %w", name, err)
+	}
+	return nil
+}
+
+// fichaFileName devuelve "NUMERODOCUMENTO_APELLIDOS_NOMBRES.pdf" en mayúsculas y sin tildes;
+// los nombres repetidos (documento vacío o duplicado) reciben un sufijo numérico
+func fichaFileName(u *models.Usuario, used map[string]int) string {
+	parts := []string{u.Documento().Numero, u.ApellidoPaterno, u.ApellidoMaterno, u.Nombres}
+	var fields []string
+	for _, part := range parts {
+		if part = sanitizeFileName(part); part != "" {
+			fields = append(fields, part)
+		}
+	}
+
+	base := strings.Join(fields, "_")
+	if base == "" {
+		base = "SIN_NOMBRE"
+	}
+
+	used[base]++
+	if n := used[base]; n > 1 {
+		return fmt.Sprintf("%s_%d.pdf", base, n)
+	}
+	return base + ".pdf"
+}
+
+// accents reemplazos de las letras con tilde del español
+var accents = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U", "Ñ", "N",
+)
+
+// sanitizeFileName deja solo letras y números ASCII separados por "_"
+func sanitizeFileName(value string) string {
+	value = strings.ToUpper(accents.Replace(strings.TrimSpace(value)))
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	}), "_")
+}
+
+// fullName devuelve "APELLIDO_PATERNO APELLIDO_MATERNO, NOMBRES"
+func fullName(u *models.Usuario) string {
+	apellidos := strings.TrimSpace(u.ApellidoPaterno + " " + u.ApellidoMaterno)
+	return fmt.Sprintf("%s, %s", apellidos, u.Nombres)
+}
+
+// CountingWriter cuenta los bytes escritos para las métricas
+type CountingWriter struct {
+	W io.Writer
+	N int
+}
+
+func (c *CountingWriter) Write(p []byte) (int, error) {
+	n, err := c.W.Write(p)
+	c.N += n
+	return n, err
+}
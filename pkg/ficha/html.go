@@ -0,0 +1,71 @@
+package ficha
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTMLContentType tipo de contenido de la vista previa HTML
+const HTMLContentType = "text/html; charset=utf-8"
+
+//go:embed ficha.html
+var htmlSource string
+
+var htmlTemplate = template.Must(template.New("ficha").Funcs(template.FuncMap{
+	"spanPercent": func(span int) string {
+		return fmt.Sprintf("%.4f", float64(span)*100/Columns)
+	},
+}).Parse(htmlSource))
+
+// Theme datos de la empresa y colores con los que se dibuja la ficha en HTML
+type Theme struct {
+	CompanyName string
+	RUC         string
+	Logo        []byte
+	// PrimaryColor y TextColor en formato #RRGGBB
+	PrimaryColor string
+	TextColor    string
+	FooterText   string
+}
+
+// htmlData datos que recibe la plantilla
+type htmlData struct {
+	Title       string
+	Columns     int
+	Doc         *Document
+	Theme       Theme
+	Logo        template.URL
+	Foto        template.URL
+	GeneratedAt string
+}
+
+// RenderHTML escribe la ficha como una página HTML autocontenida (imágenes embebidas)
+func RenderHTML(w io.Writer, doc *Document, theme Theme, generatedAt time.Time) error {
+	data := htmlData{
+		Title:       Title,
+		Columns:     Columns,
+		Doc:         doc,
+		Theme:       theme,
+		Logo:        imageURL(theme.Logo),
+		Foto:        imageURL(doc.Foto),
+		GeneratedAt: generatedAt.Format("02/01/2006 15:04:05"),
+	}
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("error generando HTML: %w", err)
+	}
+	return nil
+}
+
+// imageURL devuelve la imagen como data URI; solo se aceptan PNG y JPEG
+func imageURL(img []byte) template.URL {
+	contentType := http.DetectContentType(img)
+	if contentType != "image/png" && contentType != "image/jpeg" {
+		return ""
+	}
+	return template.URL("data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(img))
+}
@@ -0,0 +1,373 @@
+// Package ficha arma la ficha de datos del personal como un modelo independiente del
+// formato de salida; los renderers PDF (maroto) y HTML dibujan el mismo modelo
+package ficha
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/catalog"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// Secciones de la ficha de datos del personal
+const (
+	SectionDatosPersonales = "datos_personales"
+	SectionContacto        = "contacto"
+	SectionDatosLaborales  = "datos_laborales"
+	SectionCuentas         = "cuentas"
+	SectionFamiliares      = "familiares"
+	SectionEducacion       = "educacion"
+	SectionCapacitaciones  = "capacitaciones"
+	SectionExperiencia     = "experiencia"
+	SectionIdiomas         = "idiomas"
+	SectionFirma           = "firma"
+)
+
+// AllSections secciones de la ficha en el orden en que se imprimen
+var AllSections = []string{
+	SectionDatosPersonales,
+	SectionContacto,
+	SectionDatosLaborales,
+	SectionCuentas,
+	SectionFamiliares,
+	SectionEducacion,
+	SectionCapacitaciones,
+	SectionExperiencia,
+	SectionIdiomas,
+	SectionFirma,
+}
+
+// Columns ancho total de una fila; los anchos de campos y columnas se expresan sobre 12
+const Columns = 12
+
+// Title título del documento
+const Title = "FICHA DE DATOS DEL PERSONAL"
+
+// declarationText texto de la declaración jurada que firma el trabajador
+const declarationText = "Declaro bajo juramento que los datos consignados en la presente ficha son verdaderos " +
+	"y me comprometo a comunicar a la empresa cualquier variación. Autorizo su verificación y el " +
+	"tratamiento de mis datos personales para fines de la relación laboral, conforme a la Ley N° 29733."
+
+// Document ficha de un trabajador
+type Document struct {
+	// Trabajador nombre en formato "APELLIDOS, NOMBRES"
+	Trabajador string
+	// Documento tipo y número del documento de identidad, por ejemplo "DNI 12345678"
+	Documento string
+	// Foto imagen decodificada; nil si no tiene o no es válida
+	Foto     []byte
+	Sections []Section
+}
+
+// Section sección numerada de la ficha
+type Section struct {
+	Key    string
+	Number string
+	Title  string
+	Blocks []Block
+	// Signature agrega al final el espacio para la firma y la huella digital
+	Signature bool
+}
+
+// Block contenido de una sección: filas de campos, una tabla o un párrafo
+type Block struct {
+	Subtitle string
+	Rows     [][]Field
+	Table    *Table
+	Text     string
+}
+
+// Field campo "Etiqueta: valor" que ocupa Span columnas
+type Field struct {
+	Label string
+	Value string
+	Span  int
+}
+
+// Table tabla con encabezados; Empty es el texto que se muestra si no tiene filas
+type Table struct {
+	Columns []Column
+	Rows    [][]string
+	Empty   string
+}
+
+// Column encabezado de una tabla que ocupa Span columnas
+type Column struct {
+	Title string
+	Span  int
+}
+
+// DisplayValue valor a mostrar; los campos vacíos se muestran como "-"
+func (f Field) DisplayValue() string {
+	if f.Value == "" {
+		return "-"
+	}
+	return f.Value
+}
+
+// Build arma la ficha del usuario con las secciones indicadas; vacío incluye todas
+func Build(u *models.Usuario, sections []string) *Document {
+	doc := &Document{
+		Trabajador: FullName(u),
+		Documento:  u.Documento().String(),
+		Foto:       DecodeFoto(u.Foto),
+	}
+
+	builders := map[string]func(*models.Usuario) Section{
+		SectionDatosPersonales: datosPersonales,
+		SectionContacto:        contacto,
+		SectionDatosLaborales:  datosLaborales,
+		SectionCuentas:         cuentas,
+		SectionFamiliares:      familiares,
+		SectionEducacion:       educacion,
+		SectionCapacitaciones:  capacitaciones,
+		SectionExperiencia:     experiencia,
+		SectionIdiomas:         idiomas,
+		SectionFirma:           firma,
+	}
+
+	for _, key := range AllSections {
+		if !includes(sections, key) {
+			continue
+		}
+		section := builders[key](u)
+		section.Key = key
+		section.Number = sectionNumber(len(doc.Sections) + 1)
+		doc.Sections = append(doc.Sections, section)
+	}
+	return doc
+}
+
+// IsSection indica si name es una sección de la ficha
+func IsSection(name string) bool {
+	return includes(AllSections, name)
+}
+
+// FullName devuelve "APELLIDO_PATERNO APELLIDO_MATERNO, NOMBRES"
+func FullName(u *models.Usuario) string {
+	apellidos := strings.TrimSpace(u.ApellidoPaterno + " " + u.ApellidoMaterno)
+	return fmt.Sprintf("%s, %s", apellidos, u.Nombres)
+}
+
+func datosPersonales(u *models.Usuario) Section {
+	return Section{
+		Title: "DATOS PERSONALES",
+		Blocks: []Block{{Rows: [][]Field{
+			{{u.Documento().Label(), u.Documento().Numero, 3}, {"Apellidos", strings.TrimSpace(u.ApellidoPaterno + " " + u.ApellidoMaterno), 5}, {"Nombres", u.Nombres, 4}},
+			{{"F. Nacimiento", u.FechaNacimiento, 3}, {"Sexo", u.Sexo, 3}, {"Estado Civil", u.EstadoCivil, 3}, {"G. Sanguíneo", u.GrupoSanguineo, 3}},
+			{{"Licencia", u.LicenciaConducir, 3}, {"Cat. Licencia", u.CategoriaLicencia, 3}},
+			{{"Dirección Domicilio", u.DireccionDomicilio, 12}},
+			{{"Lugar Nacimiento", fmt.Sprintf("%s - %s - %s", u.LugarNacimientoDepartamento, u.LugarNacimientoProvincia, u.LugarNacimientoDistrito), 9}, {"Ubigeo", u.UbigeoNacimiento, 3}},
+		}}},
+	}
+}
+
+func contacto(u *models.Usuario) Section {
+	return Section{
+		Title: "CONTACTO Y EMERGENCIA",
+		Blocks: []Block{
+			{Rows: [][]Field{
+				{{"Teléfono", u.Telefono, 4}, {"Email", u.Email, 8}},
+			}},
+			{Subtitle: "Contacto de emergencia", Rows: [][]Field{
+				{{"Contacto Emergencia", u.ContactoNombre, 6}, {"Parentesco", catalog.Parentesco.NombreOf(u.ContactoParentesco), 6}},
+				{{"Celular", u.ContactoCelular, 6}, {"Teléfono Fijo", u.ContactoTelefonoFijo, 6}},
+				{{"Dirección Emergencia", u.ContactoDireccion, 12}},
+			}},
+		},
+	}
+}
+
+func datosLaborales(u *models.Usuario) Section {
+	return Section{
+		Title: "DATOS LABORALES",
+		Blocks: []Block{{Rows: [][]Field{
+			{{"Puesto", u.PuestoActual, 4}, {"Lugar", u.LugarTrabajo, 4}, {"F. Ingreso", u.FechaIngreso, 4}},
+			{{"Régimen Pensión", u.RegimenPensionario, 4}, {"AFP/ONP", strings.TrimSpace(catalog.AFP.NombreOf(u.AfpNombre) + " " + u.Cuspp), 4}, {"Salud", catalog.RegimenSalud.NombreOf(u.RegimenSalud), 4}},
+			{{"Situación Contractual", u.SituacionContractual, 8}, {"F. Cese", u.FechaCese, 4}},
+		}}},
+	}
+}
+
+func cuentas(u *models.Usuario) Section {
+	rows := [][]Field{
+		{{"Autoriza Cuenta Sueldo BCP", formatBool(u.AutorizaBcp), 4}, {"Autoriza CTS BCP", formatBool(u.AutorizaCtsBcp), 4}, {"Autoriza Otro Banco", formatBool(u.AutorizaOtroBanco), 4}},
+	}
+	if u.AutorizaOtroBanco && len(u.CuentasBancarias) == 0 {
+		rows = append(rows, []Field{{"Banco", u.OtroBancoNombre, 4}, {"N° Cuenta", u.OtroBancoCuenta, 4}, {"CCI", u.OtroBancoCci, 4}})
+	}
+	blocks := []Block{{Rows: rows}}
+
+	if len(u.CuentasBancarias) > 0 {
+		table := &Table{
+			Columns: []Column{{"Propósito", 1}, {"Banco", 3}, {"N° Cuenta", 3}, {"CCI", 3}, {"Moneda", 1}, {"Activa", 1}},
+		}
+		for _, c := range u.CuentasBancarias {
+			banco := c.Banco
+			if b, ok := catalog.BancoByCodigo(c.Banco); ok {
+				banco = b.Nombre
+			}
+			table.Rows = append(table.Rows, []string{strings.ToUpper(c.Proposito), banco, c.NumeroCuenta, c.Cci, c.Moneda, formatBool(c.Activa)})
+		}
+		blocks = append(blocks, Block{Subtitle: "Cuentas Bancarias", Table: table})
+	}
+	return Section{Title: "APERTURA DE CUENTAS", Blocks: blocks}
+}
+
+func familiares(u *models.Usuario) Section {
+	conyuge := &Table{
+		Columns: []Column{{"Apellidos y Nombres", 3}, {"DNI", 2}, {"Género", 2}, {"F. Nac", 2}, {"Dirección", 3}},
+		Empty:   "- Sin información -",
+	}
+	if c := u.DatosConyuge; c != nil && c.ApellidosNombres != "" {
+		conyuge.Rows = append(conyuge.Rows, []string{c.ApellidosNombres, c.Dni, c.Genero, c.FechaNacimiento, formatDireccion(c.Domicilio, c.Direccion)})
+	}
+
+	hijos := &Table{
+		Columns: []Column{{"Apellidos y Nombres", 4}, {"DNI", 2}, {"F. Nac", 2}, {"Edad", 1}, {"Dirección", 3}},
+		Empty:   "- Sin hijos registrados -",
+	}
+	for _, h := range u.Hijos {
+		hijos.Rows = append(hijos.Rows, []string{h.ApellidosNombres, h.Dni, h.FechaNacimiento, fmt.Sprintf("%d", h.Edad), formatDireccion(h.Domicilio, h.Direccion)})
+	}
+
+	padres := &Table{
+		Columns: []Column{{"Apellidos y Nombres", 4}, {"F. Nac", 2}, {"Ocupación", 3}, {"Estado Civil", 2}, {"Vive", 1}},
+		Empty:   "- Sin padres registrados -",
+	}
+	for _, p := range u.Padres {
+		padres.Rows = append(padres.Rows, []string{p.ApellidosNombres, p.FechaNacimiento, p.Ocupacion, p.EstadoCivil, formatBool(p.Vive)})
+	}
+
+	return Section{
+		Title: "DATOS FAMILIARES",
+		Blocks: []Block{
+			{Subtitle: "Datos del Cónyuge / Conviviente", Table: conyuge},
+			{Subtitle: "Hijos", Table: hijos},
+			{Subtitle: "Padres", Table: padres},
+		},
+	}
+}
+
+func educacion(u *models.Usuario) Section {
+	basica := &Table{
+		Columns: []Column{{"Nivel", 2}, {"Institución", 5}, {"Desde", 2}, {"Hasta", 2}, {"Comp.", 1}},
+		Empty:   "- Sin información -",
+	}
+	for _, e := range u.EducacionBasica {
+		basica.Rows = append(basica.Rows, []string{catalog.NivelEducativo.NombreOf(e.Nivel), e.CentroEstudios, e.Desde, e.Hasta, formatBool(e.Completa)})
+	}
+
+	superior := &Table{
+		Columns: []Column{{"Nivel", 2}, {"Institución", 3}, {"Especialidad", 3}, {"Periodo", 2}, {"Grado", 2}},
+		Empty:   "- Sin información -",
+	}
+	for _, e := range u.EducacionSuperior {
+		superior.Rows = append(superior.Rows, []string{catalog.NivelEducativo.NombreOf(e.Nivel), e.CentroEstudios, e.Especialidad, e.Desde + "-" + e.Hasta, e.GradoAcademico})
+	}
+
+	return Section{
+		Title: "EDUCACIÓN",
+		Blocks: []Block{
+			{Subtitle: "Educación Básica", Table: basica},
+			{Subtitle: "Educación Superior", Table: superior},
+		},
+	}
+}
+
+func capacitaciones(u *models.Usuario) Section {
+	table := &Table{
+		Columns: []Column{{"Curso / Capacitación", 6}, {"Institución", 4}, {"Horas", 2}},
+		Empty:   "- Sin capacitaciones registradas -",
+	}
+	for _, c := range u.Capacitaciones {
+		table.Rows = append(table.Rows, []string{c.Nombre, c.Institucion, fmt.Sprintf("%d", c.Horas)})
+	}
+	return Section{Title: "CAPACITACIONES", Blocks: []Block{{Table: table}}}
+}
+
+func experiencia(u *models.Usuario) Section {
+	table := &Table{
+		Columns: []Column{{"Empresa", 3}, {"Cargo", 3}, {"F. Inicio", 2}, {"F. Fin", 2}, {"Motivo", 2}},
+		Empty:   "- Sin experiencia registrada -",
+	}
+	for _, ex := range u.ExperienciaLaboral {
+		table.Rows = append(table.Rows, []string{ex.Empresa, ex.Cargo, ex.FechaIngreso, ex.FechaCese, ex.MotivoCese})
+	}
+	return Section{Title: "EXPERIENCIA LABORAL", Blocks: []Block{{Table: table}}}
+}
+
+func idiomas(u *models.Usuario) Section {
+	table := &Table{
+		Columns: []Column{{"Idioma", 3}, {"Lee", 3}, {"Habla", 3}, {"Escribe", 3}},
+		Empty:   "- Sin idiomas registrados -",
+	}
+	for _, i := range u.Idiomas {
+		table.Rows = append(table.Rows, []string{i.Idioma, catalog.NivelIdioma.NombreOf(i.Lee), catalog.NivelIdioma.NombreOf(i.Habla), catalog.NivelIdioma.NombreOf(i.Escribe)})
+	}
+	return Section{Title: "IDIOMAS", Blocks: []Block{{Table: table}}}
+}
+
+func firma(*models.Usuario) Section {
+	return Section{
+		Title:     "DECLARACIÓN JURADA",
+		Blocks:    []Block{{Text: declarationText}},
+		Signature: true,
+	}
+}
+
+// formatDireccion dirección de un familiar: el domicilio por partes o, en los registros
+// anteriores, el texto libre
+func formatDireccion(domicilio *models.Direccion, direccion string) string {
+	if domicilio != nil {
+		return domicilio.Format()
+	}
+	return direccion
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "Si"
+	}
+	return "No"
+}
+
+// DecodeFoto decodifica la foto en base64 (con o sin prefijo data:image/...;base64,)
+func DecodeFoto(foto string) []byte {
+	if foto == "" || foto == "null" || foto == "undefined" {
+		return nil
+	}
+	if _, data, found := strings.Cut(foto, "base64,"); found {
+		foto = data
+	}
+	img, err := base64.StdEncoding.DecodeString(foto)
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+func includes(sections []string, section string) bool {
+	if len(sections) == 0 {
+		return true
+	}
+	for _, s := range sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// romanNumerals numeración de las secciones de la ficha
+var romanNumerals = []string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII", "XIII", "XIV", "XV"}
+
+// sectionNumber devuelve el número romano de la sección n (desde 1)
+func sectionNumber(n int) string {
+	if n >= 1 && n <= len(romanNumerals) {
+		return romanNumerals[n-1]
+	}
+	return fmt.Sprintf("%d", n)
+}
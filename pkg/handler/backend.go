@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+
+	"github.com/luispfcanales/rainforestapp/pkg/blob"
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/database"
+	"github.com/luispfcanales/rainforestapp/pkg/pdf"
+	"github.com/luispfcanales/rainforestapp/pkg/ratelimit"
+	"github.com/luispfcanales/rainforestapp/pkg/repository"
+	"github.com/luispfcanales/rainforestapp/pkg/signing"
+	"github.com/luispfcanales/rainforestapp/pkg/verify"
+)
+
+// Repositorios en memoria compartidos por todos los handlers del proceso
+var (
+	memoryRepo = sync.OnceValue(func() *repository.MemoryUsuarioRepository {
+		return repository.NewMemoryUsuarioRepository()
+	})
+	memoryIdempotencyRepo = sync.OnceValue(func() *repository.MemoryIdempotencyRepository {
+		return repository.NewMemoryIdempotencyRepository()
+	})
+	memoryRateLimitStore = sync.OnceValue(func() *ratelimit.MemoryStore {
+		return ratelimit.NewMemoryStore()
+	})
+	memoryJobRepo = sync.OnceValue(func() *repository.MemoryJobRepository {
+		return repository.NewMemoryJobRepository()
+	})
+	memoryBlobStore = sync.OnceValue(func() *blob.MemoryStore {
+		return blob.NewMemoryStore()
+	})
+	memoryAuditRepo = sync.OnceValue(func() *repository.MemoryAuditRepository {
+		return repository.NewMemoryAuditRepository()
+	})
+	memoryCertificadoRepo = sync.OnceValue(func() *repository.MemoryCertificadoRepository {
+		return repository.NewMemoryCertificadoRepository()
+	})
+	memoryAperturaRepo = sync.OnceValue(func() *repository.MemoryAperturaRepository {
+		return repository.NewMemoryAperturaRepository()
+	})
+)
+
+// newUsuarioRepository crea el repositorio según el backend configurado
+func newUsuarioRepository(ctx context.Context, cfg *config.Config) (repository.UsuarioRepository, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageMemory:
+		return memoryRepo(), nil
+	case config.StorageFirestore:
+		firestoreClient, err := database.GetFirestoreClient(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewUsuarioRepository(firestoreClient), nil
+	}
+	return nil, fmt.Errorf("backend de almacenamiento %q no soportado", cfg.Storage.Backend)
+}
+
+// newIdempotencyRepository crea el repositorio de idempotencia según el backend configurado
+func newIdempotencyRepository(ctx context.Context, cfg *config.Config) (repository.IdempotencyRepository, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageMemory:
+		return memoryIdempotencyRepo(), nil
+	case config.StorageFirestore:
+		firestoreClient, err := database.GetFirestoreClient(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewIdempotencyRepository(firestoreClient), nil
+	}
+	return nil, fmt.Errorf("backend de almacenamiento %q no soportado", cfg.Storage.Backend)
+}
+
+// newJobRepository crea el repositorio de trabajos de reportes según el backend configurado
+func newJobRepository(ctx context.Context, cfg *config.Config) (repository.JobRepository, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageMemory:
+		return memoryJobRepo(), nil
+	case config.StorageFirestore:
+		firestoreClient, err := database.GetFirestoreClient(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewJobRepository(firestoreClient), nil
+	}
+	return nil, fmt.Errorf("backend de almacenamiento %q no soportado", cfg.Storage.Backend)
+}
+
+// newAuditRepository crea el repositorio de auditoría según el backend configurado
+func newAuditRepository(ctx context.Context, cfg *config.Config) (repository.AuditRepository, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageMemory:
+		return memoryAuditRepo(), nil
+	case config.StorageFirestore:
+		firestoreClient, err := database.GetFirestoreClient(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewAuditRepository(firestoreClient), nil
+	}
+	return nil, fmt.Errorf("backend de almacenamiento %q no soportado", cfg.Storage.Backend)
+}
+
+// newCertificadoRepository crea el repositorio de certificados emitidos según el backend configurado
+func newCertificadoRepository(ctx context.Context, cfg *config.Config) (repository.CertificadoRepository, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageMemory:
+		return memoryCertificadoRepo(), nil
+	case config.StorageFirestore:
+		firestoreClient, err := database.GetFirestoreClient(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewCertificadoRepository(firestoreClient), nil
+	}
+	return nil, fmt.Errorf("backend de almacenamiento %q no soportado", cfg.Storage.Backend)
+}
+
+// newAperturaRepository crea el repositorio del seguimiento de aperturas de cuenta según el backend configurado
+func newAperturaRepository(ctx context.Context, cfg *config.Config) (repository.AperturaRepository, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageMemory:
+		return memoryAperturaRepo(), nil
+	case config.StorageFirestore:
+		firestoreClient, err := database.GetFirestoreClient(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewAperturaRepository(firestoreClient), nil
+	}
+	return nil, fmt.Errorf("backend de almacenamiento %q no soportado", cfg.Storage.Backend)
+}
+
+// newBlobStore crea el almacenamiento de los archivos generados por los reportes
+func newBlobStore(ctx context.Context, cfg *config.Config) (blob.Store, error) {
+	switch cfg.Blob.Backend {
+	case config.BlobMemory:
+		return memoryBlobStore(), nil
+	case config.BlobFile:
+		return blob.NewFileStore(cfg.Blob.Dir)
+	case config.BlobGCS:
+		return blob.NewGCSStore(ctx, cfg.Blob.Bucket, cfg.Firebase.Credentials)
+	case "":
+		return nil, fmt.Errorf("los reportes requieren BLOB_BACKEND (memory, file o gcs)")
+	}
+	return nil, fmt.Errorf("backend de archivos %q no soportado", cfg.Blob.Backend)
+}
+
+// newRateLimiter crea el limitador de peticiones; devuelve nil si está deshabilitado
+func newRateLimiter(ctx context.Context, cfg *config.Config) (*ratelimit.Limiter, error) {
+	if !cfg.RateLimit.Enabled {
+		return nil, nil
+	}
+
+	limit := ratelimit.PerMinute(cfg.RateLimit.RequestsPerMinute, cfg.RateLimit.Burst)
+
+	switch cfg.RateLimit.Backend {
+	case config.StorageMemory:
+		return ratelimit.New(memoryRateLimitStore(), limit), nil
+	case config.StorageFirestore:
+		firestoreClient, err := database.GetFirestoreClient(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return ratelimit.New(ratelimit.NewFirestoreStore(firestoreClient), limit), nil
+	}
+	return nil, fmt.Errorf("backend de rate limit %q no soportado", cfg.RateLimit.Backend)
+}
+
+// newBranding construye el branding de los PDF a partir de la configuración
+func newBranding(cfg config.BrandingConfig) (pdf.Branding, error) {
+	branding := pdf.DefaultBranding()
+	branding.CompanyName = cfg.CompanyName
+	branding.RUC = cfg.RUC
+	branding.FooterText = cfg.FooterText
+	branding.Sections = cfg.Sections
+
+	if cfg.LogoPath != "" {
+		logo, err := os.ReadFile(cfg.LogoPath)
+		if err != nil {
+			return pdf.Branding{}, fmt.Errorf("error leyendo logo: %w", err)
+		}
+		branding.Logo = logo
+	}
+
+	if cfg.PrimaryColor != "" {
+		color, err := parseHexColor(cfg.PrimaryColor)
+		if err != nil {
+			return pdf.Branding{}, err
+		}
+		branding.PrimaryColor = color
+	}
+	if cfg.TextColor != "" {
+		color, err := parseHexColor(cfg.TextColor)
+		if err != nil {
+			return pdf.Branding{}, err
+		}
+		branding.TextColor = color
+	}
+
+	if cfg.FontFamily != "" {
+		branding.FontFamily = strings.ToLower(cfg.FontFamily)
+	}
+	if len(cfg.FontFiles) > 0 {
+		branding.CustomFonts = make(map[fontstyle.Type][]byte, len(cfg.FontFiles))
+		for style, path := range cfg.FontFiles {
+			fontStyle, ok := pdf.FontStyles[style]
+			if !ok {
+				styles := slices.Sorted(maps.Keys(pdf.FontStyles))
+				return pdf.Branding{}, fmt.Errorf("estilo de fuente %q no soportado (%s)", style, strings.Join(styles, ", "))
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return pdf.Branding{}, fmt.Errorf("error leyendo fuente: %w", err)
+			}
+			branding.CustomFonts[fontStyle] = data
+		}
+	}
+
+	if err := branding.Validate(); err != nil {
+		return pdf.Branding{}, err
+	}
+
+	return branding, nil
+}
+
+// newPDFGenerators crea un generador por tenant; la clave vacía es el branding por defecto
+func newPDFGenerators(ctx context.Context, cfg *config.Config) (map[string]*pdf.PDFGenerator, error) {
+	generators := make(map[string]*pdf.PDFGenerator, len(cfg.Tenants)+1)
+
+	docSigner, err := newDocumentSigner(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := pdf.Letters{
+		Certificado:   cfg.Certificados.Certificado,
+		Constancia:    cfg.Certificados.Constancia,
+		Ciudad:        cfg.Certificados.Ciudad,
+		Firmante:      cfg.Certificados.Firmante,
+		CargoFirmante: cfg.Certificados.CargoFirmante,
+	}
+	if err := letters.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, tenant := range append([]string{""}, tenantNames(cfg)...) {
+		brandingCfg, _ := cfg.BrandingFor(tenant)
+		branding, err := newBranding(brandingCfg)
+		if err != nil {
+			if tenant != "" {
+				return nil, fmt.Errorf("tenant %s: %w", tenant, err)
+			}
+			return nil, err
+		}
+		generators[tenant] = pdf.NewPDFGenerator(branding).WithSecurity(pdf.Security{
+			Watermark:     cfg.PDFSecurity.Watermark,
+			WatermarkText: cfg.PDFSecurity.WatermarkText,
+			PasswordDNI:   cfg.PDFSecurity.PasswordDNI,
+			OwnerPassword: cfg.PDFSecurity.OwnerPassword,
+			RestrictPrint: cfg.PDFSecurity.RestrictPrint,
+			RestrictCopy:  cfg.PDFSecurity.RestrictCopy,
+		}).WithLetters(letters)
+		if signer := newSigner(cfg); signer != nil {
+			generators[tenant].WithVerifier(signer)
+		}
+		if docSigner != nil {
+			generators[tenant].WithSigner(docSigner)
+		}
+	}
+
+	return generators, nil
+}
+
+// newSigner crea el firmador de los QR de verificación; devuelve nil si está deshabilitado
+func newSigner(cfg *config.Config) *verify.Signer {
+	if !cfg.Verify.Enabled() {
+		return nil
+	}
+	return verify.NewSigner(cfg.Verify.Secret, cfg.Verify.BaseURL)
+}
+
+// newDocumentSigner crea el firmador PAdES de los PDF; devuelve nil si está deshabilitado
+func newDocumentSigner(ctx context.Context, cfg *config.Config) (*signing.Signer, error) {
+	if !cfg.Signing.Enabled() {
+		return nil, nil
+	}
+
+	cert, err := readPEM(cfg.Signing.Cert, cfg.Signing.CertFile)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo certificado de firma: %w", err)
+	}
+	key, err := readPEM(cfg.Signing.Key, cfg.Signing.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo clave de firma: %w", err)
+	}
+
+	audit, err := newAuditRepository(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return signing.New(cert, key, signing.Options{
+		Name:        cfg.Signing.Name,
+		Reason:      cfg.Signing.Reason,
+		Location:    cfg.Signing.Location,
+		ContactInfo: cfg.Signing.ContactInfo,
+	}, audit)
+}
+
+// readPEM devuelve el PEM configurado directamente o el contenido del archivo
+func readPEM(value, path string) ([]byte, error) {
+	if value != "" {
+		return []byte(value), nil
+	}
+	return os.ReadFile(path)
+}
+
+// tenantNames devuelve los nombres de los tenants configurados
+func tenantNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Tenants))
+	for name := range cfg.Tenants {
+		names = append(names, name)
+	}
+	return names
+}
+
+// parseHexColor convierte un color #RRGGBB al formato del PDF
+func parseHexColor(value string) (*props.Color, error) {
+	var r, g, b int
+	if _, err := fmt.Sscanf(value, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, fmt.Errorf("color %q inválido: %w", value, err)
+	}
+	return &props.Color{Red: r, Green: g, Blue: b}, nil
+}
+
+// applyLogLevel configura el nivel del logger por defecto
+func applyLogLevel(cfg *config.Config) {
+	slog.SetLogLoggerLevel(cfg.SlogLevel())
+}
+
+// allowedOrigin devuelve el valor de Access-Control-Allow-Origin para el origen de la petición
+func allowedOrigin(origin string, allowed []string) string {
+	for _, o := range allowed {
+		if o == "*" {
+			return "*"
+		}
+		if origin != "" && strings.EqualFold(o, origin) {
+			return origin
+		}
+	}
+	return ""
+}
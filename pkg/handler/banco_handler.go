@@ -0,0 +1,411 @@
+package handler
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/export"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/repository"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// Formatos del archivo de apertura masiva
+const (
+	aperturaCSV = "csv"
+	aperturaTXT = "txt"
+)
+
+// BancoHandler genera las cartas de autorización y el archivo de apertura masiva de cuentas
+// y lleva el seguimiento de cada apertura
+type BancoHandler struct {
+	*UsuarioHandler
+	aperturas repository.AperturaRepository
+}
+
+// NewBancoHandler crea una nueva instancia del handler de bancos
+func NewBancoHandler(cfg *config.Config) (*BancoHandler, error) {
+	h, err := NewUsuarioHandler(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	aperturaRepo, err := newAperturaRepository(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BancoHandler{
+		UsuarioHandler: h,
+		aperturas:      aperturaRepo,
+	}, nil
+}
+
+// GetCartaBanco genera la carta de autorización de la cuenta sueldo o CTS (tipo) de un
+// trabajador y registra la apertura como solicitada si aún no existe. Acepta los mismos
+// query params de protección que la ficha.
+func (h *BancoHandler) GetCartaBanco(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	if !h.cfg.Features.PDF {
+		response.Error(w, http.StatusServiceUnavailable, "La generación de PDF está deshabilitada")
+		return
+	}
+
+	query := r.URL.Query()
+	doc, ok, err := parseDocumentoQuery(query)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if !ok {
+		response.BadRequest(w, "El documento es requerido")
+		return
+	}
+	tipo, ok := parseCuentaType(w, query.Get("tipo"))
+	if !ok {
+		return
+	}
+
+	pdfGen, err := h.pdfGenerator(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	security, err := h.parsePDFSecurity(r, pdfGen)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.PDF.Duration)
+	defer cancel()
+
+	usuario, err := h.service.GetUsuarioByDocumento(ctx, doc)
+	if err != nil {
+		log.Printf("Error obteniendo usuario: %v", err)
+		response.NotFound(w, "Usuario no encontrado")
+		return
+	}
+
+	apertura, err := models.NewAperturaCuenta(usuario, tipo, time.Now())
+	if err != nil {
+		unprocessable(w, err)
+		return
+	}
+
+	aperturas, err := h.aperturas.Request(ctx, []*models.AperturaCuenta{apertura})
+	if err != nil {
+		log.Printf("Error registrando apertura: %v", err)
+		response.InternalServerError(w, "Error al registrar la apertura")
+		return
+	}
+	apertura = aperturas[0]
+
+	pdfBytes, err := pdfGen.GenerateCartaBancoPDF(ctx, usuario, apertura, security)
+	if err != nil {
+		log.Printf("Error generando carta %s: %v", apertura.ID, err)
+		response.InternalServerError(w, "Error generando PDF")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	filename := fmt.Sprintf("carta_%s_%s.pdf", tipo, usuario.Documento().Numero)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+	w.Header().Set("X-Apertura-Estado", apertura.Estado)
+	w.Header().Set("Access-Control-Expose-Headers", "X-Apertura-Estado, Content-Disposition")
+
+	if _, err := w.Write(pdfBytes); err != nil {
+		log.Printf("Error escribiendo PDF: %v", err)
+	}
+}
+
+// CreateAperturaMasiva genera el archivo de apertura masiva en BCP, en el formato interno de
+// export y no en el layout del banco, con los trabajadores que cumplen los filtros del
+// listado, autorizaron la cuenta (tipo) en BCP y cuya apertura sigue solicitada. Es POST porque los que no tenían apertura quedan registrados como solicitados.
+// Los filtros van en los query params como en el listado; formato elige entre ancho fijo
+// (txt, por defecto) y csv.
+func (h *BancoHandler) CreateAperturaMasiva(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "POST" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	tipo, ok := parseCuentaType(w, query.Get("tipo"))
+	if !ok {
+		return
+	}
+
+	format := strings.TrimSpace(query.Get("formato"))
+	if format == "" {
+		format = aperturaTXT
+	}
+	var uw export.UsuarioWriter
+	var buf bytes.Buffer
+	contentType := export.AperturaTXTContentType
+	switch format {
+	case aperturaTXT:
+		uw = export.NewAperturaTXTWriter(&buf, tipo)
+	case aperturaCSV:
+		uw = export.NewAperturaCSVWriter(&buf, tipo)
+		contentType = export.CSVContentType
+	default:
+		response.BadRequest(w, fmt.Sprintf("formato debe ser %s o %s", aperturaTXT, aperturaCSV))
+		return
+	}
+
+	filter := parseUsuarioFilter(r)
+	if value := query.Get("limit"); value != "" {
+		limit, err := parseLimit(value, h.cfg.Limits)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		filter.Limit = limit
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Export.Duration)
+	defer cancel()
+
+	start := time.Now()
+	usuarios, err := h.service.ListUsuarios(ctx, filter)
+	if err != nil {
+		log.Printf("Error listando usuarios: %v", err)
+		response.InternalServerError(w, "Error al listar usuarios")
+		return
+	}
+
+	var autorizados []*models.Usuario
+	var solicitudes []*models.AperturaCuenta
+	for _, u := range usuarios {
+		apertura, err := models.NewAperturaCuenta(u, tipo, start)
+		if err != nil || apertura.Banco != models.BancoBCP {
+			continue
+		}
+		autorizados = append(autorizados, u)
+		solicitudes = append(solicitudes, apertura)
+	}
+
+	aperturas, err := h.aperturas.Request(ctx, solicitudes)
+	if err != nil {
+		log.Printf("Error registrando aperturas: %v", err)
+		response.InternalServerError(w, "Error al registrar las aperturas")
+		return
+	}
+
+	rows := 0
+	for i, apertura := range aperturas {
+		if apertura.Estado != models.AperturaSolicitada {
+			continue
+		}
+		if err := uw.Write(autorizados[i]); err != nil {
+			log.Printf("Error generando archivo de apertura: %v", err)
+			response.InternalServerError(w, "Error generando archivo")
+			return
+		}
+		rows++
+	}
+	if rows == 0 {
+		response.NotFound(w, "No hay aperturas pendientes en BCP con los filtros indicados")
+		return
+	}
+	if err := uw.Flush(); err != nil {
+		log.Printf("Error generando archivo de apertura: %v", err)
+		response.InternalServerError(w, "Error generando archivo")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	filename := fmt.Sprintf("apertura_bcp_interno_%s_%s.%s", tipo, start.Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", buf.Len()))
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("Error escribiendo archivo de apertura: %v", err)
+		return
+	}
+	metrics.ExportGenerated("apertura_"+format, time.Since(start), buf.Len())
+}
+
+// ListAperturas lista el seguimiento de las aperturas, las más recientes primero.
+// Filtros opcionales: tipo, estado, banco, dni y limit.
+func (h *BancoHandler) ListAperturas(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := parseLimit(query.Get("limit"), h.cfg.Limits)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	filter := models.AperturaFilter{
+		Tipo:   strings.TrimSpace(query.Get("tipo")),
+		Estado: strings.TrimSpace(query.Get("estado")),
+		Banco:  strings.TrimSpace(query.Get("banco")),
+		Dni:    strings.TrimSpace(cmp.Or(query.Get("numero_documento"), query.Get("dni"))),
+		Limit:  limit,
+	}
+	if filter.Tipo != "" && !models.IsCuentaType(filter.Tipo) {
+		response.BadRequest(w, fmt.Sprintf("tipo de cuenta %q no soportado (%v)", filter.Tipo, models.CuentaTypes))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Request.Duration)
+	defer cancel()
+
+	aperturas, err := h.aperturas.List(ctx, filter)
+	if err != nil {
+		log.Printf("Error listando aperturas: %v", err)
+		response.InternalServerError(w, "Error al listar aperturas")
+		return
+	}
+
+	response.Success(w, "Aperturas obtenidas exitosamente", aperturas)
+}
+
+// UpdateApertura avanza la apertura de un trabajador al estado siguiente: abierta cuando el
+// banco confirma la cuenta y registrada con el número de cuenta
+func (h *BancoHandler) UpdateApertura(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "POST" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.cfg.Limits.MaxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			response.RequestEntityTooLarge(w, fmt.Sprintf("El cuerpo de la petición no puede superar %d KB", maxErr.Limit/1024))
+			return
+		}
+		response.BadRequest(w, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	var req models.UpdateAperturaRequest
+	if err := decodeStrictJSON(body, &req); err != nil {
+		response.BadRequest(w, "Datos inválidos: "+err.Error())
+		return
+	}
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Request.Duration)
+	defer cancel()
+
+	apertura, err := h.aperturas.Advance(ctx, &req, time.Now())
+	if err != nil {
+		var vErr *models.ValidationError
+		switch {
+		case errors.Is(err, repository.ErrAperturaNotFound):
+			response.NotFound(w, "No hay apertura solicitada para el trabajador")
+		case errors.As(err, &vErr):
+			unprocessable(w, err)
+		default:
+			log.Printf("Error actualizando apertura: %v", err)
+			response.InternalServerError(w, "Error al actualizar la apertura")
+		}
+		return
+	}
+
+	// La cuenta registrada pasa a ser la cuenta activa del trabajador para ese propósito
+	if apertura.Estado == models.AperturaRegistrada && apertura.InCatalog() {
+		if _, err := h.service.SetCuentaActiva(ctx, apertura.Documento(), apertura.Cuenta()); err != nil {
+			log.Printf("Error registrando cuenta del trabajador %s: %v", apertura.Documento(), err)
+			response.InternalServerError(w, "La apertura se registró pero no se pudo guardar la cuenta del trabajador")
+			return
+		}
+	}
+
+	response.Success(w, "Apertura actualizada exitosamente", apertura)
+}
+
+// parseCuentaType valida el tipo de cuenta; vacío es la cuenta sueldo
+func parseCuentaType(w http.ResponseWriter, value string) (string, bool) {
+	tipo := strings.TrimSpace(value)
+	if tipo == "" {
+		return models.CuentaSueldo, true
+	}
+	if !models.IsCuentaType(tipo) {
+		response.BadRequest(w, fmt.Sprintf("tipo de cuenta %q no soportado (%v)", tipo, models.CuentaTypes))
+		return "", false
+	}
+	return tipo, true
+}
+
+// unprocessable responde 422 con el error de validación de reglas de negocio
+func unprocessable(w http.ResponseWriter, err error) {
+	var vErr *models.ValidationError
+	if errors.As(err, &vErr) {
+		metrics.ValidationFailure(vErr.Field)
+	}
+	response.Error(w, http.StatusUnprocessableEntity, err.Error())
+}
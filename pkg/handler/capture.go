@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// responseCapture escribe la respuesta al cliente y guarda una copia del código y el cuerpo
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
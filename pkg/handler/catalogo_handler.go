@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/catalog"
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// catalogMaxAge segundos que el navegador puede usar los catálogos sin revalidar
+const catalogMaxAge = 3600
+
+// CatalogoHandler publica las listas de referencia para los formularios del frontend; no
+// depende de Firestore
+type CatalogoHandler struct {
+	cfg *config.Config
+}
+
+// NewCatalogoHandler crea una nueva instancia del handler de catálogos
+func NewCatalogoHandler(cfg *config.Config) *CatalogoHandler {
+	return &CatalogoHandler{cfg: cfg}
+}
+
+// GetCatalogos devuelve todos los catálogos o solo el indicado en ?nombre=. El ETag sale
+// de las versiones, que resumen el contenido: el frontend revalida con If-None-Match y
+// recibe 304 si no cambió ninguna lista.
+func (h *CatalogoHandler) GetCatalogos(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	listas := catalog.Listas()
+	if nombre := strings.TrimSpace(r.URL.Query().Get("nombre")); nombre != "" {
+		lista, ok := catalog.ListaByNombre(nombre)
+		if !ok {
+			response.NotFound(w, fmt.Sprintf("El catálogo %q no existe", nombre))
+			return
+		}
+		listas = []catalog.Lista{lista}
+	}
+
+	etag := `"` + catalog.Version(listas) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", catalogMaxAge))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	response.Success(w, "Catálogos obtenidos exitosamente", listas)
+}
+
+// ubigeoResponse nivel consultado de la tabla de ubigeo y sus elementos
+type ubigeoResponse struct {
+	Nivel   string           `json:"nivel"`
+	Version string           `json:"version"`
+	Items   []catalog.Ubigeo `json:"items"`
+}
+
+// GetUbigeo consulta la tabla de ubigeo en cascada: sin parámetros lista los departamentos,
+// con ?departamento= sus provincias y con ?provincia= sus distritos, usando los códigos
+func (h *CatalogoHandler) GetUbigeo(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	query := r.URL.Query()
+	result := ubigeoResponse{Nivel: "departamento", Version: catalog.UbigeoVersion(), Items: catalog.Departamentos()}
+	switch provincia, departamento := strings.TrimSpace(query.Get("provincia")), strings.TrimSpace(query.Get("departamento")); {
+	case provincia != "":
+		if _, ok := catalog.UbigeoByCodigo(provincia); !ok || len(provincia) != 4 {
+			response.NotFound(w, fmt.Sprintf("La provincia %q no existe", provincia))
+			return
+		}
+		result.Nivel, result.Items = "distrito", catalog.Distritos(provincia)
+	case departamento != "":
+		if _, ok := catalog.UbigeoByCodigo(departamento); !ok || len(departamento) != 2 {
+			response.NotFound(w, fmt.Sprintf("El departamento %q no existe", departamento))
+			return
+		}
+		result.Nivel, result.Items = "provincia", catalog.Provincias(departamento)
+	}
+	if result.Items == nil {
+		result.Items = []catalog.Ubigeo{}
+	}
+
+	etag := `"ubigeo-` + catalog.UbigeoVersion() + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", catalogMaxAge))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	response.Success(w, "Ubigeo obtenido exitosamente", result)
+}
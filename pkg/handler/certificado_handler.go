@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/repository"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// CertificadoHandler emite los certificados y constancias de trabajo y lista los emitidos
+type CertificadoHandler struct {
+	*UsuarioHandler
+	certificados repository.CertificadoRepository
+}
+
+// NewCertificadoHandler crea una nueva instancia del handler de certificados
+func NewCertificadoHandler(cfg *config.Config) (*CertificadoHandler, error) {
+	h, err := NewUsuarioHandler(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	certRepo, err := newCertificadoRepository(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertificadoHandler{
+		UsuarioHandler: h,
+		certificados:   certRepo,
+	}, nil
+}
+
+// EmitirCertificado registra el certificado con el siguiente correlativo y responde el PDF.
+// El certificado de trabajo es para extrabajadores y la constancia para trabajadores en
+// actividad. Acepta los mismos query params de protección que la ficha.
+func (h *CertificadoHandler) EmitirCertificado(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "POST" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	if !h.cfg.Features.PDF {
+		response.Error(w, http.StatusServiceUnavailable, "La generación de PDF está deshabilitada")
+		return
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.cfg.Limits.MaxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			response.RequestEntityTooLarge(w, fmt.Sprintf("El cuerpo de la petición no puede superar %d KB", maxErr.Limit/1024))
+			return
+		}
+		response.BadRequest(w, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	var req models.CreateCertificadoRequest
+	if err := decodeStrictJSON(body, &req); err != nil {
+		response.BadRequest(w, "Datos inválidos: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	pdfGen, err := h.pdfGenerator(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	security, err := h.parsePDFSecurity(r, pdfGen)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.PDF.Duration)
+	defer cancel()
+
+	doc, _ := req.Documento()
+	usuario, err := h.service.GetUsuarioByDocumento(ctx, doc)
+	if err != nil {
+		log.Printf("Error obteniendo usuario: %v", err)
+		response.NotFound(w, "Usuario no encontrado")
+		return
+	}
+
+	cert, err := models.NewCertificado(req.Tipo, usuario, requestTenant(r), time.Now())
+	if err != nil {
+		var vErr *models.ValidationError
+		if errors.As(err, &vErr) {
+			metrics.ValidationFailure(vErr.Field)
+		}
+		response.Error(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	cert.Solicitante = security.Viewer
+
+	// El correlativo se asigna al registrar; si luego falla la generación el número queda
+	// registrado y se puede volver a emitir con uno nuevo
+	if err := h.certificados.Create(ctx, cert); err != nil {
+		log.Printf("Error registrando certificado: %v", err)
+		response.InternalServerError(w, "Error al registrar el certificado")
+		return
+	}
+
+	pdfBytes, err := pdfGen.GenerateCertificadoPDF(ctx, usuario, cert, security)
+	if err != nil {
+		log.Printf("Error generando certificado %s: %v", cert.Codigo, err)
+		response.InternalServerError(w, "Error generando PDF")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	filename := fmt.Sprintf("%s_%s_%s.pdf", cert.Tipo, cert.Dni, cert.Codigo)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+	w.Header().Set("X-Certificado-Codigo", cert.Codigo)
+	w.Header().Set("Access-Control-Expose-Headers", "X-Certificado-Codigo, Content-Disposition")
+
+	if _, err := w.Write(pdfBytes); err != nil {
+		log.Printf("Error escribiendo PDF: %v", err)
+	}
+}
+
+// ListCertificados lista los certificados emitidos del tenant, los más recientes primero.
+// Filtros opcionales: tipo, dni y limit.
+func (h *CertificadoHandler) ListCertificados(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := parseLimit(query.Get("limit"), h.cfg.Limits)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	filter := models.CertificadoFilter{
+		Tenant: requestTenant(r),
+		Tipo:   strings.TrimSpace(query.Get("tipo")),
+		Dni:    strings.TrimSpace(cmp.Or(query.Get("numero_documento"), query.Get("dni"))),
+		Limit:  limit,
+	}
+	if filter.Tipo != "" && !models.IsCertificadoType(filter.Tipo) {
+		response.BadRequest(w, fmt.Sprintf("tipo de certificado %q no soportado (%v)", filter.Tipo, models.CertificadoTypes))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Request.Duration)
+	defer cancel()
+
+	certs, err := h.certificados.List(ctx, filter)
+	if err != nil {
+		log.Printf("Error listando certificados: %v", err)
+		response.InternalServerError(w, "Error al listar certificados")
+		return
+	}
+
+	response.Success(w, "Certificados obtenidos exitosamente", certs)
+}
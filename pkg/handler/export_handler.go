@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/export"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// Tipos aceptados en el header Accept de la exportación; el primero es el formato por defecto
+const (
+	acceptXLSX   = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	acceptCSV    = "text/csv"
+	acceptNDJSON = "application/x-ndjson"
+	acceptJSONL  = "application/jsonl"
+)
+
+// streamFlushRows cantidad de filas entre cada envío parcial de la respuesta
+const streamFlushRows = 100
+
+// ExportUsuarios exporta el listado de usuarios con los mismos filtros del listado.
+// El formato se elige con el header Accept: XLSX (por defecto), CSV o NDJSON.
+// CSV y NDJSON se envían por streaming a medida que se recorre el cursor de Firestore.
+func (h *UsuarioHandler) ExportUsuarios(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	format := negotiate(r.Header.Get("Accept"), acceptXLSX, acceptCSV, acceptNDJSON, acceptJSONL)
+
+	// Igual que el padrón, la exportación incluye a todos los trabajadores salvo que se indique un límite
+	filter := parseUsuarioFilter(r)
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := parseLimit(value, h.cfg.Limits)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		filter.Limit = limit
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Export.Duration)
+	defer cancel()
+
+	switch format {
+	case acceptCSV:
+		h.streamUsuarios(ctx, w, filter, "csv", export.CSVContentType, func(out io.Writer) export.UsuarioWriter {
+			return export.NewCSVWriter(out)
+		})
+	case acceptNDJSON, acceptJSONL:
+		h.streamUsuarios(ctx, w, filter, "ndjson", export.NDJSONContentType, func(out io.Writer) export.UsuarioWriter {
+			return export.NewNDJSONWriter(out)
+		})
+	default:
+		h.exportXLSX(ctx, w, filter)
+	}
+}
+
+// exportXLSX genera el libro de Excel completo en memoria y lo envía
+func (h *UsuarioHandler) exportXLSX(ctx context.Context, w http.ResponseWriter, filter models.UsuarioFilter) {
+	usuarios, err := h.service.ListUsuarios(ctx, filter)
+	if err != nil {
+		log.Printf("Error listando usuarios: %v", err)
+		response.InternalServerError(w, "Error al listar usuarios")
+		return
+	}
+
+	data, err := export.UsuariosXLSX(ctx, usuarios)
+	if err != nil {
+		log.Printf("Error generando XLSX: %v", err)
+		response.InternalServerError(w, "Error generando Excel")
+		return
+	}
+
+	w.Header().Set("Content-Type", export.XLSXContentType)
+	setAttachment(w, "xlsx")
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+	if _, err := w.Write(data); err != nil {
+		log.Printf("Error escribiendo XLSX: %v", err)
+	}
+}
+
+// streamUsuarios escribe los usuarios a medida que llegan del repositorio. Los headers se
+// envían con el primer usuario, de modo que un error al iniciar la consulta aún responde 500;
+// un error a mitad del recorrido solo puede registrarse y cortar la respuesta.
+func (h *UsuarioHandler) streamUsuarios(ctx context.Context, w http.ResponseWriter, filter models.UsuarioFilter, format, contentType string, newWriter func(io.Writer) export.UsuarioWriter) {
+	start := time.Now()
+	out := &export.CountingWriter{W: w}
+	uw := newWriter(out)
+	rc := http.NewResponseController(w)
+
+	started := false
+	begin := func() {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		setAttachment(w, format)
+		w.WriteHeader(http.StatusOK)
+	}
+
+	rows := 0
+	err := h.service.StreamUsuarios(ctx, filter, func(u *models.Usuario) error {
+		if !started {
+			begin()
+		}
+		if err := uw.Write(u); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%streamFlushRows == 0 {
+			return flushStream(uw, rc)
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			log.Printf("Error exportando usuarios: %v", err)
+			response.InternalServerError(w, "Error al exportar usuarios")
+			return
+		}
+		log.Printf("Exportación %s interrumpida después de %d filas: %v", format, rows, err)
+		return
+	}
+
+	if !started {
+		begin()
+	}
+	if err := flushStream(uw, rc); err != nil {
+		log.Printf("Error enviando exportación %s: %v", format, err)
+		return
+	}
+
+	metrics.ExportGenerated(format, time.Since(start), out.N)
+}
+
+// GetFichasZIP genera un ZIP con la ficha PDF de cada trabajador que cumple los filtros
+// del listado (por ejemplo lugar_trabajo=Planta Iquitos) y un manifiesto CSV
+func (h *UsuarioHandler) GetFichasZIP(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	if !h.cfg.Features.PDF {
+		response.Error(w, http.StatusServiceUnavailable, "La generación de PDF está deshabilitada")
+		return
+	}
+
+	pdfGen, err := h.pdfGenerator(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	security, err := h.parsePDFSecurity(r, pdfGen)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	filter := parseUsuarioFilter(r)
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := parseLimit(value, h.cfg.Limits)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		filter.Limit = limit
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Export.Duration)
+	defer cancel()
+
+	usuarios, err := h.service.ListUsuarios(ctx, filter)
+	if err != nil {
+		log.Printf("Error listando usuarios: %v", err)
+		response.InternalServerError(w, "Error al listar usuarios")
+		return
+	}
+	if len(usuarios) == 0 {
+		response.NotFound(w, "No se encontraron trabajadores con los filtros indicados")
+		return
+	}
+
+	// Cada ficha tiene su propio timeout además del de la exportación completa
+	generate := func(ctx context.Context, u *models.Usuario) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(ctx, h.cfg.Timeouts.PDF.Duration)
+		defer cancel()
+		return pdfGen.GenerateUsuarioPDF(ctx, u, security)
+	}
+
+	w.Header().Set("Content-Type", export.ZIPContentType)
+	filename := fmt.Sprintf("fichas_%s.zip", time.Now().Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	// El ZIP se escribe directamente en la respuesta; un error a mitad solo puede registrarse
+	if err := export.FichasZIP(ctx, w, usuarios, h.cfg.Limits.PDFConcurrency, generate); err != nil {
+		log.Printf("Error generando ZIP de fichas: %v", err)
+	}
+}
+
+// flushStream envía al cliente las filas escritas hasta el momento
+func flushStream(uw export.UsuarioWriter, rc *http.ResponseController) error {
+	if err := uw.Flush(); err != nil {
+		return err
+	}
+	if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// setAttachment agrega el nombre del archivo descargado
+func setAttachment(w http.ResponseWriter, ext string) {
+	filename := fmt.Sprintf("personal_%s.%s", time.Now().Format("20060102"), ext)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/pdf"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// GetFotocheckPDF genera los fotochecks en hojas A4 para imprimir y recortar. Con dni genera
+// el de un trabajador; sin dni, los de todos los trabajadores que cumplen los filtros del
+// listado. codigo elige entre código de barras (por defecto) y QR del número de documento.
+func (h *UsuarioHandler) GetFotocheckPDF(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	if !h.cfg.Features.PDF {
+		response.Error(w, http.StatusServiceUnavailable, "La generación de PDF está deshabilitada")
+		return
+	}
+
+	query := r.URL.Query()
+	badgeCode := strings.TrimSpace(query.Get("codigo"))
+	if badgeCode == "" {
+		badgeCode = pdf.BadgeBarcode
+	}
+	if !pdf.IsBadgeCode(badgeCode) {
+		response.BadRequest(w, fmt.Sprintf("codigo debe ser %s o %s", pdf.BadgeBarcode, pdf.BadgeQR))
+		return
+	}
+
+	filter := parseUsuarioFilter(r)
+	if value := query.Get("limit"); value != "" {
+		limit, err := parseLimit(value, h.cfg.Limits)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		filter.Limit = limit
+	}
+
+	pdfGen, err := h.pdfGenerator(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	security, err := h.parsePDFSecurity(r, pdfGen)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.PDF.Duration)
+	defer cancel()
+
+	var usuarios []*models.Usuario
+	filename := fmt.Sprintf("fotochecks_%s.pdf", time.Now().Format("20060102"))
+	doc, ok, err := parseDocumentoQuery(query)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if ok {
+		usuario, err := h.service.GetUsuarioByDocumento(ctx, doc)
+		if err != nil {
+			log.Printf("Error obteniendo usuario: %v", err)
+			response.NotFound(w, "Usuario no encontrado")
+			return
+		}
+		usuarios = []*models.Usuario{usuario}
+		filename = fmt.Sprintf("fotocheck_%s.pdf", usuario.Documento().Numero)
+	} else {
+		usuarios, err = h.service.ListUsuarios(ctx, filter)
+		if err != nil {
+			log.Printf("Error listando usuarios: %v", err)
+			response.InternalServerError(w, "Error al listar usuarios")
+			return
+		}
+		if len(usuarios) == 0 {
+			response.NotFound(w, "No hay trabajadores con los filtros indicados")
+			return
+		}
+	}
+
+	pdfBytes, err := pdfGen.GenerateFotocheckPDF(ctx, usuarios, badgeCode, security)
+	if err != nil {
+		log.Printf("Error generando fotochecks: %v", err)
+		response.InternalServerError(w, "Error generando PDF")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+
+	if _, err := w.Write(pdfBytes); err != nil {
+		log.Printf("Error escribiendo PDF: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/database"
+	"github.com/luispfcanales/rainforestapp/pkg/health"
+	"github.com/luispfcanales/rainforestapp/pkg/pdf"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+const healthCheckTimeout = 5 * time.Second
+
+// HealthHandler maneja las peticiones de liveness y readiness
+type HealthHandler struct {
+	pdfGen *pdf.PDFGenerator
+}
+
+// NewHealthHandler crea una nueva instancia del handler de salud
+func NewHealthHandler() *HealthHandler {
+	return &HealthHandler{
+		pdfGen: pdf.NewPDFGenerator(pdf.DefaultBranding()),
+	}
+}
+
+// Health indica que el proceso está vivo, sin verificar dependencias
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, []string{"*"})
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	response.Success(w, "OK", map[string]string{
+		"status": health.StatusOK,
+		"time":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// Ready verifica la configuración, Firestore y el generador de PDF
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, []string{"*"})
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	// La configuración se carga aquí para reportar el error en lugar de fallar
+	cfg, cfgErr := config.Load()
+	if cfgErr == nil {
+		cfgErr = cfg.Validate()
+	}
+
+	checks := []health.Check{
+		{
+			Name: "config",
+			Run: func(ctx context.Context) error {
+				return cfgErr
+			},
+		},
+	}
+
+	// Con el backend en memoria no hay dependencia externa que verificar
+	if cfgErr != nil || cfg.Storage.Backend == config.StorageFirestore {
+		checks = append(checks, health.Check{
+			Name: "firestore",
+			Run: func(ctx context.Context) error {
+				if cfgErr != nil {
+					return fmt.Errorf("configuración inválida")
+				}
+				client, err := database.GetFirestoreClient(ctx, cfg)
+				if err != nil {
+					return err
+				}
+				return database.Ping(ctx, client)
+			},
+		})
+	}
+
+	checks = append(checks, health.Check{
+		Name: "pdf",
+		Run: func(ctx context.Context) error {
+			if cfgErr != nil {
+				return h.pdfGen.Check(ctx)
+			}
+			// Se verifica el branding de cada tenant configurado
+			generators, err := newPDFGenerators(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			for _, pdfGen := range generators {
+				if err := pdfGen.Check(ctx); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+
+	if cfgErr == nil && cfg.Signing.Enabled() {
+		checks = append(checks, health.Check{
+			Name: "firma",
+			Run: func(ctx context.Context) error {
+				signer, err := newDocumentSigner(ctx, cfg)
+				if err != nil {
+					return err
+				}
+				return signer.Check(time.Now())
+			},
+		})
+	}
+
+	report := health.Run(r.Context(), healthCheckTimeout, checks...)
+
+	if !report.Healthy() {
+		response.JSON(w, http.StatusServiceUnavailable, response.Response{
+			Success: false,
+			Error:   "Servicio no disponible",
+			Data:    report,
+		})
+		return
+	}
+
+	response.Success(w, "Servicio listo", report)
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/luispfcanales/rainforestapp/pkg/importer"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// importFileField campo del formulario multipart con el archivo a importar
+const importFileField = "archivo"
+
+// ImportUsuarios importa usuarios desde un archivo CSV o XLSX.
+// Por defecto solo valida (dry_run=true); con dry_run=false crea los usuarios válidos
+// y aplica la política indicada en politica (omitir o actualizar) a los documentos ya registrados.
+func (h *UsuarioHandler) ImportUsuarios(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "POST" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.cfg.Features.Registro {
+		response.Error(w, http.StatusServiceUnavailable, "El registro de usuarios está deshabilitado")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+
+	dryRun := true
+	if value := query.Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(w, "dry_run debe ser true o false")
+			return
+		}
+		dryRun = parsed
+	}
+
+	policy := models.ImportPolicyOmitir
+	if value := query.Get("politica"); value != "" {
+		policy = models.ImportPolicy(value)
+		if !policy.Valid() {
+			response.BadRequest(w, "politica debe ser omitir o actualizar")
+			return
+		}
+	}
+
+	data, format, err := readImportFile(w, r, h.cfg.Limits.MaxBodyBytes)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			response.RequestEntityTooLarge(w, fmt.Sprintf("El archivo no puede superar %d KB", maxErr.Limit/1024))
+			return
+		}
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	rows, err := importer.Parse(bytes.NewReader(data), format, h.cfg.Limits.MaxImportRows)
+	if err != nil {
+		response.BadRequest(w, "Archivo inválido: "+err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Import.Duration)
+	defer cancel()
+
+	report, err := h.service.ImportUsuarios(ctx, rows, models.ImportOptions{
+		DryRun: dryRun,
+		Policy: policy,
+		Limits: h.cfg.Limits.RequestLimits(),
+	})
+	if err != nil {
+		log.Printf("Error importando usuarios: %v", err)
+		response.InternalServerError(w, "Error al importar usuarios")
+		return
+	}
+
+	message := "Importación completada"
+	if dryRun {
+		message = "Validación completada"
+	}
+	response.Success(w, message, report)
+}
+
+// readImportFile lee el archivo desde un formulario multipart (campo archivo) o desde
+// el cuerpo de la petición; el formato se obtiene del nombre, del Content-Type o de ?formato=
+func readImportFile(w http.ResponseWriter, r *http.Request, maxBytes int64) ([]byte, importer.Format, error) {
+	body := http.MaxBytesReader(w, r.Body, maxBytes)
+	defer body.Close()
+
+	formatParam := r.URL.Query().Get("formato")
+	format, ok := importer.Format(formatParam), formatParam != ""
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		if !ok {
+			if format, ok = importer.FormatFromContentType(r.Header.Get("Content-Type")); !ok {
+				return nil, "", fmt.Errorf("tipo de contenido no soportado; use text/csv, XLSX o multipart/form-data")
+			}
+		}
+		data, err := io.ReadAll(body)
+		return data, format, err
+	}
+
+	r.Body = body
+	file, fileHeader, err := r.FormFile(importFileField)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, "", err
+		}
+		return nil, "", fmt.Errorf("el formulario debe incluir el campo %s con el archivo", importFileField)
+	}
+	defer file.Close()
+
+	if !ok {
+		if format, ok = importer.FormatFromName(fileHeader.Filename); !ok {
+			return nil, "", fmt.Errorf("el archivo debe tener extensión .csv o .xlsx")
+		}
+	}
+
+	data, err := io.ReadAll(file)
+	return data, format, err
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/ficha"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// Tipos aceptados en el header Accept de la vista previa; el primero es el formato por defecto
+const (
+	acceptHTML = "text/html"
+	acceptPDF  = "application/pdf"
+)
+
+// PreviewUsuario dibuja la ficha con los datos del formulario sin guardar nada. Recibe el
+// mismo cuerpo que el registro y responde HTML (por defecto) o PDF según el header Accept.
+func (h *UsuarioHandler) PreviewUsuario(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "POST" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	format := negotiate(r.Header.Get("Accept"), acceptHTML, acceptPDF)
+	if format == acceptPDF && !h.cfg.Features.PDF {
+		response.Error(w, http.StatusServiceUnavailable, "La generación de PDF está deshabilitada")
+		return
+	}
+
+	pdfGen, err := h.pdfGenerator(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.cfg.Limits.MaxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			response.RequestEntityTooLarge(w, fmt.Sprintf("El cuerpo de la petición no puede superar %d KB", maxErr.Limit/1024))
+			return
+		}
+		response.BadRequest(w, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	var req models.CreateUsuarioRequest
+	if err := decodeStrictJSON(body, &req); err != nil {
+		response.BadRequest(w, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	// El formulario puede estar incompleto; solo se controlan los tamaños
+	if err := req.ValidateLimits(h.cfg.Limits.RequestLimits()); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	usuario := req.ToUsuario()
+	w.Header().Set("Cache-Control", "no-store")
+
+	if format == acceptPDF {
+		pdfBytes, err := pdfGen.GeneratePreviewPDF(usuario)
+		if err != nil {
+			log.Printf("Error generando vista previa PDF: %v", err)
+			response.InternalServerError(w, "Error generando PDF")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", "inline; filename=\"vista_previa.pdf\"")
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+		if _, err := w.Write(pdfBytes); err != nil {
+			log.Printf("Error escribiendo PDF: %v", err)
+		}
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := ficha.RenderHTML(&buf, pdfGen.Layout(usuario), pdfGen.Theme(), time.Now()); err != nil {
+		log.Printf("Error generando vista previa HTML: %v", err)
+		response.InternalServerError(w, "Error generando la vista previa")
+		return
+	}
+
+	w.Header().Set("Content-Type", ficha.HTMLContentType)
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", buf.Len()))
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("Error escribiendo vista previa: %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/blob"
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/jobs"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/repository"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+)
+
+// ReportHandler maneja los reportes asíncronos: encolar, consultar el estado y descargar
+type ReportHandler struct {
+	*UsuarioHandler
+	jobs   repository.JobRepository
+	blobs  blob.Store
+	runner *jobs.Runner
+}
+
+// NewReportHandler crea una nueva instancia del handler de reportes
+func NewReportHandler(cfg *config.Config) (*ReportHandler, error) {
+	ctx := context.Background()
+
+	h, err := NewUsuarioHandler(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	jobRepo, err := newJobRepository(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newBlobStore(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReportHandler{
+		UsuarioHandler: h,
+		jobs:           jobRepo,
+		blobs:          store,
+		runner:         jobs.NewRunner(cfg, jobRepo, store, h.service, h.pdfGens),
+	}, nil
+}
+
+// SubmitReport encola un reporte y responde 202 con el trabajo creado. Con
+// jobs.in_process el trabajo se ejecuta de inmediato en el mismo proceso.
+func (h *ReportHandler) SubmitReport(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "POST" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.cfg.Limits.MaxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			response.RequestEntityTooLarge(w, fmt.Sprintf("El cuerpo de la petición no puede superar %d KB", maxErr.Limit/1024))
+			return
+		}
+		response.BadRequest(w, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	var req models.CreateJobRequest
+	if err := decodeStrictJSON(body, &req); err != nil {
+		response.BadRequest(w, "Datos inválidos: "+err.Error())
+		return
+	}
+	if req.Tenant == "" {
+		req.Tenant = requestTenant(r)
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if req.Limit > h.cfg.Limits.ListMax {
+		response.BadRequest(w, fmt.Sprintf("limit no puede ser mayor a %d", h.cfg.Limits.ListMax))
+		return
+	}
+	if _, ok := h.pdfGens[req.Tenant]; !ok {
+		response.BadRequest(w, fmt.Sprintf("tenant %q no configurado", req.Tenant))
+		return
+	}
+	if (req.Tipo == models.ReportPadron || req.Tipo == models.ReportFichas) && !h.cfg.Features.PDF {
+		response.Error(w, http.StatusServiceUnavailable, "La generación de PDF está deshabilitada")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Request.Duration)
+	defer cancel()
+
+	job := req.ToJob()
+	if err := h.jobs.Create(ctx, job); err != nil {
+		log.Printf("Error encolando reporte: %v", err)
+		response.InternalServerError(w, "Error al encolar el reporte")
+		return
+	}
+
+	if h.cfg.Jobs.InProcess {
+		go func() {
+			if _, err := h.runner.RunPending(context.WithoutCancel(ctx)); err != nil {
+				log.Printf("Error procesando reportes: %v", err)
+			}
+		}()
+	}
+
+	w.Header().Set("Location", "/api/reportes/estado?id="+job.ID)
+	response.JSON(w, http.StatusAccepted, response.Response{
+		Success: true,
+		Message: "Reporte encolado",
+		Data:    job,
+	})
+}
+
+// GetReportStatus devuelve el estado y el progreso de un trabajo
+func (h *ReportHandler) GetReportStatus(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	job, ok := h.findJob(w, r)
+	if !ok {
+		return
+	}
+
+	response.Success(w, "Estado del reporte", job)
+}
+
+// DownloadReport descarga el archivo de un trabajo terminado
+func (h *ReportHandler) DownloadReport(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	job, ok := h.findJob(w, r)
+	if !ok {
+		return
+	}
+
+	if job.Estado != models.JobDone {
+		response.Error(w, http.StatusConflict, fmt.Sprintf("El reporte no está listo (estado: %s)", job.Estado))
+		return
+	}
+	if job.Expired(time.Now()) {
+		response.Error(w, http.StatusGone, "El reporte expiró, solicítelo nuevamente")
+		return
+	}
+
+	data, contentType, err := h.blobs.Get(r.Context(), job.BlobKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		response.Error(w, http.StatusGone, "El archivo del reporte ya no está disponible")
+		return
+	}
+	if err != nil {
+		log.Printf("Error descargando reporte %s: %v", job.ID, err)
+		response.InternalServerError(w, "Error al descargar el reporte")
+		return
+	}
+
+	if contentType == "" {
+		contentType = job.ContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", job.FileName))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+	if _, err := w.Write(data); err != nil {
+		log.Printf("Error escribiendo reporte: %v", err)
+	}
+}
+
+// RunReportJobs procesa los trabajos en cola. Lo invoca el cron de Vercel con el header
+// Authorization: Bearer <CRON_SECRET>.
+func (h *ReportHandler) RunReportJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "POST" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if h.cfg.Jobs.CronSecret == "" {
+		response.Error(w, http.StatusServiceUnavailable, "CRON_SECRET no configurado")
+		return
+	}
+
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.Jobs.CronSecret)) != 1 {
+		response.Unauthorized(w, "No autorizado")
+		return
+	}
+
+	processed, err := h.runner.RunPending(r.Context())
+	if err != nil {
+		log.Printf("Error procesando reportes: %v", err)
+		response.InternalServerError(w, "Error al procesar los reportes")
+		return
+	}
+
+	response.Success(w, "Reportes procesados", map[string]int{
+		"procesados": processed,
+	})
+}
+
+// findJob obtiene el trabajo del query param id y responde el error si no existe
+func (h *ReportHandler) findJob(w http.ResponseWriter, r *http.Request) (*models.Job, bool) {
+	id := strings.TrimSpace(r.URL.Query().Get("id"))
+	if id == "" {
+		response.BadRequest(w, "El parámetro id es requerido")
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Request.Duration)
+	defer cancel()
+
+	job, err := h.jobs.Get(ctx, id)
+	if errors.Is(err, repository.ErrJobNotFound) {
+		response.NotFound(w, "Reporte no encontrado")
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error obteniendo reporte: %v", err)
+		response.InternalServerError(w, "Error al obtener el reporte")
+		return nil, false
+	}
+	return job, true
+}
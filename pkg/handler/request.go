@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// decodeStrictJSON decodifica el cuerpo rechazando campos desconocidos y datos adicionales
+func decodeStrictJSON(body []byte, dst interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return describeJSONError(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("el cuerpo debe contener un único objeto JSON")
+	}
+
+	return nil
+}
+
+// describeJSONError traduce los errores de encoding/json a mensajes claros
+func describeJSONError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("el cuerpo de la petición está vacío")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("JSON incompleto")
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("JSON mal formado en la posición %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("el campo %q debe ser de tipo %s", typeErr.Field, typeErr.Type.String())
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return fmt.Errorf("campo desconocido: %s", field)
+	}
+
+	return err
+}
+
+// parseDocumentoQuery lee el documento de los query params tipo_documento y numero_documento;
+// dni se sigue aceptando como documento DNI. ok es false si no se indicó ningún número.
+func parseDocumentoQuery(query url.Values) (doc models.Documento, ok bool, err error) {
+	numero, dni := query.Get("numero_documento"), query.Get("dni")
+	if strings.TrimSpace(numero) == "" && strings.TrimSpace(dni) == "" {
+		return models.Documento{}, false, nil
+	}
+	doc, err = models.ParseDocumento(query.Get("tipo_documento"), numero, dni)
+	return doc, true, err
+}
@@ -2,59 +2,114 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/luispfcanales/rainforestapp/pkg/config"
-	"github.com/luispfcanales/rainforestapp/pkg/database"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
 	"github.com/luispfcanales/rainforestapp/pkg/models"
 	"github.com/luispfcanales/rainforestapp/pkg/pdf"
+	"github.com/luispfcanales/rainforestapp/pkg/ratelimit"
 	"github.com/luispfcanales/rainforestapp/pkg/repository"
 	"github.com/luispfcanales/rainforestapp/pkg/response"
 	"github.com/luispfcanales/rainforestapp/pkg/service"
+	"github.com/luispfcanales/rainforestapp/pkg/verify"
+)
+
+const (
+	// idempotencyTTL tiempo que se conserva la respuesta de una Idempotency-Key
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyLease tiempo que una Idempotency-Key queda reservada mientras la petición está
+	// en proceso: la duración máxima de la función. Si la función muere sin completarla ni
+	// liberarla, pasado ese tiempo la puede tomar un reintento.
+	idempotencyLease        = 60 * time.Second
+	maxIdempotencyKeyLength = 255
+	// maxViewerLength largo máximo del visor impreso en la marca de agua
+	maxViewerLength = 80
 )
 
 // UsuarioHandler maneja las peticiones HTTP para usuarios
 type UsuarioHandler struct {
-	service *service.UsuarioService
-	pdfGen  *pdf.PDFGenerator
+	cfg         *config.Config
+	service     *service.UsuarioService
+	pdfGens     map[string]*pdf.PDFGenerator
+	idempotency repository.IdempotencyRepository
+	limiter     *ratelimit.Limiter
+	proxies     ratelimit.Proxies
+	signer      *verify.Signer
 }
 
 // NewUsuarioHandler crea una nueva instancia del handler
 func NewUsuarioHandler(cfg *config.Config) (*UsuarioHandler, error) {
 	ctx := context.Background()
 
-	// Inicializar cliente de Firestore
-	firestoreClient, err := database.GetFirestoreClient(ctx, cfg)
+	applyLogLevel(cfg)
+
+	// Crear repositorio según el backend configurado
+	repo, err := newUsuarioRepository(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// Crear repositorio y servicio
-	repo := repository.NewUsuarioRepository(firestoreClient)
 	svc := service.NewUsuarioService(repo)
 
-	pdfGen := pdf.NewPDFGenerator()
+	idempotencyRepo, err := newIdempotencyRepository(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter, err := newRateLimiter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	proxies, err := ratelimit.ParseProxies(cfg.Proxy.Trusted, cfg.Proxy.Vercel)
+	if err != nil {
+		return nil, err
+	}
+
+	pdfGens, err := newPDFGenerators(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	return &UsuarioHandler{
-		service: svc,
-		pdfGen:  pdfGen,
+		cfg:         cfg,
+		service:     svc,
+		pdfGens:     pdfGens,
+		idempotency: idempotencyRepo,
+		limiter:     limiter,
+		proxies:     proxies,
+		signer:      newSigner(cfg),
 	}, nil
 }
 
-// setupCORS configura los headers CORS
-func setupCORS(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+// setupCORS configura los headers CORS según los orígenes permitidos
+func setupCORS(w http.ResponseWriter, r *http.Request, allowedOrigins []string) {
+	if origin := allowedOrigin(r.Header.Get("Origin"), allowedOrigins); origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if origin != "*" {
+			w.Header().Add("Vary", "Origin")
+		}
+	}
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, X-Tenant")
 }
 
 // CreateUsuario maneja la creación de usuarios
 func (h *UsuarioHandler) CreateUsuario(w http.ResponseWriter, r *http.Request) {
-	setupCORS(w)
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
 
 	// Manejar preflight
 	if r.Method == "OPTIONS" {
@@ -68,23 +123,89 @@ func (h *UsuarioHandler) CreateUsuario(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.cfg.Features.Registro {
+		response.Error(w, http.StatusServiceUnavailable, "El registro de usuarios está deshabilitado")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
 	// Crear contexto con timeout
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Request.Duration)
 	defer cancel()
 
+	// Limitar el tamaño del cuerpo (incluye la foto en base64)
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.cfg.Limits.MaxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			response.RequestEntityTooLarge(w, fmt.Sprintf("El cuerpo de la petición no puede superar %d KB", maxErr.Limit/1024))
+			return
+		}
+		response.BadRequest(w, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	// Reintentos con la misma Idempotency-Key devuelven la respuesta original
+	idempotencyKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if idempotencyKey != "" {
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			response.BadRequest(w, fmt.Sprintf("Idempotency-Key no puede superar %d caracteres", maxIdempotencyKeyLength))
+			return
+		}
+
+		requestHash := sha256.Sum256(body)
+		owner := rand.Text()
+		record, err := h.idempotency.Reserve(ctx, idempotencyKey, hex.EncodeToString(requestHash[:]), owner, idempotencyLease)
+		if err != nil {
+			log.Printf("Error reservando Idempotency-Key: %v", err)
+			response.InternalServerError(w, "Error al procesar Idempotency-Key")
+			return
+		}
+		if record != nil {
+			replayIdempotentResponse(w, record, hex.EncodeToString(requestHash[:]))
+			return
+		}
+
+		capture := &responseCapture{ResponseWriter: w}
+		w = capture
+		defer func() {
+			// Solo se guardan las respuestas exitosas; ante un error se libera la clave
+			if capture.status >= 200 && capture.status < 300 {
+				if err := h.idempotency.Complete(context.WithoutCancel(ctx), idempotencyKey, owner, capture.status, capture.body.Bytes(), idempotencyTTL); err != nil {
+					log.Printf("Error guardando respuesta idempotente: %v", err)
+				}
+				return
+			}
+			if err := h.idempotency.Release(context.WithoutCancel(ctx), idempotencyKey, owner); err != nil {
+				log.Printf("Error liberando Idempotency-Key: %v", err)
+			}
+		}()
+	}
+
 	// Parsear request
 	var req models.CreateUsuarioRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodeStrictJSON(body, &req); err != nil {
 		response.BadRequest(w, "Datos inválidos: "+err.Error())
 		return
 	}
-	defer r.Body.Close()
+
+	if err := req.ValidateLimits(h.cfg.Limits.RequestLimits()); err != nil {
+		var vErr *models.ValidationError
+		if errors.As(err, &vErr) {
+			metrics.ValidationFailure(vErr.Field)
+		}
+		response.BadRequest(w, err.Error())
+		return
+	}
 
 	// Crear usuario
 	usuario, err := h.service.CreateUsuario(ctx, &req)
 	if err != nil {
-		log.Printf("Error creando usuario: %v", err)
-		response.InternalServerError(w, "Error al crear usuario: "+err.Error())
+		writeUsuarioError(w, "crear", err)
 		return
 	}
 
@@ -92,9 +213,42 @@ func (h *UsuarioHandler) CreateUsuario(w http.ResponseWriter, r *http.Request) {
 	response.Created(w, "Usuario registrado exitosamente", usuario)
 }
 
-// GetUsuario maneja la obtención de un usuario por DNI
+// writeUsuarioError responde un error al guardar un usuario: 400 si los datos no pasan la
+// validación (catálogos, ubigeo, direcciones, documento, cuentas), 409 si el documento ya
+// está registrado y 500 en otro caso
+func writeUsuarioError(w http.ResponseWriter, action string, err error) {
+	var vErr *models.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, service.ErrDocumentoRegistrado):
+		response.Error(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Error al %s usuario: %v", action, err)
+		response.InternalServerError(w, "Error al "+action+" usuario: "+err.Error())
+	}
+}
+
+// replayIdempotentResponse responde a una petición repetida con la misma Idempotency-Key
+func replayIdempotentResponse(w http.ResponseWriter, record *repository.IdempotencyRecord, requestHash string) {
+	if record.RequestHash != requestHash {
+		response.Error(w, http.StatusUnprocessableEntity, "Idempotency-Key ya fue usada con datos diferentes")
+		return
+	}
+	if !record.Completed {
+		response.Error(w, http.StatusConflict, "Una petición con esta Idempotency-Key está en proceso")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// GetUsuario maneja la obtención de un usuario por su documento de identidad
 func (h *UsuarioHandler) GetUsuario(w http.ResponseWriter, r *http.Request) {
-	setupCORS(w)
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
 
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
@@ -106,17 +260,25 @@ func (h *UsuarioHandler) GetUsuario(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Obtener ID de query params
-	dni := r.URL.Query().Get("dni")
-	if dni == "" {
-		response.BadRequest(w, "DNI es requerido")
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	// Obtener el documento de query params
+	doc, ok, err := parseDocumentoQuery(r.URL.Query())
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if !ok {
+		response.BadRequest(w, "El documento es requerido")
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Request.Duration)
 	defer cancel()
 
-	usuario, err := h.service.GetUsuarioByDNI(ctx, dni)
+	usuario, err := h.service.GetUsuarioByDocumento(ctx, doc)
 	if err != nil {
 		log.Printf("Error obteniendo usuario: %v", err)
 		response.NotFound(w, "Usuario no encontrado")
@@ -128,7 +290,7 @@ func (h *UsuarioHandler) GetUsuario(w http.ResponseWriter, r *http.Request) {
 
 // ListUsuarios maneja la lista de todos los usuarios
 func (h *UsuarioHandler) ListUsuarios(w http.ResponseWriter, r *http.Request) {
-	setupCORS(w)
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
 
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
@@ -140,10 +302,23 @@ func (h *UsuarioHandler) ListUsuarios(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	limit, err := parseLimit(r.URL.Query().Get("limit"), h.cfg.Limits)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	filter := parseUsuarioFilter(r)
+	filter.Limit = limit
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Request.Duration)
 	defer cancel()
 
-	usuarios, err := h.service.ListUsuarios(ctx, 100) // Límite de 100
+	usuarios, err := h.service.ListUsuarios(ctx, filter)
 	if err != nil {
 		log.Printf("Error listando usuarios: %v", err)
 		response.InternalServerError(w, "Error al listar usuarios")
@@ -155,7 +330,7 @@ func (h *UsuarioHandler) ListUsuarios(w http.ResponseWriter, r *http.Request) {
 
 // GetUsuarioPDF maneja la obtención de un usuario específico en PDF
 func (h *UsuarioHandler) GetUsuarioPDF(w http.ResponseWriter, r *http.Request) {
-	setupCORS(w)
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
 
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
@@ -167,18 +342,43 @@ func (h *UsuarioHandler) GetUsuarioPDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	if !h.cfg.Features.PDF {
+		response.Error(w, http.StatusServiceUnavailable, "La generación de PDF está deshabilitada")
+		return
+	}
+
 	// Obtener ID del usuario desde query params
-	usuarioDNI := r.URL.Query().Get("dni")
-	if usuarioDNI == "" {
-		response.Error(w, http.StatusBadRequest, "DNI de usuario requerido")
+	doc, ok, err := parseDocumentoQuery(r.URL.Query())
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if !ok {
+		response.Error(w, http.StatusBadRequest, "Documento de usuario requerido")
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	pdfGen, err := h.pdfGenerator(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	security, err := h.parsePDFSecurity(r, pdfGen)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.PDF.Duration)
 	defer cancel()
 
 	// Obtener usuario por ID
-	usuario, err := h.service.GetUsuarioByDNI(ctx, usuarioDNI)
+	usuario, err := h.service.GetUsuarioByDocumento(ctx, doc)
 	if err != nil {
 		log.Printf("Error obteniendo usuario: %v", err)
 		response.Error(w, http.StatusNotFound, "Usuario no encontrado")
@@ -186,7 +386,7 @@ func (h *UsuarioHandler) GetUsuarioPDF(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generar PDF individual
-	pdfBytes, err := h.pdfGen.GenerateUsuarioPDF(ctx, usuario)
+	pdfBytes, err := pdfGen.GenerateUsuarioPDF(ctx, usuario, security)
 	if err != nil {
 		log.Printf("Error generando PDF: %v", err)
 		response.InternalServerError(w, "Error generando PDF")
@@ -204,3 +404,188 @@ func (h *UsuarioHandler) GetUsuarioPDF(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Error escribiendo PDF: %v", err)
 	}
 }
+
+// GetPadronPDF genera el padrón de personal en PDF con los mismos filtros del listado
+func (h *UsuarioHandler) GetPadronPDF(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, h.cfg.CORS.AllowedOrigins)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	if !h.cfg.Features.PDF {
+		response.Error(w, http.StatusServiceUnavailable, "La generación de PDF está deshabilitada")
+		return
+	}
+
+	// El padrón incluye a todos los trabajadores salvo que se indique un límite
+	filter := parseUsuarioFilter(r)
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := parseLimit(value, h.cfg.Limits)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		filter.Limit = limit
+	}
+
+	pdfGen, err := h.pdfGenerator(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	security, err := h.parsePDFSecurity(r, pdfGen)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.PDF.Duration)
+	defer cancel()
+
+	usuarios, err := h.service.ListUsuarios(ctx, filter)
+	if err != nil {
+		log.Printf("Error listando usuarios: %v", err)
+		response.InternalServerError(w, "Error al listar usuarios")
+		return
+	}
+
+	pdfBytes, err := pdfGen.GenerateRosterPDF(ctx, usuarios, filter, security)
+	if err != nil {
+		log.Printf("Error generando padrón PDF: %v", err)
+		response.InternalServerError(w, "Error generando PDF")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	filename := fmt.Sprintf("padron_personal_%s.pdf", time.Now().Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+
+	if _, err := w.Write(pdfBytes); err != nil {
+		log.Printf("Error escribiendo PDF: %v", err)
+	}
+}
+
+// pdfGenerator devuelve el generador del tenant indicado en el query param tenant o el header X-Tenant
+func (h *UsuarioHandler) pdfGenerator(r *http.Request) (*pdf.PDFGenerator, error) {
+	tenant := requestTenant(r)
+	pdfGen, ok := h.pdfGens[tenant]
+	if !ok {
+		return nil, fmt.Errorf("tenant %q no configurado", tenant)
+	}
+	return pdfGen, nil
+}
+
+// requestTenant devuelve el tenant del query param tenant o del header X-Tenant
+func requestTenant(r *http.Request) string {
+	if tenant := strings.TrimSpace(r.URL.Query().Get("tenant")); tenant != "" {
+		return tenant
+	}
+	return strings.TrimSpace(r.Header.Get("X-Tenant"))
+}
+
+// parsePDFSecurity obtiene de los query params la protección que se agrega a la configurada:
+// marca_agua, visor (nombre impreso en la marca de agua y registrado en el certificado; por
+// defecto la IP del cliente), contrasena_dni, restringir_impresion y restringir_copia. La
+// petición no puede quitar la protección configurada. Si el generador firma los documentos no
+// se puede pedir cifrarlos.
+func (h *UsuarioHandler) parsePDFSecurity(r *http.Request, pdfGen *pdf.PDFGenerator) (pdf.Security, error) {
+	query := r.URL.Query()
+	var sec pdf.Security
+
+	flags := []struct {
+		param string
+		dst   *bool
+	}{
+		{"marca_agua", &sec.Watermark},
+		{"contrasena_dni", &sec.PasswordDNI},
+		{"restringir_impresion", &sec.RestrictPrint},
+		{"restringir_copia", &sec.RestrictCopy},
+	}
+	for _, flag := range flags {
+		value := strings.TrimSpace(query.Get(flag.param))
+		if value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return pdf.Security{}, fmt.Errorf("%s debe ser true o false", flag.param)
+		}
+		*flag.dst = enabled
+	}
+
+	sec.Viewer = strings.TrimSpace(query.Get("visor"))
+	if utf8.RuneCountInString(sec.Viewer) > maxViewerLength {
+		return pdf.Security{}, fmt.Errorf("visor no puede tener más de %d caracteres", maxViewerLength)
+	}
+	if sec.Viewer == "" {
+		sec.Viewer = h.proxies.ClientIP(r)
+	}
+	if err := pdfGen.CheckSecurity(sec); err != nil {
+		return pdf.Security{}, err
+	}
+	return sec, nil
+}
+
+// parseUsuarioFilter obtiene los filtros del listado desde los query params
+func parseUsuarioFilter(r *http.Request) models.UsuarioFilter {
+	query := r.URL.Query()
+	return models.UsuarioFilter{
+		LugarTrabajo:         strings.TrimSpace(query.Get("lugar_trabajo")),
+		PuestoActual:         strings.TrimSpace(query.Get("puesto_actual")),
+		SituacionContractual: strings.TrimSpace(query.Get("situacion_contractual")),
+		RegimenPensionario:   strings.TrimSpace(query.Get("regimen_pensionario")),
+	}
+}
+
+// parseLimit interpreta el parámetro limit aplicando el valor por defecto y el máximo configurado
+func parseLimit(value string, limits config.LimitsConfig) (int, error) {
+	if value == "" {
+		return limits.ListDefault, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("limit debe ser un número entero positivo")
+	}
+	if limit > limits.ListMax {
+		limit = limits.ListMax
+	}
+	return limit, nil
+}
+
+// allowRequest aplica el rate limit por IP; responde 429 y devuelve false si se excede
+func (h *UsuarioHandler) allowRequest(w http.ResponseWriter, r *http.Request) bool {
+	if h.limiter == nil {
+		return true
+	}
+
+	result, err := h.limiter.Allow(r.Context(), h.proxies.ClientIP(r))
+	if err != nil {
+		// Si el store no responde se permite la petición para no bloquear el registro
+		log.Printf("Error aplicando rate limit: %v", err)
+		return true
+	}
+
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(h.limiter.Limit().Burst))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+	if !result.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+		response.TooManyRequests(w, "Demasiadas peticiones, intente nuevamente más tarde")
+		return false
+	}
+	return true
+}
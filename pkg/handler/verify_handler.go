@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/response"
+	"github.com/luispfcanales/rainforestapp/pkg/verify"
+)
+
+// VerifyDocumento verifica el token del QR impreso en una ficha. Es público: responde la
+// fecha de emisión y el hash del contenido sin datos personales, e indica si el registro
+// del trabajador sigue coincidiendo con el documento.
+func (h *UsuarioHandler) VerifyDocumento(w http.ResponseWriter, r *http.Request) {
+	setupCORS(w, r, []string{"*"})
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		response.Error(w, http.StatusMethodNotAllowed, "Método no permitido")
+		return
+	}
+
+	if !h.allowRequest(w, r) {
+		return
+	}
+
+	if h.signer == nil {
+		response.Error(w, http.StatusServiceUnavailable, "La verificación de documentos no está configurada")
+		return
+	}
+
+	token := strings.TrimSpace(r.URL.Query().Get("token"))
+	if token == "" {
+		response.BadRequest(w, "El parámetro token es requerido")
+		return
+	}
+
+	claims, err := h.signer.Parse(token)
+	if err != nil {
+		response.BadRequest(w, "El documento no es auténtico o el código fue alterado")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeouts.Request.Duration)
+	defer cancel()
+
+	result := models.Verificacion{
+		Valido:    true,
+		Documento: claims.Documento,
+		EmitidoEn: claims.Issued(),
+		Hash:      claims.Hash,
+		Codigo:    verify.ShortCode(claims.Hash),
+		Registro:  models.RegistroNoDisponible,
+	}
+
+	usuario, err := h.service.GetUsuario(ctx, claims.UsuarioID)
+	if err != nil {
+		log.Printf("Error obteniendo usuario para verificación: %v", err)
+	} else if hash, err := verify.ContentHash(usuario, claims.Version); err == nil {
+		result.Registro = models.RegistroModificado
+		if hash == claims.Hash {
+			result.Registro = models.RegistroVigente
+		}
+	}
+
+	response.Success(w, "Documento auténtico", result)
+}
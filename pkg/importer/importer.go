@@ -0,0 +1,252 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// Format formato del archivo de importación
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// FormatFromName obtiene el formato a partir de la extensión del archivo
+func FormatFromName(name string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".txt":
+		return FormatCSV, true
+	case ".xlsx":
+		return FormatXLSX, true
+	}
+	return "", false
+}
+
+// FormatFromContentType obtiene el formato a partir del Content-Type
+func FormatFromContentType(contentType string) (Format, bool) {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case "text/csv", "application/csv", "text/plain":
+		return FormatCSV, true
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return FormatXLSX, true
+	}
+	return "", false
+}
+
+// columns índice de los campos de CreateUsuarioRequest por su nombre JSON
+var columns = func() map[string]int {
+	t := reflect.TypeOf(models.CreateUsuarioRequest{})
+	index := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			index[name] = i
+		}
+	}
+	return index
+}()
+
+// Parse lee las filas del archivo. La primera fila contiene los nombres de las
+// columnas de CreateUsuarioRequest; las listas y objetos se escriben como JSON.
+func Parse(r io.Reader, format Format, maxRows int) ([]models.ImportRow, error) {
+	var records [][]string
+	var err error
+
+	switch format {
+	case FormatCSV:
+		records, err = readCSV(r)
+	case FormatXLSX:
+		records, err = readXLSX(r)
+	default:
+		return nil, fmt.Errorf("formato %q no soportado (csv, xlsx)", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("el archivo está vacío")
+	}
+
+	header, names, err := parseHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.ImportRow
+	for i, record := range records[1:] {
+		if isEmpty(record) {
+			continue
+		}
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("el archivo no puede tener más de %d filas", maxRows)
+		}
+		row := parseRow(i+2, header, record)
+		row.Columns = names
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("el archivo no tiene filas de datos")
+	}
+
+	return rows, nil
+}
+
+// readCSV lee un CSV separado por comas o punto y coma (formato de Excel en español)
+func readCSV(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+
+	// Se descarta el BOM que agrega Excel al guardar en UTF-8
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		br.Discard(3)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if first, err := br.Peek(br.Buffered()); err == nil {
+		line, _, _ := bytes.Cut(first, []byte("\n"))
+		if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+			reader.Comma = ';'
+		}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo CSV: %w", err)
+	}
+	return records, nil
+}
+
+// readXLSX lee la primera hoja de un libro de Excel
+func readXLSX(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo XLSX: %w", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("el libro no tiene hojas")
+	}
+
+	records, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo hoja %s: %w", sheets[0], err)
+	}
+	return records, nil
+}
+
+// parseHeader obtiene el índice del campo de cada columna y sus nombres. Las celdas vacías al
+// final del encabezado se ignoran; una columna sin nombre entre otras o desconocida es un error.
+func parseHeader(record []string) ([]int, []string, error) {
+	for len(record) > 0 && strings.TrimSpace(record[len(record)-1]) == "" {
+		record = record[:len(record)-1]
+	}
+
+	header := make([]int, len(record))
+	names := make([]string, len(record))
+	seen := make(map[string]bool, len(record))
+	var unknown []string
+
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			return nil, nil, fmt.Errorf("la columna %d del encabezado no tiene nombre", i+1)
+		}
+		field, ok := columns[name]
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("%s (columna %d)", name, i+1))
+			continue
+		}
+		if seen[name] {
+			return nil, nil, fmt.Errorf("columna duplicada: %s", name)
+		}
+		seen[name] = true
+		header[i] = field
+		names[i] = name
+	}
+
+	if len(unknown) > 0 {
+		return nil, nil, fmt.Errorf("columnas desconocidas: %s", strings.Join(unknown, ", "))
+	}
+	if !seen["dni"] && !seen["numero_documento"] {
+		return nil, nil, fmt.Errorf("falta la columna numero_documento o dni")
+	}
+	return header, names, nil
+}
+
+// parseRow convierte un registro en un CreateUsuarioRequest acumulando los errores de cada columna
+func parseRow(line int, header []int, record []string) models.ImportRow {
+	row := models.ImportRow{Line: line}
+	v := reflect.ValueOf(&row.Request).Elem()
+	t := v.Type()
+
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if i >= len(header) {
+			row.Errors = append(row.Errors, fmt.Sprintf("la fila tiene más columnas que el encabezado (%d)", len(header)))
+			break
+		}
+
+		field := v.Field(header[i])
+		name, _, _ := strings.Cut(t.Field(header[i]).Tag.Get("json"), ",")
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Bool:
+			b, ok := parseBool(value)
+			if !ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s: valor %q inválido (SI/NO)", name, value))
+				continue
+			}
+			field.SetBool(b)
+		default:
+			// Listas y objetos anidados se escriben como JSON en la celda
+			if err := json.Unmarshal([]byte(value), field.Addr().Interface()); err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s: JSON inválido", name))
+			}
+		}
+	}
+
+	return row
+}
+
+// parseBool interpreta los valores booleanos habituales en las hojas de cálculo
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "si", "sí", "s", "x", "true", "verdadero", "1":
+		return true, true
+	case "no", "n", "false", "falso", "0":
+		return false, true
+	}
+	return false, false
+}
+
+func isEmpty(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
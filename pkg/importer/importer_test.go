@@ -0,0 +1,45 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHeader(t *testing.T) {
+	_, names, err := parseHeader([]string{"DNI", " nombres ", "", "  "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "dni,nombres" {
+		t.Fatalf("columnas = %v", names)
+	}
+
+	tests := []struct {
+		record []string
+		want   string
+	}{
+		{[]string{"dni", "", "nombres"}, "la columna 2 del encabezado no tiene nombre"},
+		{[]string{"dni", "nombre", "apellido"}, "columnas desconocidas: nombre (columna 2), apellido (columna 3)"},
+		{[]string{"dni", "DNI"}, "columna duplicada: dni"},
+		{[]string{"nombres", ""}, "falta la columna numero_documento o dni"},
+	}
+	for _, tt := range tests {
+		if _, _, err := parseHeader(tt.record); err == nil || err.Error() != tt.want {
+			t.Errorf("parseHeader(%q) = %v, want %q", tt.record, err, tt.want)
+		}
+	}
+}
+
+func TestParseIgnoraCeldasVaciasAlFinal(t *testing.T) {
+	csv := "dni;nombres;;\n12345678;Ana;;\n"
+	rows, err := Parse(strings.NewReader(csv), FormatCSV, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || len(rows[0].Errors) > 0 || rows[0].Request.Nombres != "Ana" {
+		t.Fatalf("filas = %+v", rows)
+	}
+	if strings.Join(rows[0].Columns, ",") != "dni,nombres" {
+		t.Fatalf("columnas = %v", rows[0].Columns)
+	}
+}
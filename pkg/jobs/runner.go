@@ -0,0 +1,309 @@
+// Package jobs ejecuta los trabajos de reportes asíncronos
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/blob"
+	"github.com/luispfcanales/rainforestapp/pkg/config"
+	"github.com/luispfcanales/rainforestapp/pkg/export"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/pdf"
+	"github.com/luispfcanales/rainforestapp/pkg/repository"
+	"github.com/luispfcanales/rainforestapp/pkg/service"
+)
+
+// progressInterval tiempo mínimo entre cada guardado del progreso
+const progressInterval = time.Second
+
+// Runner procesa los trabajos en cola
+type Runner struct {
+	cfg     *config.Config
+	repo    repository.JobRepository
+	store   blob.Store
+	service *service.UsuarioService
+	pdfGens map[string]*pdf.PDFGenerator
+}
+
+// NewRunner crea un nuevo runner
+func NewRunner(cfg *config.Config, repo repository.JobRepository, store blob.Store, svc *service.UsuarioService, pdfGens map[string]*pdf.PDFGenerator) *Runner {
+	return &Runner{
+		cfg:     cfg,
+		repo:    repo,
+		store:   store,
+		service: svc,
+		pdfGens: pdfGens,
+	}
+}
+
+// RunPending recupera los trabajos abandonados y procesa los que están en cola hasta
+// vaciarla o hasta que termine ctx. Devuelve la cantidad de trabajos procesados.
+// Varios runners pueden ejecutarse a la vez porque Claim toma cada trabajo una sola vez.
+func (r *Runner) RunPending(ctx context.Context) (int, error) {
+	if err := r.recoverStale(ctx); err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for ctx.Err() == nil {
+		job, err := r.repo.Claim(ctx, time.Now())
+		if err != nil {
+			return processed, err
+		}
+		if job == nil {
+			break
+		}
+
+		r.run(ctx, job)
+		processed++
+	}
+	return processed, nil
+}
+
+// recoverStale vuelve a encolar los trabajos que excedieron el timeout (la instancia que
+// los ejecutaba terminó) o los marca como fallidos si ya agotaron sus intentos
+func (r *Runner) recoverStale(ctx context.Context) error {
+	stale, err := r.repo.ListStale(ctx, time.Now().Add(-r.cfg.Jobs.Timeout.Duration))
+	if err != nil {
+		return err
+	}
+
+	for _, job := range stale {
+		if job.Intentos >= r.cfg.Jobs.MaxAttempts {
+			r.fail(ctx, job, fmt.Errorf("el trabajo excedió el tiempo máximo en %d intentos", job.Intentos))
+			continue
+		}
+
+		log.Printf("Reencolando trabajo %s (intento %d)", job.ID, job.Intentos)
+		job.Estado = models.JobQueued
+		job.StartedAt = nil
+		job.Progreso, job.Procesados = 0, 0
+		if err := r.repo.Update(ctx, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run genera el reporte, lo guarda en el blob store y actualiza el trabajo
+func (r *Runner) run(ctx context.Context, job *models.Job) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Jobs.Timeout.Duration)
+	defer cancel()
+
+	progress := &tracker{repo: r.repo, job: job}
+	result, err := r.generate(ctx, job, progress)
+	// Las fichas que aún se estén generando tras un error ya no modifican el trabajo
+	progress.stop()
+	if err != nil {
+		r.fail(ctx, job, err)
+		return
+	}
+
+	key := fmt.Sprintf("reportes/%s/%s", job.ID, result.fileName)
+	if err := r.store.Put(ctx, key, result.contentType, result.data); err != nil {
+		r.fail(ctx, job, err)
+		return
+	}
+
+	now := time.Now()
+	expires := now.Add(r.cfg.Jobs.ResultTTL.Duration)
+	job.Estado = models.JobDone
+	job.Progreso = 100
+	job.Procesados = job.Total
+	job.BlobKey = key
+	job.ContentType = result.contentType
+	job.FileName = result.fileName
+	job.Size = len(result.data)
+	job.FinishedAt = &now
+	job.ExpiresAt = &expires
+
+	if err := r.repo.Update(context.WithoutCancel(ctx), job); err != nil {
+		log.Printf("Error actualizando trabajo %s: %v", job.ID, err)
+		return
+	}
+	metrics.ReportJobFinished(job.Tipo, job.Estado)
+}
+
+// fail marca el trabajo como fallido con el mensaje del error
+func (r *Runner) fail(ctx context.Context, job *models.Job, cause error) {
+	log.Printf("Trabajo %s (%s) fallido: %v", job.ID, job.Tipo, cause)
+
+	now := time.Now()
+	job.Estado = models.JobFailed
+	job.Error = cause.Error()
+	job.FinishedAt = &now
+
+	// El trabajo se guarda aunque el error haya sido el timeout del contexto
+	if err := r.repo.Update(context.WithoutCancel(ctx), job); err != nil {
+		log.Printf("Error actualizando trabajo %s: %v", job.ID, err)
+		return
+	}
+	metrics.ReportJobFinished(job.Tipo, job.Estado)
+}
+
+// result archivo generado por un trabajo
+type result struct {
+	data        []byte
+	contentType string
+	fileName    string
+}
+
+// generate genera el archivo según el tipo de reporte
+func (r *Runner) generate(ctx context.Context, job *models.Job, progress *tracker) (*result, error) {
+	usuarios, err := r.service.ListUsuarios(ctx, job.Filter())
+	if err != nil {
+		return nil, err
+	}
+
+	progress.start(ctx, len(usuarios))
+	date := job.CreatedAt.Format("20060102")
+
+	switch job.Tipo {
+	case models.ReportXLSX:
+		data, err := export.UsuariosXLSX(ctx, usuarios)
+		if err != nil {
+			return nil, err
+		}
+		return &result{data, export.XLSXContentType, fmt.Sprintf("personal_%s.xlsx", date)}, nil
+
+	case models.ReportCSV:
+		data, err := writeUsuarios(ctx, export.NewCSVWriter, usuarios, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &result{data, export.CSVContentType, fmt.Sprintf("personal_%s.csv", date)}, nil
+
+	case models.ReportNDJSON:
+		data, err := writeUsuarios(ctx, export.NewNDJSONWriter, usuarios, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &result{data, export.NDJSONContentType, fmt.Sprintf("personal_%s.ndjson", date)}, nil
+
+	case models.ReportPadron:
+		pdfGen, err := r.pdfGenerator(job.Tenant)
+		if err != nil {
+			return nil, err
+		}
+		data, err := pdfGen.GenerateRosterPDF(ctx, usuarios, job.Filter(), pdf.Security{})
+		if err != nil {
+			return nil, err
+		}
+		return &result{data, "application/pdf", fmt.Sprintf("padron_personal_%s.pdf", date)}, nil
+
+	case models.ReportFichas:
+		if len(usuarios) == 0 {
+			return nil, errors.New("no se encontraron trabajadores con los filtros indicados")
+		}
+		pdfGen, err := r.pdfGenerator(job.Tenant)
+		if err != nil {
+			return nil, err
+		}
+
+		generate := func(ctx context.Context, u *models.Usuario) ([]byte, error) {
+			defer progress.add(ctx, 1)
+			ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeouts.PDF.Duration)
+			defer cancel()
+			// Los reportes asíncronos usan la protección configurada en el generador
+			return pdfGen.GenerateUsuarioPDF(ctx, u, pdf.Security{})
+		}
+
+		var buf bytes.Buffer
+		if err := export.FichasZIP(ctx, &buf, usuarios, r.cfg.Limits.PDFConcurrency, generate); err != nil {
+			return nil, err
+		}
+		return &result{buf.Bytes(), export.ZIPContentType, fmt.Sprintf("fichas_%s.zip", date)}, nil
+	}
+
+	return nil, fmt.Errorf("tipo de reporte %q no soportado", job.Tipo)
+}
+
+// pdfGenerator devuelve el generador del tenant del trabajo
+func (r *Runner) pdfGenerator(tenant string) (*pdf.PDFGenerator, error) {
+	if !r.cfg.Features.PDF {
+		return nil, errors.New("la generación de PDF está deshabilitada")
+	}
+	pdfGen, ok := r.pdfGens[tenant]
+	if !ok {
+		return nil, fmt.Errorf("tenant %q no configurado", tenant)
+	}
+	return pdfGen, nil
+}
+
+// writeUsuarios escribe los usuarios con el writer de la exportación informando el avance
+func writeUsuarios[W export.UsuarioWriter](ctx context.Context, newWriter func(io.Writer) W, usuarios []*models.Usuario, progress *tracker) ([]byte, error) {
+	var buf bytes.Buffer
+	uw := newWriter(&buf)
+	for _, u := range usuarios {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := uw.Write(u); err != nil {
+			return nil, err
+		}
+		progress.add(ctx, 1)
+	}
+	if err := uw.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// tracker guarda el avance del trabajo como máximo una vez por progressInterval
+type tracker struct {
+	mu      sync.Mutex
+	repo    repository.JobRepository
+	job     *models.Job
+	saved   time.Time
+	stopped bool
+}
+
+// start guarda el total de elementos del reporte
+func (t *tracker) start(ctx context.Context, total int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.job.Total = total
+	t.save(ctx)
+}
+
+// stop deja de actualizar el trabajo
+func (t *tracker) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopped = true
+}
+
+// add suma n elementos procesados; el 100% se reserva para cuando el archivo está guardado
+func (t *tracker) add(ctx context.Context, n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stopped {
+		return
+	}
+	t.job.Procesados += n
+	if t.job.Total > 0 {
+		t.job.Progreso = min(t.job.Procesados*100/t.job.Total, 99)
+	}
+
+	if time.Since(t.saved) >= progressInterval {
+		t.save(ctx)
+	}
+}
+
+func (t *tracker) save(ctx context.Context) {
+	t.saved = time.Now()
+	if err := t.repo.Update(ctx, t.job); err != nil {
+		log.Printf("Error guardando progreso del trabajo %s: %v", t.job.ID, err)
+	}
+}
@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// registry registro propio para no depender del registro global de Prometheus. Los
+// contadores son del proceso: solo son útiles si un único proceso de larga duración atiende
+// todas las rutas, como vercel dev en local. En Vercel cada función es un proceso independiente y efímero, así
+// que /api/metrics muestra solo lo que atendió la instancia que responde y se pierde al
+// reciclarla; ahí hay que usar las métricas de la plataforma.
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rainforest",
+			Name:      "http_requests_total",
+			Help:      "Total de peticiones HTTP por ruta, método y código de estado.",
+		},
+		[]string{"route", "method", "status"},
+	)
+
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "rainforest",
+			Name:      "http_request_duration_seconds",
+			Help:      "Latencia de las peticiones HTTP por ruta.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method"},
+	)
+
+	firestoreErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rainforest",
+			Name:      "firestore_errors_total",
+			Help:      "Total de errores en operaciones de Firestore.",
+		},
+		[]string{"operation"},
+	)
+
+	pdfGenerationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "rainforest",
+			Name:      "pdf_generation_duration_seconds",
+			Help:      "Tiempo de generación de documentos PDF.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+		},
+		[]string{"document"},
+	)
+
+	pdfSizeBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "rainforest",
+			Name:      "pdf_size_bytes",
+			Help:      "Tamaño de los documentos PDF generados.",
+			Buckets:   prometheus.ExponentialBuckets(16*1024, 2, 10),
+		},
+		[]string{"document"},
+	)
+
+	exportGenerationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "rainforest",
+			Name:      "export_generation_duration_seconds",
+			Help:      "Tiempo de generación de las exportaciones por formato.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+		},
+		[]string{"format"},
+	)
+
+	exportSizeBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "rainforest",
+			Name:      "export_size_bytes",
+			Help:      "Tamaño de las exportaciones generadas.",
+			Buckets:   prometheus.ExponentialBuckets(16*1024, 2, 10),
+		},
+		[]string{"format"},
+	)
+
+	validationFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rainforest",
+			Name:      "validation_failures_total",
+			Help:      "Total de validaciones fallidas por campo.",
+		},
+		[]string{"field"},
+	)
+
+	reportJobsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rainforest",
+			Name:      "report_jobs_total",
+			Help:      "Total de trabajos de reportes terminados por tipo y estado.",
+		},
+		[]string{"tipo", "estado"},
+	)
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		firestoreErrorsTotal,
+		pdfGenerationDuration,
+		pdfSizeBytes,
+		exportGenerationDuration,
+		exportSizeBytes,
+		validationFailuresTotal,
+		reportJobsTotal,
+	)
+}
+
+// Handler devuelve el handler HTTP que expone las métricas del proceso en formato Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// statusRecorder captura el código de estado escrito por el handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap permite a http.ResponseController acceder al writer original
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Instrument envuelve un handler registrando peticiones y latencia para la ruta indicada
+func Instrument(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+
+		next(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		httpRequestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(status)).Inc()
+		httpRequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// FirestoreError registra un error en una operación de Firestore
+func FirestoreError(operation string) {
+	firestoreErrorsTotal.WithLabelValues(operation).Inc()
+}
+
+// PDFGenerated registra la duración y el tamaño de un PDF generado
+func PDFGenerated(document string, duration time.Duration, size int) {
+	pdfGenerationDuration.WithLabelValues(document).Observe(duration.Seconds())
+	pdfSizeBytes.WithLabelValues(document).Observe(float64(size))
+}
+
+// ExportGenerated registra la duración y el tamaño de una exportación
+func ExportGenerated(format string, duration time.Duration, size int) {
+	exportGenerationDuration.WithLabelValues(format).Observe(duration.Seconds())
+	exportSizeBytes.WithLabelValues(format).Observe(float64(size))
+}
+
+// ValidationFailure registra una validación fallida para el campo indicado
+func ValidationFailure(field string) {
+	validationFailuresTotal.WithLabelValues(field).Inc()
+}
+
+// ReportJobFinished registra un trabajo de reporte terminado
+func ReportJobFinished(tipo, estado string) {
+	reportJobsTotal.WithLabelValues(tipo, estado).Inc()
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Acciones registradas en la auditoría
+const (
+	AuditPDFFirmado = "pdf_firmado"
+)
+
+// AuditEvent registro de la auditoría
+type AuditEvent struct {
+	ID        string `json:"id" firestore:"-"`
+	Accion    string `json:"accion" firestore:"accion"`
+	Documento string `json:"documento,omitempty" firestore:"documento,omitempty"`
+	UsuarioID string `json:"usuario_id,omitempty" firestore:"usuario_id,omitempty"`
+	// Detalle datos propios de la acción (por ejemplo, los de la firma)
+	Detalle   map[string]string `json:"detalle,omitempty" firestore:"detalle,omitempty"`
+	CreatedAt time.Time         `json:"created_at" firestore:"created_at"`
+}
@@ -0,0 +1,273 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/catalog"
+)
+
+// Tipos de cuenta que el trabajador autoriza abrir
+const (
+	CuentaSueldo = "sueldo"
+	CuentaCTS    = "cts"
+)
+
+// CuentaTypes tipos de cuenta soportados
+var CuentaTypes = []string{CuentaSueldo, CuentaCTS}
+
+// BancoBCP banco con el que se hace la apertura masiva de cuentas
+const BancoBCP = "BCP"
+
+// Estados de la apertura de una cuenta, en el orden en que avanzan
+const (
+	AperturaSolicitada = "solicitada"
+	AperturaAbierta    = "abierta"
+	AperturaRegistrada = "registrada"
+)
+
+// AperturaStates estados de la apertura en orden
+var AperturaStates = []string{AperturaSolicitada, AperturaAbierta, AperturaRegistrada}
+
+// AperturaCuenta seguimiento de la apertura de una cuenta de un trabajador: solicitada al
+// generar la carta o el archivo masivo, abierta cuando el banco la confirma y registrada
+// cuando se guarda el número de cuenta
+type AperturaCuenta struct {
+	ID        string `json:"id" firestore:"-"`
+	UsuarioID string `json:"usuario_id" firestore:"usuario_id"`
+	// TipoDocumento vacío en las aperturas anteriores, que son de trabajadores con DNI
+	TipoDocumento string `json:"tipo_documento,omitempty" firestore:"tipo_documento,omitempty"`
+	// Dni número del documento de identidad; conserva el nombre de los registros anteriores
+	Dni        string `json:"dni" firestore:"dni"`
+	Trabajador string `json:"trabajador" firestore:"trabajador"`
+	Tipo       string `json:"tipo" firestore:"tipo"`
+	Banco      string `json:"banco" firestore:"banco"`
+	Estado     string `json:"estado" firestore:"estado"`
+
+	NumeroCuenta string `json:"numero_cuenta,omitempty" firestore:"numero_cuenta,omitempty"`
+	Cci          string `json:"cci,omitempty" firestore:"cci,omitempty"`
+
+	SolicitadaAt time.Time  `json:"solicitada_at" firestore:"solicitada_at"`
+	AbiertaAt    *time.Time `json:"abierta_at,omitempty" firestore:"abierta_at,omitempty"`
+	RegistradaAt *time.Time `json:"registrada_at,omitempty" firestore:"registrada_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at" firestore:"updated_at"`
+}
+
+// AperturaID identificador de la apertura: una por trabajador y tipo de cuenta. Con DNI es
+// "numero-tipo", como en las aperturas anteriores; con otro documento lleva el tipo adelante.
+func AperturaID(doc Documento, tipo string) string {
+	if doc.Tipo == DocumentoDNI {
+		return doc.Numero + "-" + tipo
+	}
+	return doc.Tipo + "-" + doc.Numero + "-" + tipo
+}
+
+// Documento documento de identidad del trabajador de la apertura
+func (a *AperturaCuenta) Documento() Documento {
+	return Documento{Tipo: cmp.Or(a.TipoDocumento, DocumentoDNI), Numero: a.Dni}
+}
+
+// IsCuentaType indica si el tipo de cuenta existe
+func IsCuentaType(tipo string) bool {
+	return tipo == CuentaSueldo || tipo == CuentaCTS
+}
+
+// BancoAutorizado devuelve el banco en el que el trabajador autorizó abrir la cuenta del tipo
+// indicado. La cuenta sueldo en BCP tiene prioridad sobre la de otro banco; la CTS solo se
+// abre en BCP.
+func BancoAutorizado(u *Usuario, tipo string) (string, error) {
+	switch tipo {
+	case CuentaSueldo:
+		if u.AutorizaBcp {
+			return BancoBCP, nil
+		}
+		if u.AutorizaOtroBanco {
+			if cuenta := u.CuentaActiva(CuentaSueldo); cuenta != nil && cuenta.Banco != BancoBCP {
+				return cuenta.Banco, nil
+			}
+			if banco, ok := catalog.FindBanco(u.OtroBancoNombre); ok {
+				return banco.Codigo, nil
+			}
+			if banco := strings.TrimSpace(u.OtroBancoNombre); banco != "" {
+				return banco, nil
+			}
+			return "", newValidationError("cuentas_bancarias", "el trabajador autorizó otro banco pero no registró su cuenta sueldo")
+		}
+		return "", newValidationError("autoriza_bcp", "el trabajador no autorizó la apertura de cuenta sueldo")
+	case CuentaCTS:
+		if u.AutorizaCtsBcp {
+			return BancoBCP, nil
+		}
+		return "", newValidationError("autoriza_cts_bcp", "el trabajador no autorizó la apertura de cuenta CTS")
+	}
+	return "", newValidationError("tipo", fmt.Sprintf("tipo de cuenta %q no soportado (%v)", tipo, CuentaTypes))
+}
+
+// NewAperturaCuenta crea la apertura solicitada de la cuenta autorizada por el trabajador.
+// Si el trabajador ya tiene una cuenta activa en ese banco, o indicó su cuenta en otro banco,
+// la apertura queda registrada.
+func NewAperturaCuenta(u *Usuario, tipo string, now time.Time) (*AperturaCuenta, error) {
+	banco, err := BancoAutorizado(u, tipo)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := u.Documento()
+	a := &AperturaCuenta{
+		ID:            AperturaID(doc, tipo),
+		UsuarioID:     u.ID,
+		TipoDocumento: doc.Tipo,
+		Dni:           doc.Numero,
+		Trabajador:    strings.Join(strings.Fields(u.Nombres+" "+u.ApellidoPaterno+" "+u.ApellidoMaterno), " "),
+		Tipo:          tipo,
+		Banco:         banco,
+		Estado:        AperturaSolicitada,
+		SolicitadaAt:  now,
+		UpdatedAt:     now,
+	}
+	switch cuenta := u.CuentaActiva(tipo); {
+	case cuenta != nil && cuenta.Banco == banco:
+		a.registrar(cuenta.NumeroCuenta, cuenta.Cci, now)
+	case banco != BancoBCP && strings.TrimSpace(u.OtroBancoCuenta) != "":
+		a.registrar(strings.TrimSpace(u.OtroBancoCuenta), strings.TrimSpace(u.OtroBancoCci), now)
+	}
+	return a, nil
+}
+
+// registrar marca la apertura como registrada con la cuenta existente del trabajador
+func (a *AperturaCuenta) registrar(numero, cci string, now time.Time) {
+	a.Estado = AperturaRegistrada
+	a.AbiertaAt = &now
+	a.RegistradaAt = &now
+	a.NumeroCuenta = numero
+	a.Cci = cci
+}
+
+// Cuenta cuenta bancaria registrada en la apertura
+func (a *AperturaCuenta) Cuenta() CuentaBancaria {
+	return CuentaBancaria{
+		Proposito:    a.Tipo,
+		Banco:        a.Banco,
+		NumeroCuenta: a.NumeroCuenta,
+		Cci:          a.Cci,
+		Moneda:       MonedaSoles,
+		Activa:       true,
+	}
+}
+
+// InCatalog indica si el banco de la apertura está en el catálogo; las aperturas de otro banco
+// registradas con texto libre no lo están
+func (a *AperturaCuenta) InCatalog() bool {
+	_, ok := catalog.BancoByCodigo(a.Banco)
+	return ok
+}
+
+// UpdateAperturaRequest DTO para avanzar el estado de una apertura; dni equivale a un
+// documento DNI
+type UpdateAperturaRequest struct {
+	TipoDocumento   string `json:"tipo_documento,omitempty"`
+	NumeroDocumento string `json:"numero_documento,omitempty"`
+	Dni             string `json:"dni,omitempty"`
+	Tipo            string `json:"tipo"`
+	Estado          string `json:"estado"`
+	NumeroCuenta    string `json:"numero_cuenta,omitempty"`
+	Cci             string `json:"cci,omitempty"`
+}
+
+// Validate valida el documento, el tipo de cuenta, el estado y que el registro incluya el
+// número de cuenta
+func (r *UpdateAperturaRequest) Validate() error {
+	if _, err := r.Documento(); err != nil {
+		return err
+	}
+	if !IsCuentaType(r.Tipo) {
+		return newValidationError("tipo", fmt.Sprintf("tipo de cuenta %q no soportado (%v)", r.Tipo, CuentaTypes))
+	}
+	if r.Estado != AperturaAbierta && r.Estado != AperturaRegistrada {
+		return newValidationError("estado", fmt.Sprintf("estado debe ser %s o %s", AperturaAbierta, AperturaRegistrada))
+	}
+	if r.Estado == AperturaRegistrada && strings.TrimSpace(r.NumeroCuenta) == "" {
+		return newValidationError("numero_cuenta", "el número de cuenta es requerido para registrar la apertura")
+	}
+	return nil
+}
+
+// Documento documento del trabajador indicado en el request
+func (r *UpdateAperturaRequest) Documento() (Documento, error) {
+	return ParseDocumento(r.TipoDocumento, r.NumeroDocumento, r.Dni)
+}
+
+// AperturaID identificador de la apertura a actualizar; vacío si el documento no es válido
+func (r *UpdateAperturaRequest) AperturaID() string {
+	doc, err := r.Documento()
+	if err != nil {
+		return ""
+	}
+	return AperturaID(doc, r.Tipo)
+}
+
+// Normalize quita los espacios y deja el CCI solo con dígitos
+func (r *UpdateAperturaRequest) Normalize() {
+	cuenta := CuentaBancaria{NumeroCuenta: r.NumeroCuenta, Cci: r.Cci}
+	cuenta.Normalize()
+	r.Dni = strings.TrimSpace(r.Dni)
+	r.NumeroCuenta = cuenta.NumeroCuenta
+	r.Cci = cuenta.Cci
+}
+
+// Advance aplica el nuevo estado; solo se avanza al estado siguiente
+func (a *AperturaCuenta) Advance(req *UpdateAperturaRequest, now time.Time) error {
+	if aperturaStep(req.Estado) != aperturaStep(a.Estado)+1 {
+		return newValidationError("estado", fmt.Sprintf("la apertura está %s y no puede pasar a %s", a.Estado, req.Estado))
+	}
+
+	// El CCI se contrasta con el banco solo si el banco está en el catálogo
+	if req.Estado == AperturaRegistrada && a.InCatalog() {
+		cuenta := a.Cuenta()
+		cuenta.NumeroCuenta, cuenta.Cci = req.NumeroCuenta, req.Cci
+		if err := cuenta.Validate(); err != nil {
+			return err
+		}
+	}
+
+	a.Estado = req.Estado
+	a.UpdatedAt = now
+	switch req.Estado {
+	case AperturaAbierta:
+		a.AbiertaAt = &now
+	case AperturaRegistrada:
+		a.RegistradaAt = &now
+		a.NumeroCuenta = req.NumeroCuenta
+		a.Cci = req.Cci
+	}
+	return nil
+}
+
+// aperturaStep posición del estado en AperturaStates; -1 si no existe
+func aperturaStep(estado string) int {
+	for i, s := range AperturaStates {
+		if s == estado {
+			return i
+		}
+	}
+	return -1
+}
+
+// AperturaFilter filtros del listado de aperturas
+type AperturaFilter struct {
+	Tipo   string
+	Estado string
+	Banco  string
+	Dni    string
+	Limit  int
+}
+
+// Matches indica si la apertura cumple con los filtros
+func (f AperturaFilter) Matches(a *AperturaCuenta) bool {
+	return (f.Tipo == "" || a.Tipo == f.Tipo) &&
+		(f.Estado == "" || a.Estado == f.Estado) &&
+		(f.Banco == "" || a.Banco == f.Banco) &&
+		(f.Dni == "" || a.Dni == f.Dni)
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/catalog"
+)
+
+// catalogValue campo codificado del usuario: el nombre del campo en los errores, su catálogo
+// y el valor
+type catalogValue struct {
+	field    string
+	catalogo *catalog.Catalogo
+	value    *string
+}
+
+// catalogValues campos del usuario que se guardan con el código de su catálogo
+func catalogValues(afp, salud, parentesco *string, basica []EducacionBasica, superior []EducacionSuperior, idiomas []Idioma) []catalogValue {
+	values := []catalogValue{
+		{"afp_nombre", catalog.AFP, afp},
+		{"regimen_salud", catalog.RegimenSalud, salud},
+		{"contacto_parentesco", catalog.Parentesco, parentesco},
+	}
+	for i := range basica {
+		values = append(values, catalogValue{"educacion_basica.nivel", catalog.NivelEducativo, &basica[i].Nivel})
+	}
+	for i := range superior {
+		values = append(values, catalogValue{"educacion_superior.nivel", catalog.NivelEducativo, &superior[i].Nivel})
+	}
+	for i := range idiomas {
+		values = append(values,
+			catalogValue{"idiomas.lee", catalog.NivelIdioma, &idiomas[i].Lee},
+			catalogValue{"idiomas.habla", catalog.NivelIdioma, &idiomas[i].Habla},
+			catalogValue{"idiomas.escribe", catalog.NivelIdioma, &idiomas[i].Escribe},
+		)
+	}
+	return values
+}
+
+// validateCatalogs valida que los campos codificados con valor estén en su catálogo; se
+// aceptan el código, el nombre o un nombre alternativo
+func validateCatalogs(values []catalogValue) error {
+	for _, v := range values {
+		if strings.TrimSpace(*v.value) == "" {
+			continue
+		}
+		if _, ok := v.catalogo.Find(*v.value); !ok {
+			return newValidationError(v.field, fmt.Sprintf("%q no existe en el catálogo %s (%s)",
+				strings.TrimSpace(*v.value), v.catalogo.Nombre, strings.Join(v.catalogo.Codigos(), ", ")))
+		}
+	}
+	return nil
+}
+
+// NormalizeCatalogs reemplaza los valores de los campos codificados por el código de su
+// catálogo. Devuelve si cambió algún valor y los valores que no están en el catálogo, que se
+// dejan como estaban. Los arreglos se copian antes de modificarlos.
+func (u *Usuario) NormalizeCatalogs() (changed bool, unknown []string) {
+	u.EducacionBasica = slices.Clone(u.EducacionBasica)
+	u.EducacionSuperior = slices.Clone(u.EducacionSuperior)
+	u.Idiomas = slices.Clone(u.Idiomas)
+
+	for _, v := range catalogValues(&u.AfpNombre, &u.RegimenSalud, &u.ContactoParentesco, u.EducacionBasica, u.EducacionSuperior, u.Idiomas) {
+		value := strings.TrimSpace(*v.value)
+		if value == "" {
+			continue
+		}
+		item, ok := v.catalogo.Find(value)
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("%s %q no está en el catálogo %s", v.field, value, v.catalogo.Nombre))
+			continue
+		}
+		if *v.value != item.Codigo {
+			*v.value = item.Codigo
+			changed = true
+		}
+	}
+	return changed, unknown
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tipos de certificado laboral
+const (
+	// CertificadoTrabajo certificado de trabajo de un extrabajador (requiere fecha de cese)
+	CertificadoTrabajo = "certificado"
+	// ConstanciaTrabajo constancia de trabajo de un trabajador en actividad
+	ConstanciaTrabajo = "constancia"
+)
+
+// CertificadoTypes tipos de certificado soportados
+var CertificadoTypes = []string{CertificadoTrabajo, ConstanciaTrabajo}
+
+// certificadoPrefixes prefijo del código de cada tipo
+var certificadoPrefixes = map[string]string{
+	CertificadoTrabajo: "CT",
+	ConstanciaTrabajo:  "CO",
+}
+
+// Certificado registro de un certificado o constancia emitido. Numero es el correlativo
+// del tipo dentro del año y del tenant, asignado por el repositorio al guardarlo.
+type Certificado struct {
+	ID     string `json:"id" firestore:"-"`
+	Tipo   string `json:"tipo" firestore:"tipo"`
+	Numero int    `json:"numero" firestore:"numero"`
+	Anio   int    `json:"anio" firestore:"anio"`
+	Codigo string `json:"codigo" firestore:"codigo"`
+	Tenant string `json:"tenant" firestore:"tenant"`
+
+	// Datos del trabajador al momento de la emisión
+	UsuarioID string `json:"usuario_id" firestore:"usuario_id"`
+	// TipoDocumento vacío en los certificados anteriores, que son de trabajadores con DNI
+	TipoDocumento string `json:"tipo_documento,omitempty" firestore:"tipo_documento,omitempty"`
+	// Dni número del documento de identidad; conserva el nombre de los registros anteriores
+	Dni          string `json:"dni" firestore:"dni"`
+	Trabajador   string `json:"trabajador" firestore:"trabajador"`
+	Puesto       string `json:"puesto" firestore:"puesto"`
+	FechaIngreso string `json:"fecha_ingreso" firestore:"fecha_ingreso"`
+	FechaCese    string `json:"fecha_cese,omitempty" firestore:"fecha_cese,omitempty"`
+
+	// Solicitante quien descargó el documento (visor o IP del cliente)
+	Solicitante string    `json:"solicitante,omitempty" firestore:"solicitante,omitempty"`
+	CreatedAt   time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// SetNumero asigna el correlativo y arma el código del certificado (CT-2026-000012)
+func (c *Certificado) SetNumero(numero int) {
+	c.Numero = numero
+	c.Codigo = fmt.Sprintf("%s-%d-%06d", certificadoPrefixes[c.Tipo], c.Anio, numero)
+}
+
+// CorrelativoKey clave del contador del correlativo: tipo, año y tenant
+func (c *Certificado) CorrelativoKey() string {
+	key := fmt.Sprintf("%s-%d", c.Tipo, c.Anio)
+	if c.Tenant != "" {
+		key = c.Tenant + "-" + key
+	}
+	return key
+}
+
+// Documento documento de identidad del trabajador del certificado
+func (c *Certificado) Documento() Documento {
+	return Documento{Tipo: cmp.Or(c.TipoDocumento, DocumentoDNI), Numero: c.Dni}
+}
+
+// CreateCertificadoRequest DTO para emitir un certificado; dni equivale a un documento DNI
+type CreateCertificadoRequest struct {
+	Tipo            string `json:"tipo"`
+	TipoDocumento   string `json:"tipo_documento,omitempty"`
+	NumeroDocumento string `json:"numero_documento,omitempty"`
+	Dni             string `json:"dni,omitempty"`
+}
+
+// Validate valida el tipo y el documento del trabajador
+func (r *CreateCertificadoRequest) Validate() error {
+	if !IsCertificadoType(r.Tipo) {
+		return newValidationError("tipo", fmt.Sprintf("tipo de certificado %q no soportado (%v)", r.Tipo, CertificadoTypes))
+	}
+	_, err := r.Documento()
+	return err
+}
+
+// Documento documento del trabajador indicado en el request
+func (r *CreateCertificadoRequest) Documento() (Documento, error) {
+	return ParseDocumento(r.TipoDocumento, r.NumeroDocumento, r.Dni)
+}
+
+// IsCertificadoType indica si el tipo de certificado existe
+func IsCertificadoType(tipo string) bool {
+	_, ok := certificadoPrefixes[tipo]
+	return ok
+}
+
+// NewCertificado arma el registro del certificado del trabajador. El certificado de trabajo
+// solo se emite a extrabajadores y la constancia solo a trabajadores en actividad.
+func NewCertificado(tipo string, u *Usuario, tenant string, now time.Time) (*Certificado, error) {
+	if strings.TrimSpace(u.FechaIngreso) == "" {
+		return nil, newValidationError("fecha_ingreso", "el trabajador no tiene fecha de ingreso registrada")
+	}
+	if strings.TrimSpace(u.PuestoActual) == "" {
+		return nil, newValidationError("puesto_actual", "el trabajador no tiene puesto registrado")
+	}
+
+	cesado := strings.TrimSpace(u.FechaCese) != ""
+	if tipo == CertificadoTrabajo && !cesado {
+		return nil, newValidationError("fecha_cese", "el certificado de trabajo requiere la fecha de cese; para un trabajador en actividad emita una constancia")
+	}
+	if tipo == ConstanciaTrabajo && cesado {
+		return nil, newValidationError("fecha_cese", "el trabajador tiene fecha de cese; emita un certificado de trabajo")
+	}
+
+	return &Certificado{
+		Tipo:          tipo,
+		Anio:          now.Year(),
+		Tenant:        tenant,
+		UsuarioID:     u.ID,
+		TipoDocumento: u.Documento().Tipo,
+		Dni:           u.Documento().Numero,
+		Trabajador:    strings.Join(strings.Fields(u.Nombres+" "+u.ApellidoPaterno+" "+u.ApellidoMaterno), " "),
+		Puesto:        u.PuestoActual,
+		FechaIngreso:  u.FechaIngreso,
+		FechaCese:     u.FechaCese,
+		CreatedAt:     now,
+	}, nil
+}
+
+// CertificadoFilter filtros del listado de certificados emitidos
+type CertificadoFilter struct {
+	Tenant string
+	Tipo   string
+	Dni    string
+	Limit  int
+}
+
+// Matches indica si el certificado cumple con los filtros
+func (f CertificadoFilter) Matches(c *Certificado) bool {
+	return c.Tenant == f.Tenant &&
+		(f.Tipo == "" || c.Tipo == f.Tipo) &&
+		(f.Dni == "" || c.Dni == f.Dni)
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/catalog"
+)
+
+// Monedas de las cuentas bancarias
+const (
+	MonedaSoles   = "PEN"
+	MonedaDolares = "USD"
+)
+
+// cciLength dígitos del CCI: entidad (3), oficina (3), cuenta (12) y dígitos de control (2)
+const cciLength = 20
+
+// CuentaBancaria cuenta del trabajador en la que se depositan la remuneración o la CTS
+type CuentaBancaria struct {
+	ID string `json:"id" firestore:"id"`
+	// Proposito sueldo o cts
+	Proposito string `json:"proposito" firestore:"proposito"`
+	// Banco código del catálogo de bancos
+	Banco        string `json:"banco" firestore:"banco"`
+	NumeroCuenta string `json:"numero_cuenta" firestore:"numero_cuenta"`
+	Cci          string `json:"cci,omitempty" firestore:"cci,omitempty"`
+	Moneda       string `json:"moneda" firestore:"moneda"`
+	Activa       bool   `json:"activa" firestore:"activa"`
+}
+
+// Normalize quita los espacios, deja el banco en mayúsculas, el CCI solo con dígitos y
+// usa soles si no se indicó la moneda
+func (c *CuentaBancaria) Normalize() {
+	c.ID = strings.TrimSpace(c.ID)
+	c.Proposito = strings.ToLower(strings.TrimSpace(c.Proposito))
+	c.Banco = strings.ToUpper(strings.TrimSpace(c.Banco))
+	c.NumeroCuenta = strings.TrimSpace(c.NumeroCuenta)
+	c.Cci = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(c.Cci))
+	c.Moneda = strings.ToUpper(strings.TrimSpace(c.Moneda))
+	if c.Moneda == "" {
+		c.Moneda = MonedaSoles
+	}
+}
+
+// Validate valida el propósito, el banco del catálogo, la moneda y el CCI: 20 dígitos, los
+// dígitos de control y que la entidad del CCI sea la del banco
+func (c *CuentaBancaria) Validate() error {
+	if !IsCuentaType(c.Proposito) {
+		return newValidationError("cuentas_bancarias.proposito", fmt.Sprintf("propósito %q no soportado (%v)", c.Proposito, CuentaTypes))
+	}
+	banco, ok := catalog.BancoByCodigo(c.Banco)
+	if !ok {
+		return newValidationError("cuentas_bancarias.banco", fmt.Sprintf("banco %q no existe en el catálogo", c.Banco))
+	}
+	if c.NumeroCuenta == "" {
+		return newValidationError("cuentas_bancarias.numero_cuenta", "el número de cuenta es requerido")
+	}
+	if c.Moneda != MonedaSoles && c.Moneda != MonedaDolares {
+		return newValidationError("cuentas_bancarias.moneda", fmt.Sprintf("moneda debe ser %s o %s", MonedaSoles, MonedaDolares))
+	}
+	if c.Cci == "" {
+		return nil
+	}
+	if err := ValidateCCI(c.Cci); err != nil {
+		return err
+	}
+	if c.Cci[:3] != banco.CodigoCCI {
+		return newValidationError("cuentas_bancarias.cci", fmt.Sprintf("el CCI %s no corresponde a %s (entidad %s)", c.Cci, banco.Nombre, banco.CodigoCCI))
+	}
+	return nil
+}
+
+// ValidateCCI valida el largo y los dígitos de control del CCI. El primer dígito de control
+// corresponde a la entidad y oficina y el segundo al número de cuenta.
+func ValidateCCI(cci string) error {
+	if len(cci) != cciLength || strings.Trim(cci, "0123456789") != "" {
+		return newValidationError("cuentas_bancarias.cci", fmt.Sprintf("el CCI debe tener %d dígitos", cciLength))
+	}
+	if cciCheckDigit(cci[:6]) != cci[18] || cciCheckDigit(cci[6:18]) != cci[19] {
+		return newValidationError("cuentas_bancarias.cci", fmt.Sprintf("los dígitos de control del CCI %s no son válidos", cci))
+	}
+	return nil
+}
+
+// cciCheckDigit dígito de control del CCI: los dígitos se multiplican alternadamente por 1 y
+// 2 desde la izquierda, los productos de dos cifras se reducen sumando sus cifras y el dígito
+// es lo que le falta a la suma para llegar a la siguiente decena
+func cciCheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		n := int(digits[i]-'0') * (1 + i%2)
+		if n > 9 {
+			n -= 9
+		}
+		sum += n
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// normalizeCuentas devuelve una copia de las cuentas normalizadas
+func normalizeCuentas(cuentas []CuentaBancaria) []CuentaBancaria {
+	if len(cuentas) == 0 {
+		return nil
+	}
+	normalized := make([]CuentaBancaria, len(cuentas))
+	for i, c := range cuentas {
+		c.Normalize()
+		normalized[i] = c
+	}
+	return normalized
+}
+
+// validateCuentas valida cada cuenta y que haya a lo más una cuenta activa por propósito
+func validateCuentas(cuentas []CuentaBancaria) error {
+	activas := make(map[string]bool, len(CuentaTypes))
+	for i := range cuentas {
+		if err := cuentas[i].Validate(); err != nil {
+			return err
+		}
+		if !cuentas[i].Activa {
+			continue
+		}
+		if activas[cuentas[i].Proposito] {
+			return newValidationError("cuentas_bancarias.activa", fmt.Sprintf("solo puede haber una cuenta %s activa", cuentas[i].Proposito))
+		}
+		activas[cuentas[i].Proposito] = true
+	}
+	return nil
+}
+
+// CuentaActiva devuelve la cuenta activa del propósito indicado; nil si no tiene
+func (u *Usuario) CuentaActiva(proposito string) *CuentaBancaria {
+	for i := range u.CuentasBancarias {
+		if u.CuentasBancarias[i].Activa && u.CuentasBancarias[i].Proposito == proposito {
+			return &u.CuentasBancarias[i]
+		}
+	}
+	return nil
+}
+
+// SetCuentaActiva deja la cuenta como la activa de su propósito y desactiva las demás. Si la
+// cuenta ya estaba registrada en el mismo banco solo se activa y se completa el CCI.
+func (u *Usuario) SetCuentaActiva(cuenta CuentaBancaria) {
+	registrada := false
+	for i := range u.CuentasBancarias {
+		c := &u.CuentasBancarias[i]
+		if c.Proposito != cuenta.Proposito {
+			continue
+		}
+		c.Activa = !registrada && c.Banco == cuenta.Banco && c.NumeroCuenta == cuenta.NumeroCuenta
+		if c.Activa {
+			registrada = true
+			if c.Cci == "" {
+				c.Cci = cuenta.Cci
+			}
+		}
+	}
+	if registrada {
+		return
+	}
+
+	cuenta.Activa = true
+	if cuenta.ID == "" {
+		cuenta.ID = fmt.Sprintf("%s-%d", cuenta.Proposito, len(u.CuentasBancarias)+1)
+	}
+	u.CuentasBancarias = append(u.CuentasBancarias, cuenta)
+}
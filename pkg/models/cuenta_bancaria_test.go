@@ -0,0 +1,72 @@
+package models
+
+import "testing"
+
+func TestCCICheckDigit(t *testing.T) {
+	// Con tramos de largo par el cálculo coincide con el dígito de Luhn; el ejemplo publicado
+	// 7992739871 tiene dígito 3
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{"7992739871", '3'},
+		{"002193", '1'},
+		{"001234567890", '3'},
+		{"003200", '3'},
+		{"011011", '4'},
+		{"000000", '0'},
+	}
+	for _, tt := range tests {
+		if got := cciCheckDigit(tt.digits); got != tt.want {
+			t.Errorf("cciCheckDigit(%s) = %c, want %c", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCCI(t *testing.T) {
+	valid := []struct {
+		banco string
+		cci   string
+	}{
+		{"BCP", "00219300123456789013"},
+		{"INTERBANK", "00320030001234567839"},
+		{"SCOTIABANK", "00917000001234567029"},
+		{"BBVA", "01101100010001234543"},
+		{"NACION", "01800000000438123406"},
+		{"BANBIF", "03810010000012345645"},
+	}
+
+	for _, v := range valid {
+		if err := ValidateCCI(v.cci); err != nil {
+			t.Errorf("%s %s: %v", v.banco, v.cci, err)
+		}
+		c := CuentaBancaria{Proposito: CuentaSueldo, Banco: v.banco, NumeroCuenta: "1", Cci: v.cci, Moneda: MonedaSoles}
+		if err := c.Validate(); err != nil {
+			t.Errorf("cuenta %s %s: %v", v.banco, v.cci, err)
+		}
+
+		// Cualquier cambio de un solo dígito tiene que invalidar el CCI
+		for i := range len(v.cci) {
+			for d := byte('0'); d <= '9'; d++ {
+				if d == v.cci[i] {
+					continue
+				}
+				corrupted := v.cci[:i] + string(d) + v.cci[i+1:]
+				if ValidateCCI(corrupted) == nil {
+					t.Errorf("%s: se aceptó %s (posición %d)", v.banco, corrupted, i+1)
+				}
+			}
+		}
+	}
+
+	for _, cci := range []string{"", "0021930012345678901", "002193001234567890133", "0021930012345678901A"} {
+		if ValidateCCI(cci) == nil {
+			t.Errorf("se aceptó el CCI mal formado %q", cci)
+		}
+	}
+
+	c := CuentaBancaria{Proposito: CuentaSueldo, Banco: "BBVA", NumeroCuenta: "1", Cci: "00219300123456789013", Moneda: MonedaSoles}
+	if c.Validate() == nil {
+		t.Error("se aceptó un CCI de BCP en una cuenta BBVA")
+	}
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/catalog"
+)
+
+// Direccion domicilio por partes, como lo piden el T-Registro y EsSalud
+type Direccion struct {
+	// TipoVia código del catálogo tipo_via
+	TipoVia      string `json:"tipo_via" firestore:"tipo_via"`
+	NombreVia    string `json:"nombre_via" firestore:"nombre_via"`
+	Numero       string `json:"numero,omitempty" firestore:"numero,omitempty"`
+	Interior     string `json:"interior,omitempty" firestore:"interior,omitempty"`
+	Urbanizacion string `json:"urbanizacion,omitempty" firestore:"urbanizacion,omitempty"`
+	Referencia   string `json:"referencia,omitempty" firestore:"referencia,omitempty"`
+	// Ubigeo código INEI de 6 dígitos del distrito
+	Ubigeo string `json:"ubigeo" firestore:"ubigeo"`
+	// Departamento, Provincia y Distrito nombres del ubigeo; son opcionales, pero si se envían
+	// deben corresponder al ubigeo y al guardar se reemplazan por los nombres oficiales
+	Departamento string `json:"departamento,omitempty" firestore:"departamento,omitempty"`
+	Provincia    string `json:"provincia,omitempty" firestore:"provincia,omitempty"`
+	Distrito     string `json:"distrito,omitempty" firestore:"distrito,omitempty"`
+}
+
+// normalize quita los espacios y deja el tipo de vía con su código si está en el catálogo
+func (d *Direccion) normalize() {
+	d.TipoVia = strings.TrimSpace(d.TipoVia)
+	if item, ok := catalog.TipoVia.Find(d.TipoVia); ok {
+		d.TipoVia = item.Codigo
+	}
+	d.NombreVia = strings.TrimSpace(d.NombreVia)
+	d.Numero = strings.TrimSpace(d.Numero)
+	d.Interior = strings.TrimSpace(d.Interior)
+	d.Urbanizacion = strings.TrimSpace(d.Urbanizacion)
+	d.Referencia = strings.TrimSpace(d.Referencia)
+	d.Ubigeo = strings.TrimSpace(d.Ubigeo)
+	d.Departamento = strings.TrimSpace(d.Departamento)
+	d.Provincia = strings.TrimSpace(d.Provincia)
+	d.Distrito = strings.TrimSpace(d.Distrito)
+	if dep, prov, dist, ok := catalog.UbigeoNombres(d.Ubigeo); ok && sameLugar([]string{d.Departamento, d.Provincia, d.Distrito}, dep, prov, dist) {
+		d.Departamento, d.Provincia, d.Distrito = dep, prov, dist
+	}
+}
+
+// validate valida el tipo de vía, el nombre y el ubigeo del distrito, que debe estar en la
+// tabla y corresponder a los nombres enviados
+func (d *Direccion) validate(field string) error {
+	if _, ok := catalog.TipoVia.Find(d.TipoVia); !ok {
+		return newValidationError(field+".tipo_via", fmt.Sprintf("%q no existe en el catálogo %s (%s)",
+			strings.TrimSpace(d.TipoVia), catalog.TipoVia.Nombre, strings.Join(catalog.TipoVia.Codigos(), ", ")))
+	}
+	if strings.TrimSpace(d.NombreVia) == "" {
+		return newValidationError(field+".nombre_via", "el nombre de la vía es requerido")
+	}
+	ubigeo := strings.TrimSpace(d.Ubigeo)
+	dep, prov, dist, ok := catalog.UbigeoNombres(ubigeo)
+	if !ok {
+		return newValidationError(field+".ubigeo", fmt.Sprintf("el ubigeo %q no corresponde a un distrito", ubigeo))
+	}
+	names := []string{strings.TrimSpace(d.Departamento), strings.TrimSpace(d.Provincia), strings.TrimSpace(d.Distrito)}
+	if !sameLugar(names, dep, prov, dist) {
+		return newValidationError(field+".ubigeo", fmt.Sprintf("el ubigeo %s corresponde a %s, %s, %s", ubigeo, dist, prov, dep))
+	}
+	return nil
+}
+
+// Format dirección en una línea para los PDF:
+// "Avenida Los Pinos 123 Int. 4B, Urb. Santa Rosa, Miraflores - Lima - Lima (Ref. frente al parque)"
+func (d *Direccion) Format() string {
+	if d == nil {
+		return ""
+	}
+
+	via := strings.Join(strings.Fields(catalog.TipoVia.NombreOf(d.TipoVia)+" "+d.NombreVia+" "+d.Numero), " ")
+	if d.Interior != "" {
+		via += " Int. " + d.Interior
+	}
+	parts := []string{via}
+	if d.Urbanizacion != "" {
+		parts = append(parts, "Urb. "+d.Urbanizacion)
+	}
+	if dep, prov, dist, ok := catalog.UbigeoNombres(d.Ubigeo); ok {
+		parts = append(parts, dist+" - "+prov+" - "+dep)
+	}
+
+	text := strings.Join(parts, ", ")
+	if d.Referencia != "" {
+		text += " (Ref. " + d.Referencia + ")"
+	}
+	return text
+}
+
+// normalizeDireccion devuelve una copia normalizada; nil si no hay dirección
+func normalizeDireccion(d *Direccion) *Direccion {
+	if d == nil {
+		return nil
+	}
+	normalized := *d
+	normalized.normalize()
+	if normalized == (Direccion{}) {
+		return nil
+	}
+	return &normalized
+}
+
+// validateDirecciones valida los domicilios por partes y que los familiares que comparten el
+// domicilio del trabajador tengan uno que copiar
+func (u *CreateUsuarioRequest) validateDirecciones() error {
+	type direccion struct {
+		field string
+		value *Direccion
+	}
+	direcciones := []direccion{{"domicilio", u.Domicilio}, {"contacto_domicilio", u.ContactoDomicilio}}
+	if u.DatosConyuge != nil && !u.DatosConyuge.MismoDomicilio {
+		direcciones = append(direcciones, direccion{"datos_conyuge.domicilio", u.DatosConyuge.Domicilio})
+	}
+	for _, h := range u.Hijos {
+		if !h.MismoDomicilio {
+			direcciones = append(direcciones, direccion{"hijos.domicilio", h.Domicilio})
+		}
+	}
+	for _, d := range direcciones {
+		if normalized := normalizeDireccion(d.value); normalized != nil {
+			if err := normalized.validate(d.field); err != nil {
+				return err
+			}
+		}
+	}
+
+	mismo := u.DatosConyuge != nil && u.DatosConyuge.MismoDomicilio
+	for _, h := range u.Hijos {
+		mismo = mismo || h.MismoDomicilio
+	}
+	if mismo && normalizeDireccion(u.Domicilio) == nil && strings.TrimSpace(u.DireccionDomicilio) == "" {
+		return newValidationError("mismo_domicilio", "el trabajador no registró su domicilio para copiarlo a sus familiares")
+	}
+	return nil
+}
+
+// resolveDirecciones normaliza los domicilios por partes, copia el del trabajador a los
+// familiares que lo comparten y completa los textos de dirección con Format para los PDF,
+// exportaciones y archivos bancarios que los usan
+func (u *Usuario) resolveDirecciones() {
+	u.Domicilio = normalizeDireccion(u.Domicilio)
+	if u.Domicilio != nil {
+		u.DireccionDomicilio = u.Domicilio.Format()
+	}
+	u.ContactoDomicilio = normalizeDireccion(u.ContactoDomicilio)
+	if u.ContactoDomicilio != nil {
+		u.ContactoDireccion = u.ContactoDomicilio.Format()
+	}
+
+	// resolve copia el domicilio del trabajador o normaliza el propio del familiar
+	resolve := func(mismo bool, domicilio **Direccion, direccion *string) {
+		if mismo {
+			*domicilio = normalizeDireccion(u.Domicilio)
+			*direccion = u.DireccionDomicilio
+			return
+		}
+		*domicilio = normalizeDireccion(*domicilio)
+		if *domicilio != nil {
+			*direccion = (*domicilio).Format()
+		}
+	}
+
+	if u.DatosConyuge != nil {
+		conyuge := *u.DatosConyuge
+		resolve(conyuge.MismoDomicilio, &conyuge.Domicilio, &conyuge.Direccion)
+		u.DatosConyuge = &conyuge
+	}
+	if len(u.Hijos) > 0 {
+		hijos := make([]Hijo, len(u.Hijos))
+		for i, h := range u.Hijos {
+			resolve(h.MismoDomicilio, &h.Domicilio, &h.Direccion)
+			hijos[i] = h
+		}
+		u.Hijos = hijos
+	}
+}
@@ -0,0 +1,82 @@
+package models
+
+import "testing"
+
+func TestDireccionFueraDeLima(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       Direccion
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Cusco",
+			d:    Direccion{TipoVia: "av.", NombreVia: "El Sol", Numero: "520", Ubigeo: "080101"},
+			want: "Avenida El Sol 520, Cusco - Cusco - Cusco",
+		},
+		{
+			name: "Iquitos",
+			d:    Direccion{TipoVia: "CAL", NombreVia: "Próspero", Numero: "1010", Urbanizacion: "Centro", Ubigeo: "160101"},
+			want: "Calle Próspero 1010, Urb. Centro, Iquitos - Maynas - Loreto",
+		},
+		{
+			name: "nombres que corresponden al ubigeo",
+			d:    Direccion{TipoVia: "AV", NombreVia: "El Sol", Ubigeo: "080101", Departamento: "CUSCO", Distrito: "cusco"},
+			want: "Avenida El Sol, Cusco - Cusco - Cusco",
+		},
+		{
+			name:    "nombres de otro distrito",
+			d:       Direccion{TipoVia: "AV", NombreVia: "El Sol", Ubigeo: "080101", Distrito: "Wanchaq"},
+			wantErr: true,
+		},
+		{
+			name:    "sin ubigeo",
+			d:       Direccion{TipoVia: "AV", NombreVia: "El Sol", Departamento: "Cusco", Provincia: "Cusco", Distrito: "Cusco"},
+			wantErr: true,
+		},
+		{
+			name:    "distrito que no está en la tabla",
+			d:       Direccion{TipoVia: "JR", NombreVia: "Libertad", Numero: "210", Ubigeo: "160201", Distrito: "Yurimaguas"},
+			wantErr: true,
+		},
+		{
+			name:    "ubigeo inexistente",
+			d:       Direccion{TipoVia: "JR", NombreVia: "Libertad", Ubigeo: "080199"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := normalizeDireccion(&tt.d)
+			err := d.validate("domicilio")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && d.Format() != tt.want {
+				t.Errorf("Format() = %q, want %q", d.Format(), tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveDireccionesMismoDomicilio(t *testing.T) {
+	u := &Usuario{
+		Domicilio:    &Direccion{TipoVia: "AV", NombreVia: "El Sol", Numero: "520", Ubigeo: "080101"},
+		DatosConyuge: &DatosConyuge{MismoDomicilio: true},
+		Hijos:        []Hijo{{MismoDomicilio: true}, {Domicilio: &Direccion{TipoVia: "JR", NombreVia: "Libertad", Ubigeo: "160101"}}},
+	}
+	u.resolveDirecciones()
+
+	if u.DireccionDomicilio != "Avenida El Sol 520, Cusco - Cusco - Cusco" {
+		t.Errorf("DireccionDomicilio = %q", u.DireccionDomicilio)
+	}
+	if u.DatosConyuge.Direccion != u.DireccionDomicilio || u.DatosConyuge.Domicilio == nil || u.DatosConyuge.Domicilio.Ubigeo != "080101" {
+		t.Errorf("cónyuge = %+v", u.DatosConyuge)
+	}
+	if u.Hijos[0].Direccion != u.DireccionDomicilio {
+		t.Errorf("hijo con mismo domicilio = %q", u.Hijos[0].Direccion)
+	}
+	if u.Hijos[1].Direccion != "Jirón Libertad, Iquitos - Maynas - Loreto" {
+		t.Errorf("hijo con domicilio propio = %q", u.Hijos[1].Direccion)
+	}
+}
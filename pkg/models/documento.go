@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/catalog"
+)
+
+// Tipos de documento de identidad, códigos del catálogo tipo_documento
+const (
+	DocumentoDNI       = "DNI"
+	DocumentoCE        = "CE"
+	DocumentoPTP       = "PTP"
+	DocumentoPasaporte = "PASAPORTE"
+)
+
+// documentoRule largo del número y si solo admite dígitos
+type documentoRule struct {
+	min, max int
+	digits   bool
+}
+
+// documentoRules reglas del número por tipo de documento
+var documentoRules = map[string]documentoRule{
+	DocumentoDNI:       {8, 8, true},
+	DocumentoCE:        {9, 9, true},
+	DocumentoPTP:       {9, 9, true},
+	DocumentoPasaporte: {6, 12, false},
+}
+
+// documentoLabels etiqueta corta del tipo de documento en los PDF
+var documentoLabels = map[string]string{
+	DocumentoDNI:       "DNI",
+	DocumentoCE:        "C.E.",
+	DocumentoPTP:       "PTP",
+	DocumentoPasaporte: "Pasaporte",
+}
+
+// Documento documento de identidad; el trabajador se identifica por el tipo y el número
+type Documento struct {
+	Tipo   string
+	Numero string
+}
+
+// Label etiqueta corta del tipo de documento: "DNI", "C.E.", "PTP" o "Pasaporte"
+func (d Documento) Label() string {
+	if label, ok := documentoLabels[d.Tipo]; ok {
+		return label
+	}
+	return d.Tipo
+}
+
+// String tipo y número para mostrar, por ejemplo "DNI 12345678" o "C.E. 001234567"
+func (d Documento) String() string {
+	return d.Label() + " " + d.Numero
+}
+
+// ParseDocumento normaliza y valida el tipo y el número de documento. dni es el campo de los
+// clientes anteriores a tipo_documento y numero_documento y equivale a un DNI; sin tipo
+// también se asume DNI.
+func ParseDocumento(tipo, numero, dni string) (Documento, error) {
+	numero = strings.NewReplacer(" ", "", "-", "", ".", "").Replace(strings.ToUpper(strings.TrimSpace(numero)))
+	dni = strings.TrimSpace(dni)
+	tipo = strings.TrimSpace(tipo)
+
+	if numero == "" {
+		if dni == "" {
+			return Documento{}, newValidationError("numero_documento", "el número de documento es requerido")
+		}
+		if tipo != "" && tipo != DocumentoDNI {
+			return Documento{}, newValidationError("numero_documento", "el campo dni solo se acepta para documentos DNI; use numero_documento")
+		}
+		numero = dni
+	}
+	if tipo == "" {
+		tipo = DocumentoDNI
+	}
+
+	item, ok := catalog.TipoDocumento.Find(tipo)
+	if !ok {
+		return Documento{}, newValidationError("tipo_documento", fmt.Sprintf("%q no existe en el catálogo %s (%s)",
+			tipo, catalog.TipoDocumento.Nombre, strings.Join(catalog.TipoDocumento.Codigos(), ", ")))
+	}
+	doc := Documento{Tipo: item.Codigo, Numero: numero}
+	if err := doc.validate(); err != nil {
+		return Documento{}, err
+	}
+	if dni != "" && doc.Tipo == DocumentoDNI && dni != doc.Numero {
+		return Documento{}, newValidationError("dni", "dni y numero_documento no coinciden")
+	}
+	return doc, nil
+}
+
+// validate valida el número según las reglas del tipo de documento
+func (d Documento) validate() error {
+	rule := documentoRules[d.Tipo]
+	chars := "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	kind := "letras y dígitos"
+	if rule.digits {
+		chars, kind = "0123456789", "dígitos"
+	}
+	if len(d.Numero) < rule.min || len(d.Numero) > rule.max || strings.Trim(d.Numero, chars) != "" {
+		length := fmt.Sprintf("%d", rule.min)
+		if rule.max != rule.min {
+			length = fmt.Sprintf("entre %d y %d", rule.min, rule.max)
+		}
+		return newValidationError("numero_documento", fmt.Sprintf("el número de %s debe tener %s %s", d.Label(), length, kind))
+	}
+	return nil
+}
+
+// Documento documento de identidad del trabajador. Los registros anteriores a la migración
+// solo tienen dni y se toman como DNI.
+func (u *Usuario) Documento() Documento {
+	if u.TipoDocumento == "" && u.NumeroDocumento == "" {
+		return Documento{Tipo: DocumentoDNI, Numero: u.Dni}
+	}
+	return Documento{Tipo: u.TipoDocumento, Numero: u.NumeroDocumento}
+}
+
+// setDocumento guarda el documento; dni solo tiene valor si el documento es DNI
+func (u *Usuario) setDocumento(doc Documento) {
+	u.TipoDocumento = doc.Tipo
+	u.NumeroDocumento = doc.Numero
+	u.Dni = ""
+	if doc.Tipo == DocumentoDNI {
+		u.Dni = doc.Numero
+	}
+}
+
+// NormalizeDocumento completa tipo_documento y numero_documento en los registros que solo
+// tienen dni. Los números que no cumplen las reglas del tipo se migran igual y se devuelve la
+// observación.
+func (u *Usuario) NormalizeDocumento() (changed bool, notes []string) {
+	before := *u
+	doc := u.Documento()
+	if doc.Numero == "" {
+		return false, []string{"el usuario no tiene número de documento"}
+	}
+	if err := doc.validate(); err != nil {
+		notes = append(notes, err.Error())
+	}
+	u.setDocumento(doc)
+	changed = u.TipoDocumento != before.TipoDocumento || u.NumeroDocumento != before.NumeroDocumento || u.Dni != before.Dni
+	return changed, notes
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// usuarioFields índice de los campos de Usuario por su nombre en Firestore
+var usuarioFields = sync.OnceValue(func() map[string]int {
+	t := reflect.TypeOf(Usuario{})
+	fields := make(map[string]int, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("firestore"), ",")
+		if name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	return fields
+})
+
+// ChangedFields nombres en Firestore de los campos que difieren entre before y after
+func ChangedFields(before, after *Usuario) []string {
+	b, a := reflect.ValueOf(before).Elem(), reflect.ValueOf(after).Elem()
+	var changed []string
+	for name, i := range usuarioFields() {
+		if !reflect.DeepEqual(b.Field(i).Interface(), a.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// FieldValue valor del campo de Usuario con el nombre de Firestore indicado
+func (u *Usuario) FieldValue(name string) (any, bool) {
+	i, ok := usuarioFields()[name]
+	if !ok {
+		return nil, false
+	}
+	return reflect.ValueOf(u).Elem().Field(i).Interface(), true
+}
+
+// CopyFields copia en u los campos de src con los nombres de Firestore indicados
+func (u *Usuario) CopyFields(src *Usuario, names []string) {
+	dst, from := reflect.ValueOf(u).Elem(), reflect.ValueOf(src).Elem()
+	for _, name := range names {
+		if i, ok := usuarioFields()[name]; ok {
+			dst.Field(i).Set(from.Field(i))
+		}
+	}
+}
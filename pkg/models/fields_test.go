@@ -0,0 +1,41 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChangedFields(t *testing.T) {
+	before := &Usuario{ID: "a", Dni: "12345678", Nombres: "Ana", Hijos: []Hijo{{Dni: "87654321"}}}
+	after := *before
+	after.Hijos = slices.Clone(before.Hijos)
+
+	if got := ChangedFields(before, &after); len(got) != 0 {
+		t.Fatalf("sin cambios: %v", got)
+	}
+
+	after.TipoDocumento = DocumentoDNI
+	after.NumeroDocumento = "12345678"
+	after.Hijos[0].Edad = 3
+	after.ID = "b"
+
+	got := ChangedFields(before, &after)
+	slices.Sort(got)
+	want := []string{"hijos", "numero_documento", "tipo_documento"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("ChangedFields = %v, want %v", got, want)
+	}
+
+	stored := Usuario{ID: "a", Nombres: "Ana", Email: "ana@example.com"}
+	stored.CopyFields(&after, got)
+	if stored.NumeroDocumento != "12345678" || stored.Email != "ana@example.com" || stored.ID != "a" {
+		t.Fatalf("CopyFields copió campos de más o de menos: %+v", stored)
+	}
+
+	if v, ok := after.FieldValue("numero_documento"); !ok || v != "12345678" {
+		t.Fatalf("FieldValue = %v, %v", v, ok)
+	}
+	if _, ok := after.FieldValue("id"); ok {
+		t.Fatal("FieldValue devolvió un campo que no se guarda")
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+// ImportPolicy indica qué hacer con los documentos que ya están registrados
+type ImportPolicy string
+
+const (
+	ImportPolicyOmitir     ImportPolicy = "omitir"
+	ImportPolicyActualizar ImportPolicy = "actualizar"
+)
+
+// Valid indica si la política es conocida
+func (p ImportPolicy) Valid() bool {
+	return p == ImportPolicyOmitir || p == ImportPolicyActualizar
+}
+
+// Estados de una fila de importación. En modo de validación (dry-run) se
+// informa la acción que se realizaría; al importar, el resultado obtenido.
+const (
+	ImportEstadoInvalido    = "invalido"
+	ImportEstadoCrear       = "crear"
+	ImportEstadoActualizar  = "actualizar"
+	ImportEstadoOmitir      = "omitir"
+	ImportEstadoCreado      = "creado"
+	ImportEstadoActualizado = "actualizado"
+	ImportEstadoOmitido     = "omitido"
+	ImportEstadoError       = "error"
+)
+
+// ImportRow fila leída del archivo de importación
+type ImportRow struct {
+	// Line número de línea en el archivo (el encabezado es la línea 1)
+	Line    int
+	Request CreateUsuarioRequest
+	// Columns nombres de las columnas del archivo; al actualizar un registro existente solo
+	// se escriben estos campos
+	Columns []string
+	// Errors errores de lectura de las columnas de la fila
+	Errors []string
+}
+
+// ImportOptions opciones de una importación masiva
+type ImportOptions struct {
+	DryRun bool
+	Policy ImportPolicy
+	Limits RequestLimits
+}
+
+// ImportRowResult resultado de una fila de la importación
+type ImportRowResult struct {
+	Fila            int      `json:"fila"`
+	TipoDocumento   string   `json:"tipo_documento"`
+	NumeroDocumento string   `json:"numero_documento"`
+	Estado          string   `json:"estado"`
+	Errores         []string `json:"errores,omitempty"`
+}
+
+// ImportReport reporte de una importación masiva
+type ImportReport struct {
+	DryRun       bool              `json:"dry_run"`
+	Politica     ImportPolicy      `json:"politica"`
+	Total        int               `json:"total"`
+	Validas      int               `json:"validas"`
+	Invalidas    int               `json:"invalidas"`
+	Creados      int               `json:"creados"`
+	Actualizados int               `json:"actualizados"`
+	Omitidos     int               `json:"omitidos"`
+	Fallidos     int               `json:"fallidos"`
+	Filas        []ImportRowResult `json:"filas"`
+}
+
+// importDerived campos que se calculan a partir de una columna importada y se escriben con ella
+var importDerived = map[string][]string{
+	"ubigeo_nacimiento":             {"lugar_nacimiento_departamento", "lugar_nacimiento_provincia", "lugar_nacimiento_distrito"},
+	"lugar_nacimiento_departamento": {"ubigeo_nacimiento", "lugar_nacimiento_provincia", "lugar_nacimiento_distrito"},
+	"lugar_nacimiento_provincia":    {"ubigeo_nacimiento", "lugar_nacimiento_departamento", "lugar_nacimiento_distrito"},
+	"lugar_nacimiento_distrito":     {"ubigeo_nacimiento", "lugar_nacimiento_departamento", "lugar_nacimiento_provincia"},
+	"domicilio":                     {"direccion_domicilio"},
+	"contacto_domicilio":            {"contacto_direccion"},
+}
+
+// importReadOnly columnas que no se importan sobre un registro existente: el documento
+// identifica la fila y la cuenta de otro banco es de solo lectura
+var importReadOnly = map[string]bool{
+	"tipo_documento":    true,
+	"numero_documento":  true,
+	"dni":               true,
+	"otro_banco_nombre": true,
+	"otro_banco_cuenta": true,
+	"otro_banco_cci":    true,
+}
+
+// MergeImport copia en u las columnas importadas de src junto con los campos que se derivan
+// de ellas y devuelve los nombres en Firestore de los campos que cambiaron. Las columnas que
+// no vienen en el archivo conservan el valor registrado.
+func (u *Usuario) MergeImport(src *Usuario, columns []string) []string {
+	var fields []string
+	for _, column := range columns {
+		if importReadOnly[column] {
+			continue
+		}
+		fields = append(fields, column)
+		fields = append(fields, importDerived[column]...)
+	}
+
+	before := *u
+	u.CopyFields(src, fields)
+	// los familiares que comparten el domicilio del trabajador toman el importado
+	u.resolveDirecciones()
+	return ChangedFields(&before, u)
+}
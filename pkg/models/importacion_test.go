@@ -0,0 +1,44 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeImportSoloEscribeColumnasImportadas(t *testing.T) {
+	current := Usuario{
+		ID:              "a",
+		TipoDocumento:   DocumentoDNI,
+		NumeroDocumento: "12345678",
+		Dni:             "12345678",
+		Nombres:         "Ana",
+		Email:           "ana@example.com",
+		Telefono:        "987654321",
+		OtroBancoCci:    "00219300123456789012",
+		Hijos:           []Hijo{{Dni: "87654321", MismoDomicilio: true}},
+	}
+	imported := &Usuario{
+		TipoDocumento:   DocumentoDNI,
+		NumeroDocumento: "12345678",
+		Dni:             "12345678",
+		Email:           "ana@empresa.pe",
+		Domicilio:       &Direccion{TipoVia: "01", NombreVia: "Próspero"},
+	}
+
+	merged := current
+	got := merged.MergeImport(imported, []string{"dni", "email", "domicilio", "otro_banco_cci"})
+	slices.Sort(got)
+	want := []string{"direccion_domicilio", "domicilio", "email", "hijos"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("MergeImport = %v, want %v", got, want)
+	}
+	if merged.Nombres != "Ana" || merged.Telefono != "987654321" || merged.OtroBancoCci != current.OtroBancoCci {
+		t.Fatalf("se perdieron columnas que no venían en el archivo: %+v", merged)
+	}
+	if merged.DireccionDomicilio == "" || merged.Hijos[0].Direccion != merged.DireccionDomicilio {
+		t.Fatalf("no se copió el domicilio importado al hijo que lo comparte: %+v", merged.Hijos[0])
+	}
+	if current.Hijos[0].Direccion != "" {
+		t.Fatal("se modificó el registro original")
+	}
+}
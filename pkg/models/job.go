@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Estados de un trabajo de reporte
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Tipos de reporte que se pueden generar de forma asíncrona
+const (
+	ReportXLSX   = "xlsx"
+	ReportCSV    = "csv"
+	ReportNDJSON = "ndjson"
+	ReportPadron = "padron"
+	ReportFichas = "fichas"
+)
+
+// ReportTypes tipos de reporte soportados
+var ReportTypes = []string{ReportXLSX, ReportCSV, ReportNDJSON, ReportPadron, ReportFichas}
+
+// Job trabajo de generación de un reporte
+type Job struct {
+	ID     string `json:"id" firestore:"-"`
+	Tipo   string `json:"tipo" firestore:"tipo"`
+	Estado string `json:"estado" firestore:"estado"`
+
+	// Parámetros del reporte
+	LugarTrabajo         string `json:"lugar_trabajo,omitempty" firestore:"lugar_trabajo,omitempty"`
+	PuestoActual         string `json:"puesto_actual,omitempty" firestore:"puesto_actual,omitempty"`
+	SituacionContractual string `json:"situacion_contractual,omitempty" firestore:"situacion_contractual,omitempty"`
+	RegimenPensionario   string `json:"regimen_pensionario,omitempty" firestore:"regimen_pensionario,omitempty"`
+	Limit                int    `json:"limit,omitempty" firestore:"limit,omitempty"`
+	Tenant               string `json:"tenant,omitempty" firestore:"tenant,omitempty"`
+
+	// Progreso porcentaje de avance (0 a 100)
+	Progreso   int `json:"progreso" firestore:"progreso"`
+	Procesados int `json:"procesados" firestore:"procesados"`
+	Total      int `json:"total" firestore:"total"`
+	Intentos   int `json:"intentos" firestore:"intentos"`
+
+	Error string `json:"error,omitempty" firestore:"error,omitempty"`
+
+	// Resultado almacenado en el blob store
+	BlobKey     string `json:"-" firestore:"blob_key,omitempty"`
+	ContentType string `json:"content_type,omitempty" firestore:"content_type,omitempty"`
+	FileName    string `json:"file_name,omitempty" firestore:"file_name,omitempty"`
+	Size        int    `json:"size,omitempty" firestore:"size,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at" firestore:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty" firestore:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" firestore:"finished_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" firestore:"expires_at,omitempty"`
+}
+
+// CreateJobRequest DTO para solicitar un reporte
+type CreateJobRequest struct {
+	Tipo                 string `json:"tipo"`
+	LugarTrabajo         string `json:"lugar_trabajo,omitempty"`
+	PuestoActual         string `json:"puesto_actual,omitempty"`
+	SituacionContractual string `json:"situacion_contractual,omitempty"`
+	RegimenPensionario   string `json:"regimen_pensionario,omitempty"`
+	Limit                int    `json:"limit,omitempty"`
+	Tenant               string `json:"tenant,omitempty"`
+}
+
+// Validate valida el tipo de reporte y el límite
+func (r *CreateJobRequest) Validate() error {
+	valid := false
+	for _, tipo := range ReportTypes {
+		if r.Tipo == tipo {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return newValidationError("tipo", fmt.Sprintf("tipo de reporte %q no soportado (%v)", r.Tipo, ReportTypes))
+	}
+	if r.Limit < 0 {
+		return newValidationError("limit", "limit no puede ser negativo")
+	}
+	return nil
+}
+
+// ToJob convierte el request a un trabajo en cola
+func (r *CreateJobRequest) ToJob() *Job {
+	return &Job{
+		Tipo:                 r.Tipo,
+		Estado:               JobQueued,
+		LugarTrabajo:         r.LugarTrabajo,
+		PuestoActual:         r.PuestoActual,
+		SituacionContractual: r.SituacionContractual,
+		RegimenPensionario:   r.RegimenPensionario,
+		Limit:                r.Limit,
+		Tenant:               r.Tenant,
+		CreatedAt:            time.Now(),
+	}
+}
+
+// Filter devuelve los filtros del listado del reporte
+func (j *Job) Filter() UsuarioFilter {
+	return UsuarioFilter{
+		LugarTrabajo:         j.LugarTrabajo,
+		PuestoActual:         j.PuestoActual,
+		SituacionContractual: j.SituacionContractual,
+		RegimenPensionario:   j.RegimenPensionario,
+		Limit:                j.Limit,
+	}
+}
+
+// Expired indica si el resultado ya no se puede descargar
+func (j *Job) Expired(now time.Time) bool {
+	return j.ExpiresAt != nil && now.After(*j.ExpiresAt)
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/luispfcanales/rainforestapp/pkg/catalog"
+)
+
+// lugarNacimiento lugar de nacimiento con los nombres oficiales y el ubigeo del distrito
+type lugarNacimiento struct {
+	ubigeo       string
+	departamento string
+	provincia    string
+	distrito     string
+}
+
+// resolveNacimiento valida el lugar de nacimiento con la tabla de ubigeo. Se puede enviar el
+// ubigeo, los tres nombres o ambos; si se envían ambos deben corresponder. Sin ningún dato
+// el lugar queda vacío, como en los nacidos en el extranjero.
+func resolveNacimiento(ubigeo, departamento, provincia, distrito string) (lugarNacimiento, error) {
+	ubigeo = strings.TrimSpace(ubigeo)
+	names := []string{strings.TrimSpace(departamento), strings.TrimSpace(provincia), strings.TrimSpace(distrito)}
+	hasNames := names[0] != "" || names[1] != "" || names[2] != ""
+
+	if ubigeo == "" && !hasNames {
+		return lugarNacimiento{}, nil
+	}
+
+	if ubigeo != "" {
+		dep, prov, dist, ok := catalog.UbigeoNombres(ubigeo)
+		if !ok {
+			return lugarNacimiento{}, newValidationError("ubigeo_nacimiento", fmt.Sprintf("el ubigeo %q no corresponde a un distrito", ubigeo))
+		}
+		if hasNames && !sameLugar(names, dep, prov, dist) {
+			return lugarNacimiento{}, newValidationError("ubigeo_nacimiento", fmt.Sprintf("el ubigeo %s corresponde a %s", ubigeo, strings.Join(nonEmpty(dist, prov, dep), ", ")))
+		}
+		return lugarNacimiento{ubigeo: ubigeo, departamento: dep, provincia: prov, distrito: dist}, nil
+	}
+
+	if names[0] == "" || names[1] == "" || names[2] == "" {
+		return lugarNacimiento{}, newValidationError("lugar_nacimiento", "el lugar de nacimiento requiere departamento, provincia y distrito")
+	}
+	found, ok := catalog.FindDistrito(names[0], names[1], names[2])
+	if !ok {
+		return lugarNacimiento{}, newValidationError("lugar_nacimiento", fmt.Sprintf("el distrito %s, %s, %s no existe en la tabla de ubigeo", names[2], names[1], names[0]))
+	}
+	dep, prov, dist, _ := catalog.UbigeoNombres(found.Codigo)
+	return lugarNacimiento{ubigeo: found.Codigo, departamento: dep, provincia: prov, distrito: dist}, nil
+}
+
+// sameLugar indica si los nombres enviados corresponden al departamento, la provincia y el
+// distrito, sin distinguir mayúsculas ni tildes
+func sameLugar(names []string, departamento, provincia, distrito string) bool {
+	for i, nombre := range []string{departamento, provincia, distrito} {
+		if names[i] != "" && !catalog.SameNombre(names[i], nombre) {
+			return false
+		}
+	}
+	return true
+}
+
+// nonEmpty valores no vacíos en el orden recibido
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// NormalizeUbigeo completa el ubigeo de nacimiento y deja los nombres oficiales. Si el lugar
+// no se encuentra en la tabla se deja como estaba y se devuelve la observación.
+func (u *Usuario) NormalizeUbigeo() (changed bool, notes []string) {
+	lugar, err := resolveNacimiento(u.UbigeoNacimiento, u.LugarNacimientoDepartamento, u.LugarNacimientoProvincia, u.LugarNacimientoDistrito)
+	if err != nil {
+		return false, []string{err.Error()}
+	}
+
+	changed = u.UbigeoNacimiento != lugar.ubigeo ||
+		u.LugarNacimientoDepartamento != lugar.departamento ||
+		u.LugarNacimientoProvincia != lugar.provincia ||
+		u.LugarNacimientoDistrito != lugar.distrito
+	u.UbigeoNacimiento = lugar.ubigeo
+	u.LugarNacimientoDepartamento = lugar.departamento
+	u.LugarNacimientoProvincia = lugar.provincia
+	u.LugarNacimientoDistrito = lugar.distrito
+	return changed, nil
+}
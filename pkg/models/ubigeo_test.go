@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestResolveNacimiento(t *testing.T) {
+	tests := []struct {
+		name                  string
+		ubigeo, dep, prov, di string
+		want                  lugarNacimiento
+		wantErr               bool
+	}{
+		{name: "vacío"},
+		{name: "ubigeo fuera de Lima", ubigeo: "080108",
+			want: lugarNacimiento{"080108", "Cusco", "Cusco", "Wanchaq"}},
+		{name: "nombres fuera de Lima", dep: "loreto", prov: "maynas", di: "IQUITOS",
+			want: lugarNacimiento{"160101", "Loreto", "Maynas", "Iquitos"}},
+		{name: "ubigeo que no está en la tabla", ubigeo: "010199", wantErr: true},
+		{name: "nombres que no están en la tabla", dep: "Loreto", prov: "Alto Amazonas", di: "Yurimaguas", wantErr: true},
+		{name: "ubigeo y nombres distintos", ubigeo: "080108", dep: "Loreto", prov: "Maynas", di: "Iquitos", wantErr: true},
+		{name: "distrito de otra provincia", dep: "Cusco", prov: "Cusco", di: "Yurimaguas", wantErr: true},
+		{name: "provincia inexistente", dep: "Loreto", prov: "Cusco", di: "Cusco", wantErr: true},
+		{name: "ubigeo inexistente", ubigeo: "080199", wantErr: true},
+		{name: "nombres incompletos", dep: "Cusco", prov: "Cusco", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveNacimiento(tt.ubigeo, tt.dep, tt.prov, tt.di)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"time"
@@ -15,6 +16,9 @@ type DatosConyuge struct {
 	Dni              string `json:"dni" firestore:"dni"`
 	Direccion        string `json:"direccion" firestore:"direccion"`
 	CopiaDni         string `json:"copia_dni,omitempty" firestore:"copia_dni,omitempty"`
+	// MismoDomicilio copia el domicilio del trabajador en Domicilio y Direccion al guardar
+	MismoDomicilio bool       `json:"mismo_domicilio,omitempty" firestore:"mismo_domicilio,omitempty"`
+	Domicilio      *Direccion `json:"domicilio,omitempty" firestore:"domicilio,omitempty"`
 }
 
 type Hijo struct {
@@ -25,6 +29,9 @@ type Hijo struct {
 	Dni              string `json:"dni" firestore:"dni"`
 	Edad             int    `json:"edad" firestore:"edad"`
 	CopiaDni         string `json:"copia_dni,omitempty" firestore:"copia_dni,omitempty"`
+	// MismoDomicilio copia el domicilio del trabajador en Domicilio y Direccion al guardar
+	MismoDomicilio bool       `json:"mismo_domicilio,omitempty" firestore:"mismo_domicilio,omitempty"`
+	Domicilio      *Direccion `json:"domicilio,omitempty" firestore:"domicilio,omitempty"`
 }
 
 type Padre struct {
@@ -84,12 +91,19 @@ type Usuario struct {
 	ID        string    `json:"id,omitempty" firestore:"-"`
 	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" firestore:"updated_at,omitempty"`
+	// Version hora de la última escritura del documento leído; las actualizaciones parciales
+	// la usan como precondición para no pisar cambios hechos después de leerlo
+	Version time.Time `json:"-" firestore:"-"`
 
 	// Datos Personales
-	ApellidoPaterno   string `json:"apellido_paterno" firestore:"apellido_paterno"`
-	ApellidoMaterno   string `json:"apellido_materno" firestore:"apellido_materno"`
-	Nombres           string `json:"nombres" firestore:"nombres"`
-	Sexo              string `json:"sexo" firestore:"sexo"`
+	ApellidoPaterno string `json:"apellido_paterno" firestore:"apellido_paterno"`
+	ApellidoMaterno string `json:"apellido_materno" firestore:"apellido_materno"`
+	Nombres         string `json:"nombres" firestore:"nombres"`
+	Sexo            string `json:"sexo" firestore:"sexo"`
+	// TipoDocumento y NumeroDocumento identifican al trabajador. Dni se conserva para los
+	// registros y consultas anteriores y solo tiene valor si el documento es DNI.
+	TipoDocumento     string `json:"tipo_documento" firestore:"tipo_documento"`
+	NumeroDocumento   string `json:"numero_documento" firestore:"numero_documento"`
 	Dni               string `json:"dni" firestore:"dni"`
 	LicenciaConducir  string `json:"licencia_conducir,omitempty" firestore:"licencia_conducir,omitempty"`
 	CategoriaLicencia string `json:"categoria_licencia,omitempty" firestore:"categoria_licencia,omitempty"`
@@ -99,12 +113,17 @@ type Usuario struct {
 	LugarNacimientoDistrito     string `json:"lugar_nacimiento_distrito" firestore:"lugar_nacimiento_distrito"`
 	LugarNacimientoProvincia    string `json:"lugar_nacimiento_provincia" firestore:"lugar_nacimiento_provincia"`
 	LugarNacimientoDepartamento string `json:"lugar_nacimiento_departamento" firestore:"lugar_nacimiento_departamento"`
+	// UbigeoNacimiento código INEI de 6 dígitos del distrito, el que pide el T-Registro
+	UbigeoNacimiento string `json:"ubigeo_nacimiento,omitempty" firestore:"ubigeo_nacimiento,omitempty"`
 
-	// Domicilio
-	DireccionDomicilio string `json:"direccion_domicilio" firestore:"direccion_domicilio"`
+	// Domicilio; DireccionDomicilio es el texto libre de los registros anteriores a Domicilio y,
+	// si hay Domicilio, su versión en una línea
+	DireccionDomicilio string     `json:"direccion_domicilio" firestore:"direccion_domicilio"`
+	Domicilio          *Direccion `json:"domicilio,omitempty" firestore:"domicilio,omitempty"`
 
 	// Datos Laborales
 	FechaIngreso         string `json:"fecha_ingreso" firestore:"fecha_ingreso"`
+	FechaCese            string `json:"fecha_cese,omitempty" firestore:"fecha_cese,omitempty"`
 	LugarTrabajo         string `json:"lugar_trabajo" firestore:"lugar_trabajo"`
 	PuestoActual         string `json:"puesto_actual" firestore:"puesto_actual"`
 	Telefono             string `json:"telefono" firestore:"telefono"`
@@ -123,7 +142,9 @@ type Usuario struct {
 	ContactoCelular      string `json:"contacto_celular" firestore:"contacto_celular"`
 	ContactoTelefonoFijo string `json:"contacto_telefono_fijo,omitempty" firestore:"contacto_telefono_fijo,omitempty"`
 	ContactoDireccion    string `json:"contacto_direccion" firestore:"contacto_direccion"`
-	GrupoSanguineo       string `json:"grupo_sanguineo" firestore:"grupo_sanguineo"`
+	// ContactoDomicilio dirección por partes del contacto; ContactoDireccion es su versión en una línea
+	ContactoDomicilio *Direccion `json:"contacto_domicilio,omitempty" firestore:"contacto_domicilio,omitempty"`
+	GrupoSanguineo    string     `json:"grupo_sanguineo" firestore:"grupo_sanguineo"`
 
 	EstadoCivil           string `json:"estado_civil" firestore:"estado_civil"`
 	ConstanciaEstadoCivil string `json:"constancia_estado_civil,omitempty" firestore:"constancia_estado_civil,omitempty"`
@@ -146,15 +167,21 @@ type Usuario struct {
 	Idiomas []Idioma `json:"idiomas,omitempty" firestore:"idiomas,omitempty"`
 
 	// Apertura de Cuenta Sueldo
-	AutorizaBcp       bool   `json:"autoriza_bcp" firestore:"autoriza_bcp"`
-	AutorizaOtroBanco bool   `json:"autoriza_otro_banco" firestore:"autoriza_otro_banco"`
-	OtroBancoNombre   string `json:"otro_banco_nombre,omitempty" firestore:"otro_banco_nombre,omitempty"`
-	OtroBancoCuenta   string `json:"otro_banco_cuenta,omitempty" firestore:"otro_banco_cuenta,omitempty"`
-	OtroBancoCci      string `json:"otro_banco_cci,omitempty" firestore:"otro_banco_cci,omitempty"`
+	AutorizaBcp       bool `json:"autoriza_bcp" firestore:"autoriza_bcp"`
+	AutorizaOtroBanco bool `json:"autoriza_otro_banco" firestore:"autoriza_otro_banco"`
+	// OtroBancoNombre, OtroBancoCuenta y OtroBancoCci texto libre de los registros anteriores
+	// a CuentasBancarias; son de solo lectura: las peticiones no los pueden escribir y solo se
+	// muestran en los registros que ya los tenían
+	OtroBancoNombre string `json:"otro_banco_nombre,omitempty" firestore:"otro_banco_nombre,omitempty"`
+	OtroBancoCuenta string `json:"otro_banco_cuenta,omitempty" firestore:"otro_banco_cuenta,omitempty"`
+	OtroBancoCci    string `json:"otro_banco_cci,omitempty" firestore:"otro_banco_cci,omitempty"`
 
 	// Apertura de Cuenta CTS
 	AutorizaCtsBcp bool `json:"autoriza_cts_bcp" firestore:"autoriza_cts_bcp"`
 
+	// Cuentas sueldo y CTS del trabajador; a lo más una activa por propósito
+	CuentasBancarias []CuentaBancaria `json:"cuentas_bancarias,omitempty" firestore:"cuentas_bancarias,omitempty"`
+
 	Foto string `json:"foto,omitempty" firestore:"foto,omitempty"`
 }
 
@@ -164,15 +191,20 @@ type CreateUsuarioRequest struct {
 	ApellidoMaterno             string               `json:"apellido_materno"`
 	Nombres                     string               `json:"nombres"`
 	Sexo                        string               `json:"sexo"`
-	Dni                         string               `json:"dni"`
+	TipoDocumento               string               `json:"tipo_documento,omitempty"`
+	NumeroDocumento             string               `json:"numero_documento,omitempty"`
+	Dni                         string               `json:"dni,omitempty"`
 	LicenciaConducir            string               `json:"licencia_conducir,omitempty"`
 	CategoriaLicencia           string               `json:"categoria_licencia,omitempty"`
 	FechaNacimiento             string               `json:"fecha_nacimiento"`
 	LugarNacimientoDistrito     string               `json:"lugar_nacimiento_distrito"`
 	LugarNacimientoProvincia    string               `json:"lugar_nacimiento_provincia"`
 	LugarNacimientoDepartamento string               `json:"lugar_nacimiento_departamento"`
+	UbigeoNacimiento            string               `json:"ubigeo_nacimiento,omitempty"`
 	DireccionDomicilio          string               `json:"direccion_domicilio"`
+	Domicilio                   *Direccion           `json:"domicilio,omitempty"`
 	FechaIngreso                string               `json:"fecha_ingreso"`
+	FechaCese                   string               `json:"fecha_cese,omitempty"`
 	LugarTrabajo                string               `json:"lugar_trabajo"`
 	PuestoActual                string               `json:"puesto_actual"`
 	Telefono                    string               `json:"telefono"`
@@ -187,6 +219,7 @@ type CreateUsuarioRequest struct {
 	ContactoCelular             string               `json:"contacto_celular"`
 	ContactoTelefonoFijo        string               `json:"contacto_telefono_fijo,omitempty"`
 	ContactoDireccion           string               `json:"contacto_direccion"`
+	ContactoDomicilio           *Direccion           `json:"contacto_domicilio,omitempty"`
 	GrupoSanguineo              string               `json:"grupo_sanguineo"`
 	EstadoCivil                 string               `json:"estado_civil"`
 	ConstanciaEstadoCivil       string               `json:"constancia_estado_civil,omitempty"`
@@ -200,46 +233,113 @@ type CreateUsuarioRequest struct {
 	Idiomas                     []Idioma             `json:"idiomas,omitempty"`
 	AutorizaBcp                 bool                 `json:"autoriza_bcp"`
 	AutorizaOtroBanco           bool                 `json:"autoriza_otro_banco"`
-	OtroBancoNombre             string               `json:"otro_banco_nombre,omitempty"`
-	OtroBancoCuenta             string               `json:"otro_banco_cuenta,omitempty"`
-	OtroBancoCci                string               `json:"otro_banco_cci,omitempty"`
-	AutorizaCtsBcp              bool                 `json:"autoriza_cts_bcp"`
-	Foto                        string               `json:"foto,omitempty"`
+	// OtroBancoNombre, OtroBancoCuenta y OtroBancoCci son de solo lectura: se aceptan en el
+	// JSON solo para rechazarlos con un mensaje claro, las cuentas van en CuentasBancarias
+	OtroBancoNombre  string           `json:"otro_banco_nombre,omitempty"`
+	OtroBancoCuenta  string           `json:"otro_banco_cuenta,omitempty"`
+	OtroBancoCci     string           `json:"otro_banco_cci,omitempty"`
+	AutorizaCtsBcp   bool             `json:"autoriza_cts_bcp"`
+	CuentasBancarias []CuentaBancaria `json:"cuentas_bancarias,omitempty"`
+	Foto             string           `json:"foto,omitempty"`
 }
 
 // Validate valida los datos del usuario
 func (u *CreateUsuarioRequest) Validate() error {
 	if strings.TrimSpace(u.Nombres) == "" {
-		return fmt.Errorf("los nombres son requeridos")
+		return newValidationError("nombres", "los nombres son requeridos")
 	}
 	if strings.TrimSpace(u.ApellidoPaterno) == "" {
-		return fmt.Errorf("el apellido paterno es requerido")
+		return newValidationError("apellido_paterno", "el apellido paterno es requerido")
 	}
-	if strings.TrimSpace(u.Dni) == "" {
-		return fmt.Errorf("el DNI es requerido")
+	if _, err := ParseDocumento(u.TipoDocumento, u.NumeroDocumento, u.Dni); err != nil {
+		return err
 	}
 	if len(u.Nombres) < 2 {
-		return fmt.Errorf("los nombres deben tener al menos 2 caracteres")
+		return newValidationError("nombres", "los nombres deben tener al menos 2 caracteres")
+	}
+	if _, err := resolveNacimiento(u.UbigeoNacimiento, u.LugarNacimientoDepartamento, u.LugarNacimientoProvincia, u.LugarNacimientoDistrito); err != nil {
+		return err
+	}
+	if err := u.validateDirecciones(); err != nil {
+		return err
+	}
+	if err := validateCatalogs(catalogValues(&u.AfpNombre, &u.RegimenSalud, &u.ContactoParentesco,
+		u.EducacionBasica, u.EducacionSuperior, u.Idiomas)); err != nil {
+		return err
+	}
+	if err := u.validateOtroBanco(); err != nil {
+		return err
+	}
+	return validateCuentas(normalizeCuentas(u.CuentasBancarias))
+}
+
+// validateOtroBanco rechaza los campos otro_banco_*, que solo se conservan en los registros
+// anteriores a cuentas_bancarias
+func (u *CreateUsuarioRequest) validateOtroBanco() error {
+	legacy := []struct{ field, value string }{
+		{"otro_banco_nombre", u.OtroBancoNombre},
+		{"otro_banco_cuenta", u.OtroBancoCuenta},
+		{"otro_banco_cci", u.OtroBancoCci},
+	}
+	for _, l := range legacy {
+		if strings.TrimSpace(l.value) != "" {
+			return newValidationError(l.field, l.field+" es de solo lectura; registre la cuenta en cuentas_bancarias")
+		}
 	}
 	return nil
 }
 
-// ToUsuario convierte el request a un modelo Usuario
+// RequestLimits límites de tamaño de los datos enviados en el registro
+type RequestLimits struct {
+	MaxHijos          int
+	MaxCapacitaciones int
+	MaxExperiencia    int
+	MaxFotoBytes      int
+}
+
+// ValidateLimits valida la cantidad de elementos de los arreglos y el tamaño de la foto
+func (u *CreateUsuarioRequest) ValidateLimits(l RequestLimits) error {
+	if len(u.Hijos) > l.MaxHijos {
+		return newValidationError("hijos", fmt.Sprintf("no se pueden registrar más de %d hijos", l.MaxHijos))
+	}
+	if len(u.Capacitaciones) > l.MaxCapacitaciones {
+		return newValidationError("capacitaciones", fmt.Sprintf("no se pueden registrar más de %d capacitaciones", l.MaxCapacitaciones))
+	}
+	if len(u.ExperienciaLaboral) > l.MaxExperiencia {
+		return newValidationError("experiencia_laboral", fmt.Sprintf("no se pueden registrar más de %d experiencias laborales", l.MaxExperiencia))
+	}
+
+	foto := u.Foto
+	if _, data, found := strings.Cut(foto, "base64,"); found {
+		foto = data
+	}
+	if base64.StdEncoding.DecodedLen(len(foto)) > l.MaxFotoBytes {
+		return newValidationError("foto", fmt.Sprintf("la foto no puede superar %d KB", l.MaxFotoBytes/1024))
+	}
+
+	return nil
+}
+
+// ToUsuario convierte el request a un modelo Usuario con su documento de identidad; los
+// campos codificados se guardan con el código de su catálogo, el lugar de nacimiento con los
+// nombres oficiales y su ubigeo y los domicilios por partes también como texto
 func (u *CreateUsuarioRequest) ToUsuario() *Usuario {
-	return &Usuario{
+	usuario := &Usuario{
 		ApellidoPaterno:             strings.TrimSpace(u.ApellidoPaterno),
 		ApellidoMaterno:             strings.TrimSpace(u.ApellidoMaterno),
 		Nombres:                     strings.TrimSpace(u.Nombres),
 		Sexo:                        strings.TrimSpace(u.Sexo),
-		Dni:                         strings.TrimSpace(u.Dni),
 		LicenciaConducir:            strings.TrimSpace(u.LicenciaConducir),
 		CategoriaLicencia:           strings.TrimSpace(u.CategoriaLicencia),
 		FechaNacimiento:             strings.TrimSpace(u.FechaNacimiento),
 		LugarNacimientoDistrito:     strings.TrimSpace(u.LugarNacimientoDistrito),
 		LugarNacimientoProvincia:    strings.TrimSpace(u.LugarNacimientoProvincia),
 		LugarNacimientoDepartamento: strings.TrimSpace(u.LugarNacimientoDepartamento),
+		UbigeoNacimiento:            strings.TrimSpace(u.UbigeoNacimiento),
 		DireccionDomicilio:          strings.TrimSpace(u.DireccionDomicilio),
+		Domicilio:                   u.Domicilio,
 		FechaIngreso:                strings.TrimSpace(u.FechaIngreso),
+		FechaCese:                   strings.TrimSpace(u.FechaCese),
 		LugarTrabajo:                strings.TrimSpace(u.LugarTrabajo),
 		PuestoActual:                strings.TrimSpace(u.PuestoActual),
 		Telefono:                    strings.TrimSpace(u.Telefono),
@@ -254,6 +354,7 @@ func (u *CreateUsuarioRequest) ToUsuario() *Usuario {
 		ContactoCelular:             strings.TrimSpace(u.ContactoCelular),
 		ContactoTelefonoFijo:        strings.TrimSpace(u.ContactoTelefonoFijo),
 		ContactoDireccion:           strings.TrimSpace(u.ContactoDireccion),
+		ContactoDomicilio:           u.ContactoDomicilio,
 		GrupoSanguineo:              strings.TrimSpace(u.GrupoSanguineo),
 		EstadoCivil:                 strings.TrimSpace(u.EstadoCivil),
 		ConstanciaEstadoCivil:       strings.TrimSpace(u.ConstanciaEstadoCivil),
@@ -267,11 +368,57 @@ func (u *CreateUsuarioRequest) ToUsuario() *Usuario {
 		Idiomas:                     u.Idiomas,
 		AutorizaBcp:                 u.AutorizaBcp,
 		AutorizaOtroBanco:           u.AutorizaOtroBanco,
-		OtroBancoNombre:             strings.TrimSpace(u.OtroBancoNombre),
-		OtroBancoCuenta:             strings.TrimSpace(u.OtroBancoCuenta),
-		OtroBancoCci:                strings.TrimSpace(u.OtroBancoCci),
 		AutorizaCtsBcp:              u.AutorizaCtsBcp,
+		CuentasBancarias:            normalizeCuentas(u.CuentasBancarias),
 		Foto:                        strings.TrimSpace(u.Foto),
 		CreatedAt:                   time.Now(),
 	}
+	if doc, err := ParseDocumento(u.TipoDocumento, u.NumeroDocumento, u.Dni); err == nil {
+		usuario.setDocumento(doc)
+	} else {
+		usuario.Dni = strings.TrimSpace(u.Dni)
+	}
+	usuario.NormalizeCatalogs()
+	usuario.NormalizeUbigeo()
+	usuario.resolveDirecciones()
+	return usuario
+}
+
+// UsuarioFilter filtros del listado de usuarios (coincidencia exacta)
+type UsuarioFilter struct {
+	LugarTrabajo         string
+	PuestoActual         string
+	SituacionContractual string
+	RegimenPensionario   string
+	// Limit cantidad máxima de resultados; 0 significa sin límite
+	Limit int
+}
+
+// Matches indica si el usuario cumple con los filtros
+func (f UsuarioFilter) Matches(u *Usuario) bool {
+	return (f.LugarTrabajo == "" || u.LugarTrabajo == f.LugarTrabajo) &&
+		(f.PuestoActual == "" || u.PuestoActual == f.PuestoActual) &&
+		(f.SituacionContractual == "" || u.SituacionContractual == f.SituacionContractual) &&
+		(f.RegimenPensionario == "" || u.RegimenPensionario == f.RegimenPensionario)
+}
+
+// Description describe los filtros aplicados en texto legible
+func (f UsuarioFilter) Description() string {
+	var parts []string
+	if f.LugarTrabajo != "" {
+		parts = append(parts, "Lugar de trabajo: "+f.LugarTrabajo)
+	}
+	if f.PuestoActual != "" {
+		parts = append(parts, "Puesto: "+f.PuestoActual)
+	}
+	if f.SituacionContractual != "" {
+		parts = append(parts, "Situación contractual: "+f.SituacionContractual)
+	}
+	if f.RegimenPensionario != "" {
+		parts = append(parts, "Régimen pensionario: "+f.RegimenPensionario)
+	}
+	if len(parts) == 0 {
+		return "Todos los trabajadores"
+	}
+	return strings.Join(parts, " | ")
 }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOtroBancoSoloLectura(t *testing.T) {
+	for _, field := range []string{"otro_banco_nombre", "otro_banco_cuenta", "otro_banco_cci"} {
+		req := &CreateUsuarioRequest{}
+		switch field {
+		case "otro_banco_nombre":
+			req.OtroBancoNombre = "Interbank"
+		case "otro_banco_cuenta":
+			req.OtroBancoCuenta = "2003001234567"
+		case "otro_banco_cci":
+			req.OtroBancoCci = "00320001300123456700"
+		}
+
+		var vErr *ValidationError
+		if err := req.validateOtroBanco(); !errors.As(err, &vErr) || vErr.Field != field {
+			t.Errorf("%s: err = %v, want error de validación del campo", field, err)
+		} else if !strings.HasPrefix(vErr.Message, field+" ") {
+			t.Errorf("%s: el mensaje %q no nombra el campo", field, vErr.Message)
+		}
+		if u := req.ToUsuario(); u.OtroBancoNombre != "" || u.OtroBancoCuenta != "" || u.OtroBancoCci != "" {
+			t.Errorf("%s: ToUsuario copió el campo de solo lectura", field)
+		}
+	}
+
+	if err := (&CreateUsuarioRequest{}).validateOtroBanco(); err != nil {
+		t.Fatalf("sin campos de otro banco: %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+// ValidationError error de validación asociado a un campo del request
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// newValidationError crea un error de validación para el campo indicado
+func newValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Message: message}
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Estado del registro del trabajador respecto del documento verificado
+const (
+	// RegistroVigente los datos actuales coinciden con los del documento
+	RegistroVigente = "vigente"
+	// RegistroModificado los datos cambiaron después de emitir el documento
+	RegistroModificado = "modificado"
+	// RegistroNoDisponible el registro ya no existe o no se pudo consultar
+	RegistroNoDisponible = "no_disponible"
+)
+
+// Verificacion resultado de verificar un documento impreso. No incluye datos personales.
+type Verificacion struct {
+	Valido    bool      `json:"valido"`
+	Documento string    `json:"documento"`
+	EmitidoEn time.Time `json:"emitido_en"`
+	// Hash SHA-256 del contenido del documento al emitirlo
+	Hash string `json:"hash"`
+	// Codigo código corto impreso junto al QR
+	Codigo   string `json:"codigo"`
+	Registro string `json:"registro"`
+}
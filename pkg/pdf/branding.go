@@ -0,0 +1,96 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontfamily"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core/entity"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+
+	"github.com/luispfcanales/rainforestapp/pkg/ficha"
+)
+
+// Branding datos de la empresa y plantilla de los documentos
+type Branding struct {
+	CompanyName  string
+	RUC          string
+	Logo         []byte
+	PrimaryColor *props.Color
+	TextColor    *props.Color
+	// FontFamily familia de fuente por defecto (arial, helvetica, courier) o la
+	// familia con la que se registran CustomFonts
+	FontFamily string
+	// CustomFonts fuentes TTF por estilo, registradas con FontFamily
+	CustomFonts map[fontstyle.Type][]byte
+	FooterText  string
+	// Sections secciones de la ficha a incluir (ver ficha.AllSections); vacío incluye todas
+	Sections []string
+}
+
+// FontStyles estilos de fuente que se pueden configurar en font_files y su equivalente en el PDF
+var FontStyles = map[string]fontstyle.Type{
+	"normal":      fontstyle.Normal,
+	"bold":        fontstyle.Bold,
+	"italic":      fontstyle.Italic,
+	"bold_italic": fontstyle.BoldItalic,
+}
+
+// DefaultBranding devuelve el branding por defecto con el logo embebido
+func DefaultBranding() Branding {
+	return Branding{
+		CompanyName:  "RAINFOREST ENTERPRISE",
+		Logo:         logoBytes,
+		PrimaryColor: getHeaderColor(),
+		TextColor:    getTextColor(),
+		FontFamily:   fontfamily.Arial,
+	}
+}
+
+// Validate verifica que las secciones y las fuentes sean válidas
+func (b Branding) Validate() error {
+	for _, section := range b.Sections {
+		if !ficha.IsSection(section) {
+			return fmt.Errorf("sección %q no existe (%s)", section, strings.Join(ficha.AllSections, ", "))
+		}
+	}
+	if len(b.CustomFonts) > 0 {
+		if _, ok := b.CustomFonts[fontstyle.Normal]; !ok {
+			return fmt.Errorf("la fuente %q debe incluir el estilo normal", b.FontFamily)
+		}
+	}
+	return nil
+}
+
+// primaryColor color de títulos y líneas
+func (g *PDFGenerator) primaryColor() *props.Color {
+	if g.branding.PrimaryColor != nil {
+		return g.branding.PrimaryColor
+	}
+	return getHeaderColor()
+}
+
+// textColor color de textos secundarios
+func (g *PDFGenerator) textColor() *props.Color {
+	if g.branding.TextColor != nil {
+		return g.branding.TextColor
+	}
+	return getTextColor()
+}
+
+// withFonts aplica la fuente del branding al builder de configuración
+func (g *PDFGenerator) withFonts(builder config.Builder) config.Builder {
+	if len(g.branding.CustomFonts) > 0 {
+		fonts := make([]*entity.CustomFont, 0, len(g.branding.CustomFonts))
+		for style, data := range g.branding.CustomFonts {
+			fonts = append(fonts, &entity.CustomFont{Family: g.branding.FontFamily, Style: style, Bytes: data})
+		}
+		builder = builder.WithCustomFonts(fonts)
+	}
+	if g.branding.FontFamily != "" {
+		builder = builder.WithDefaultFont(&props.Font{Family: g.branding.FontFamily})
+	}
+	return builder
+}
@@ -0,0 +1,245 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/line"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
+	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// bancoNames razón social de los bancos con los que se hace la apertura
+var bancoNames = map[string]string{
+	models.BancoBCP: "BANCO DE CRÉDITO DEL PERÚ",
+}
+
+// cartaSubjects asunto de la carta por tipo de cuenta
+var cartaSubjects = map[string]string{
+	models.CuentaSueldo: "Autorización de apertura de cuenta sueldo",
+	models.CuentaCTS:    "Autorización de apertura de cuenta CTS",
+}
+
+// GenerateCartaBancoPDF genera la carta con la que el trabajador autoriza la apertura de su
+// cuenta sueldo o CTS, dirigida al banco de la apertura. Si la cuenta sueldo ya está
+// registrada en otro banco la carta autoriza el depósito en esa cuenta.
+func (g *PDFGenerator) GenerateCartaBancoPDF(ctx context.Context, usuario *models.Usuario, apertura *models.AperturaCuenta, sec Security) ([]byte, error) {
+	start := time.Now()
+
+	subject, ok := cartaSubjects[apertura.Tipo]
+	if !ok {
+		return nil, fmt.Errorf("tipo de cuenta %q no soportado", apertura.Tipo)
+	}
+	if apertura.Banco != models.BancoBCP && apertura.NumeroCuenta != "" {
+		subject = "Autorización de depósito de remuneraciones"
+	}
+
+	cfg := g.withFonts(config.NewBuilder().
+		WithPageSize(pagesize.A4).
+		WithOrientation(orientation.Vertical).
+		WithLeftMargin(25).
+		WithTopMargin(20).
+		WithRightMargin(25).
+		WithBottomMargin(20)).
+		Build()
+
+	m := maroto.New(cfg)
+
+	err := m.RegisterFooter(
+		row.New(6).Add(col.New(12).Add(line.New(props.Line{Color: g.primaryColor(), Thickness: 0.5}))),
+		row.New(4).Add(col.New(12).Add(text.New(g.branding.FooterText, props.Text{Size: 7, Color: g.textColor()}))),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error registrando pie de página: %w", err)
+	}
+
+	issued := fechaLarga(start)
+	if g.letters.Ciudad != "" {
+		issued = g.letters.Ciudad + ", " + issued
+	}
+
+	banco := bancoNames[apertura.Banco]
+	if banco == "" {
+		banco = strings.ToUpper(apertura.Banco)
+	}
+
+	m.AddRows(g.letterHeader(), row.New(10))
+	m.AddRows(
+		row.New(6).Add(col.New(12).Add(text.New(issued, props.Text{Size: 11, Align: align.Right}))),
+		row.New(10),
+		row.New(5).Add(col.New(12).Add(text.New("Señores", props.Text{Size: 11}))),
+		row.New(5).Add(col.New(12).Add(text.New(banco, props.Text{Size: 11, Style: fontstyle.Bold}))),
+		row.New(5).Add(col.New(12).Add(text.New("Presente.-", props.Text{Size: 11}))),
+		row.New(8),
+		row.New(6).Add(
+			col.New(2).Add(text.New("Asunto:", props.Text{Size: 11, Style: fontstyle.Bold})),
+			col.New(10).Add(text.New(subject, props.Text{Size: 11, Style: fontstyle.Bold})),
+		),
+		row.New(8),
+	)
+
+	for _, paragraph := range g.cartaParagraphs(usuario, apertura) {
+		m.AddRows(
+			row.New().Add(col.New(12).Add(text.New(paragraph, props.Text{
+				Size: 11, Align: align.Justify, VerticalPadding: 2,
+			}))),
+			row.New(4),
+		)
+	}
+
+	m.AddRows(row.New(2), row.New(6).Add(col.New(12).Add(text.New("Datos del titular", props.Text{
+		Size: 11, Style: fontstyle.Bold, Color: g.primaryColor(),
+	}))))
+	for _, field := range cartaTitular(usuario) {
+		m.AddRows(row.New(5.5).Add(
+			col.New(4).Add(text.New(field[0], props.Text{Size: 10, Style: fontstyle.Bold})),
+			col.New(8).Add(text.New(orDash(field[1]), props.Text{Size: 10})),
+		))
+	}
+
+	m.AddRows(
+		row.New(8),
+		row.New(6).Add(col.New(12).Add(text.New("Atentamente,", props.Text{Size: 11}))),
+		row.New(30),
+	)
+	m.AddRows(g.cartaSignatureRows(apertura)...)
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("error generando PDF: %w", err)
+	}
+
+	sec = g.protection(sec)
+	if sec.PasswordDNI {
+		sec.Password = usuario.Documento().Numero
+	}
+	pdfBytes, err := g.finish(ctx, document.GetBytes(), DocumentInfo{Tipo: DocumentCartaBanco, UsuarioID: usuario.ID}, sec, start)
+	if err != nil {
+		return nil, err
+	}
+	metrics.PDFGenerated(DocumentCartaBanco, time.Since(start), len(pdfBytes))
+
+	return pdfBytes, nil
+}
+
+// cartaParagraphs cuerpo de la carta según el tipo de cuenta y el banco
+func (g *PDFGenerator) cartaParagraphs(u *models.Usuario, a *models.AperturaCuenta) []string {
+	empresa := g.branding.CompanyName
+	if g.branding.RUC != "" {
+		empresa += ", con RUC N° " + g.branding.RUC
+	}
+	intro := fmt.Sprintf("Yo, %s, identificado(a) con %s N° %s, trabajador(a) de %s", a.Trabajador, a.Documento().Label(), a.Dni, empresa)
+	if puesto := strings.TrimSpace(u.PuestoActual); puesto != "" {
+		intro += " en el cargo de " + puesto
+	}
+
+	switch {
+	case a.Tipo == models.CuentaCTS:
+		return []string{
+			intro + ", autorizo a mi empleador a solicitar a su representada la apertura a mi nombre de una cuenta de " +
+				"Compensación por Tiempo de Servicios (CTS) en moneda nacional, en la que se efectuarán los depósitos " +
+				"de mi CTS conforme a la normativa vigente.",
+			"Asimismo, autorizo a su representada a entregar a mi empleador la información de la cuenta abierta " +
+				"para el registro de los depósitos.",
+		}
+	case a.Banco != models.BancoBCP && a.NumeroCuenta != "":
+		cuenta := "N° " + a.NumeroCuenta
+		if a.Cci != "" {
+			cuenta += " (CCI " + a.Cci + ")"
+		}
+		return []string{
+			intro + fmt.Sprintf(", autorizo a mi empleador a depositar mis remuneraciones y demás beneficios laborales "+
+				"en la cuenta %s de la que soy titular en su representada.", cuenta),
+		}
+	}
+	return []string{
+		intro + ", autorizo a mi empleador a solicitar a su representada la apertura a mi nombre de una cuenta de " +
+			"ahorros en moneda nacional, en la que se depositarán mis remuneraciones y demás beneficios laborales.",
+		"Asimismo, autorizo a su representada a entregar a mi empleador la información de la cuenta abierta " +
+			"para el registro de los pagos.",
+	}
+}
+
+// cartaTitular datos del trabajador que el banco requiere para la apertura
+func cartaTitular(u *models.Usuario) [][2]string {
+	return [][2]string{
+		{"Apellidos y nombres", strings.Join(strings.Fields(u.ApellidoPaterno+" "+u.ApellidoMaterno), " ") + ", " + strings.TrimSpace(u.Nombres)},
+		{u.Documento().Label(), u.Documento().Numero},
+		{"Fecha de nacimiento", fechaNumerica(u.FechaNacimiento)},
+		{"Sexo", u.Sexo},
+		{"Estado civil", u.EstadoCivil},
+		{"Domicilio", u.DireccionDomicilio},
+		{"Teléfono", u.Telefono},
+		{"Correo electrónico", u.Email},
+		{"Fecha de ingreso", fechaNumerica(u.FechaIngreso)},
+	}
+}
+
+// cartaSignatureRows firma del trabajador a la izquierda y del empleador a la derecha
+func (g *PDFGenerator) cartaSignatureRows(a *models.AperturaCuenta) []core.Row {
+	signers := func(left, right string, style fontstyle.Type) core.Row {
+		return row.New(5).Add(
+			col.New(5).Add(text.New(left, props.Text{Size: 9, Style: style, Align: align.Center})),
+			col.New(2),
+			col.New(5).Add(text.New(right, props.Text{Size: 9, Style: style, Align: align.Center})),
+		)
+	}
+
+	var employer []string
+	for _, value := range []string{g.letters.Firmante, g.letters.CargoFirmante, g.branding.CompanyName} {
+		if value != "" {
+			employer = append(employer, value)
+		}
+	}
+	employer = append(employer, "EL EMPLEADOR")
+	worker := []string{a.Trabajador, a.Documento().Label() + " N° " + a.Dni, "EL TRABAJADOR"}
+
+	rows := []core.Row{
+		row.New(3).Add(
+			col.New(5).Add(line.New(props.Line{Color: g.textColor(), Thickness: 0.5})),
+			col.New(2),
+			col.New(5).Add(line.New(props.Line{Color: g.textColor(), Thickness: 0.5})),
+		),
+	}
+	for i := range max(len(worker), len(employer)) {
+		var left, right string
+		if i < len(worker) {
+			left = worker[i]
+		}
+		if i < len(employer) {
+			right = employer[i]
+		}
+		style := fontstyle.Normal
+		if i == 0 {
+			style = fontstyle.Bold
+		}
+		rows = append(rows, signers(left, right, style))
+	}
+	return rows
+}
+
+// fechaNumerica fecha de texto del usuario en formato dd/mm/aaaa; si no se reconoce se
+// devuelve tal cual
+func fechaNumerica(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("02/01/2006")
+		}
+	}
+	return value
+}
@@ -0,0 +1,305 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
+	"github.com/johnfercher/maroto/v2/pkg/components/line"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
+	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// Plantillas por defecto de los certificados y constancias de trabajo
+const (
+	DefaultCertificadoTemplate = "Se certifica que {tratamiento} {trabajador}, con {tipo_documento} N° {numero_documento}, laboró en {empresa} " +
+		"desde el {fecha_ingreso} hasta el {fecha_cese}, desempeñando el cargo de {puesto}.\n\n" +
+		"Se expide el presente certificado a solicitud de la parte interesada, para los fines que estime conveniente."
+	DefaultConstanciaTemplate = "Se deja constancia que {tratamiento} {trabajador}, con {tipo_documento} N° {numero_documento}, labora en {empresa} " +
+		"desde el {fecha_ingreso} hasta la fecha, desempeñando el cargo de {puesto}.\n\n" +
+		"Se expide la presente constancia a solicitud de la parte interesada, para los fines que estime conveniente."
+)
+
+// LetterPlaceholders marcadores que se pueden usar en las plantillas
+var LetterPlaceholders = []string{
+	"trabajador", "nombres", "apellidos", "tratamiento", "tipo_documento", "numero_documento", "dni",
+	"puesto", "lugar_trabajo", "fecha_ingreso", "fecha_cese", "empresa", "ruc", "codigo",
+}
+
+// letterTitles título de cada tipo de documento
+var letterTitles = map[string]string{
+	models.CertificadoTrabajo: "CERTIFICADO DE TRABAJO",
+	models.ConstanciaTrabajo:  "CONSTANCIA DE TRABAJO",
+}
+
+// placeholderPattern marcador de una plantilla: {nombre}
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// meses nombres de los meses para las fechas en letras
+var meses = [...]string{
+	"enero", "febrero", "marzo", "abril", "mayo", "junio",
+	"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+}
+
+// dateLayouts formatos de fecha aceptados en los campos de texto del usuario
+var dateLayouts = []string{"2006-01-02", "02/01/2006", time.RFC3339}
+
+// Letters plantillas y firmante de los certificados y constancias de trabajo
+type Letters struct {
+	// Certificado y Constancia texto con marcadores ({trabajador}, {numero_documento}, ...); los párrafos
+	// se separan con una línea en blanco. Vacío usa la plantilla por defecto.
+	Certificado string
+	Constancia  string
+	// Ciudad lugar que antecede a la fecha de emisión
+	Ciudad        string
+	Firmante      string
+	CargoFirmante string
+}
+
+// Validate verifica que las plantillas solo usen marcadores conocidos
+func (l Letters) Validate() error {
+	for name, tmpl := range map[string]string{"certificado": l.Certificado, "constancia": l.Constancia} {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(tmpl, -1) {
+			if !isPlaceholder(match[1]) {
+				return fmt.Errorf("plantilla de %s: marcador %q no existe (%s)", name, match[0], strings.Join(LetterPlaceholders, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// template devuelve la plantilla del tipo de documento
+func (l Letters) template(tipo string) string {
+	if tipo == models.CertificadoTrabajo {
+		if l.Certificado != "" {
+			return l.Certificado
+		}
+		return DefaultCertificadoTemplate
+	}
+	if l.Constancia != "" {
+		return l.Constancia
+	}
+	return DefaultConstanciaTemplate
+}
+
+// WithLetters define las plantillas y el firmante de los certificados y constancias
+func (g *PDFGenerator) WithLetters(l Letters) *PDFGenerator {
+	g.letters = l
+	return g
+}
+
+// GenerateCertificadoPDF genera el certificado o la constancia de trabajo registrado en cert
+// con la plantilla del generador. sec agrega protección a la configurada en el generador.
+func (g *PDFGenerator) GenerateCertificadoPDF(ctx context.Context, usuario *models.Usuario, cert *models.Certificado, sec Security) ([]byte, error) {
+	start := time.Now()
+
+	title, ok := letterTitles[cert.Tipo]
+	if !ok {
+		return nil, fmt.Errorf("tipo de certificado %q no soportado", cert.Tipo)
+	}
+
+	cfg := g.withFonts(config.NewBuilder().
+		WithPageSize(pagesize.A4).
+		WithOrientation(orientation.Vertical).
+		WithLeftMargin(25).
+		WithTopMargin(20).
+		WithRightMargin(25).
+		WithBottomMargin(20)).
+		Build()
+
+	m := maroto.New(cfg)
+
+	err := m.RegisterFooter(
+		row.New(6).Add(col.New(12).Add(line.New(props.Line{Color: g.primaryColor(), Thickness: 0.5}))),
+		row.New(4).Add(
+			col.New(8).Add(text.New(g.branding.FooterText, props.Text{Size: 7, Color: g.textColor()})),
+			col.New(4).Add(text.New(cert.Codigo, props.Text{Size: 7, Align: align.Right, Color: g.textColor()})),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error registrando pie de página: %w", err)
+	}
+
+	m.AddRows(g.letterHeader(), row.New(20))
+	m.AddRows(
+		row.New(10).Add(col.New(12).Add(text.New(title, props.Text{
+			Size: 16, Style: fontstyle.Bold, Align: align.Center, Color: g.primaryColor(),
+		}))),
+		row.New(6).Add(col.New(12).Add(text.New("N° "+cert.Codigo, props.Text{
+			Size: 10, Align: align.Center, Color: g.textColor(),
+		}))),
+		row.New(15),
+	)
+
+	for _, paragraph := range letterParagraphs(g.fillLetter(g.letters.template(cert.Tipo), usuario, cert)) {
+		m.AddRows(
+			row.New().Add(col.New(12).Add(text.New(paragraph, props.Text{
+				Size: 11, Align: align.Justify, VerticalPadding: 2,
+			}))),
+			row.New(6),
+		)
+	}
+
+	issued := fechaLarga(cert.CreatedAt)
+	if g.letters.Ciudad != "" {
+		issued = g.letters.Ciudad + ", " + issued
+	}
+	m.AddRows(
+		row.New(10),
+		row.New(6).Add(col.New(12).Add(text.New(issued, props.Text{Size: 11, Align: align.Right}))),
+		row.New(35),
+	)
+	m.AddRows(g.letterSignatureRows()...)
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("error generando PDF: %w", err)
+	}
+
+	sec = g.protection(sec)
+	if sec.PasswordDNI {
+		sec.Password = usuario.Documento().Numero
+	}
+	pdfBytes, err := g.finish(ctx, document.GetBytes(), DocumentInfo{Tipo: cert.Tipo, UsuarioID: usuario.ID}, sec, start)
+	if err != nil {
+		return nil, err
+	}
+	metrics.PDFGenerated(cert.Tipo, time.Since(start), len(pdfBytes))
+
+	return pdfBytes, nil
+}
+
+// letterHeader logo, razón social y RUC de la empresa
+func (g *PDFGenerator) letterHeader() core.Row {
+	company := col.New(9).Add(text.New(g.branding.CompanyName, props.Text{
+		Size: 14, Style: fontstyle.Bold, Align: align.Right, Color: g.primaryColor(), Top: 6,
+	}))
+	if g.branding.RUC != "" {
+		company.Add(text.New("RUC: "+g.branding.RUC, props.Text{
+			Size: 9, Align: align.Right, Color: g.textColor(), Top: 13,
+		}))
+	}
+
+	logo := col.New(3)
+	if len(g.branding.Logo) > 0 {
+		logo.Add(image.NewFromBytes(g.branding.Logo, imageExtension(g.branding.Logo), props.Rect{Center: true, Percent: 90}))
+	}
+
+	return row.New(25).Add(logo, company)
+}
+
+// letterSignatureRows línea de firma con el nombre y cargo del firmante
+func (g *PDFGenerator) letterSignatureRows() []core.Row {
+	signer := func(value string, style fontstyle.Type) core.Row {
+		return row.New(5).Add(
+			col.New(3),
+			col.New(6).Add(text.New(value, props.Text{Size: 10, Style: style, Align: align.Center})),
+			col.New(3),
+		)
+	}
+
+	rows := []core.Row{
+		row.New(3).Add(
+			col.New(3),
+			col.New(6).Add(line.New(props.Line{Color: g.textColor(), Thickness: 0.5})),
+			col.New(3),
+		),
+	}
+	if g.letters.Firmante != "" {
+		rows = append(rows, signer(g.letters.Firmante, fontstyle.Bold))
+	}
+	if g.letters.CargoFirmante != "" {
+		rows = append(rows, signer(g.letters.CargoFirmante, fontstyle.Normal))
+	}
+	return append(rows, signer(g.branding.CompanyName, fontstyle.Normal))
+}
+
+// fillLetter reemplaza los marcadores de la plantilla con los datos del trabajador y del registro
+func (g *PDFGenerator) fillLetter(tmpl string, u *models.Usuario, cert *models.Certificado) string {
+	values := map[string]string{
+		"trabajador":       cert.Trabajador,
+		"nombres":          u.Nombres,
+		"apellidos":        strings.TrimSpace(u.ApellidoPaterno + " " + u.ApellidoMaterno),
+		"tratamiento":      tratamiento(u.Sexo),
+		"tipo_documento":   cert.Documento().Label(),
+		"numero_documento": cert.Dni,
+		"dni":              cert.Dni,
+		"puesto":           cert.Puesto,
+		"lugar_trabajo":    u.LugarTrabajo,
+		"fecha_ingreso":    fechaTexto(cert.FechaIngreso),
+		"fecha_cese":       fechaTexto(cert.FechaCese),
+		"empresa":          g.branding.CompanyName,
+		"ruc":              g.branding.RUC,
+		"codigo":           cert.Codigo,
+	}
+	return placeholderPattern.ReplaceAllStringFunc(tmpl, func(match string) string {
+		if value, ok := values[match[1:len(match)-1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// letterParagraphs separa el texto en párrafos por las líneas en blanco
+func letterParagraphs(body string) []string {
+	var paragraphs []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.Join(strings.Fields(paragraph), " "); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	return paragraphs
+}
+
+// isPlaceholder indica si el marcador existe
+func isPlaceholder(name string) bool {
+	for _, placeholder := range LetterPlaceholders {
+		if placeholder == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tratamiento forma de referirse al trabajador según el sexo registrado
+func tratamiento(sexo string) string {
+	switch strings.ToUpper(strings.TrimSpace(sexo)) {
+	case "M", "MASCULINO":
+		return "el señor"
+	case "F", "FEMENINO":
+		return "la señora"
+	}
+	return "el(la) señor(a)"
+}
+
+// fechaTexto escribe en letras una fecha de texto del usuario; si no se reconoce el formato
+// se devuelve tal cual
+func fechaTexto(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return fechaLarga(t)
+		}
+	}
+	return value
+}
+
+// fechaLarga fecha en letras: 5 de marzo de 2024
+func fechaLarga(t time.Time) string {
+	return fmt.Sprintf("%d de %s de %d", t.Day(), meses[t.Month()-1], t.Year())
+}
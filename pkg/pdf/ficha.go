@@ -0,0 +1,130 @@
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/line"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+
+	"github.com/luispfcanales/rainforestapp/pkg/ficha"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// renderSection dibuja una sección del modelo de la ficha
+func (g *PDFGenerator) renderSection(m core.Maroto, section ficha.Section, doc *ficha.Document) {
+	m.AddRows(
+		row.New(8).Add(col.New(12).Add(text.New(section.Number+". "+section.Title, props.Text{
+			Style: fontstyle.Bold, Size: 11, Color: g.primaryColor(), Top: 1,
+		}))),
+		row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: g.primaryColor(), Thickness: 1}))),
+		row.New(3),
+	)
+
+	for _, block := range section.Blocks {
+		g.renderBlock(m, block)
+	}
+
+	if section.Signature {
+		m.AddRows(g.signatureRows(doc)...)
+	}
+}
+
+// renderBlock dibuja los campos, la tabla o el párrafo de un bloque
+func (g *PDFGenerator) renderBlock(m core.Maroto, block ficha.Block) {
+	headerTextStyle := props.Text{Style: fontstyle.Bold, Size: 9, Align: align.Center}
+	cellTextStyle := props.Text{Size: 8, Align: align.Center}
+
+	if block.Subtitle != "" {
+		m.AddRows(row.New(6).Add(col.New(12).Add(text.New(block.Subtitle, props.Text{Style: fontstyle.Bold, Size: 9}))))
+	}
+
+	for _, fields := range block.Rows {
+		cols := make([]core.Col, 0, len(fields))
+		for _, field := range fields {
+			cols = append(cols, col.New(field.Span).Add(
+				text.New(fmt.Sprintf("%s: %s", field.Label, field.DisplayValue()), props.Text{Size: 8, Align: align.Left, Left: 2}),
+			))
+		}
+		m.AddRows(row.New(6).Add(cols...))
+	}
+
+	if table := block.Table; table != nil {
+		header := make([]core.Col, 0, len(table.Columns))
+		for _, column := range table.Columns {
+			header = append(header, col.New(column.Span).Add(text.New(column.Title, headerTextStyle)))
+		}
+		m.AddRows(
+			row.New(6).Add(header...),
+			row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: g.textColor(), Thickness: 0.5}))),
+		)
+
+		for _, values := range table.Rows {
+			cells := make([]core.Col, 0, len(values))
+			for i, value := range values {
+				cells = append(cells, col.New(table.Columns[i].Span).Add(text.New(value, cellTextStyle)))
+			}
+			m.AddRows(row.New(5).Add(cells...))
+		}
+		if len(table.Rows) == 0 {
+			m.AddRows(row.New(5).Add(col.New(12).Add(text.New(table.Empty, cellTextStyle))))
+		}
+	}
+
+	if block.Text != "" {
+		m.AddRows(row.New(16).Add(col.New(12).Add(text.New(block.Text, props.Text{Size: 8, Align: align.Justify, Left: 2, Right: 2}))))
+	}
+
+	m.AddRows(row.New(5))
+}
+
+// signatureRows devuelve el espacio para la fecha, la firma y la huella digital
+func (g *PDFGenerator) signatureRows(doc *ficha.Document) []core.Row {
+	fingerprintBox := &props.Cell{BorderType: border.Full, BorderColor: g.textColor(), BorderThickness: 0.3}
+
+	return []core.Row{
+		row.New(8).Add(col.New(12).Add(text.New("Lugar y fecha: ________________________________", props.Text{Size: 8, Left: 2, Top: 2}))),
+		row.New(42).Add(
+			col.New(1),
+			col.New(6).Add(
+				line.New(props.Line{Color: g.textColor(), Thickness: 0.5, OffsetPercent: 62, SizePercent: 90}),
+				text.New("Firma del trabajador", props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Center, Top: 28}),
+				text.New(doc.Trabajador, props.Text{Size: 8, Align: align.Center, Top: 32, Color: g.textColor()}),
+				text.New(doc.Documento, props.Text{Size: 8, Align: align.Center, Top: 36, Color: g.textColor()}),
+			),
+			col.New(2),
+			col.New(2).WithStyle(fingerprintBox).Add(
+				text.New("Huella digital", props.Text{Size: 7, Align: align.Center, Top: 36, Color: g.textColor()}),
+			),
+			col.New(1),
+		),
+	}
+}
+
+// Layout arma el modelo de la ficha con las secciones del branding del generador
+func (g *PDFGenerator) Layout(usuario *models.Usuario) *ficha.Document {
+	return ficha.Build(usuario, g.branding.Sections)
+}
+
+// Theme devuelve el branding del generador para dibujar la ficha en HTML
+func (g *PDFGenerator) Theme() ficha.Theme {
+	return ficha.Theme{
+		CompanyName:  g.branding.CompanyName,
+		RUC:          g.branding.RUC,
+		Logo:         g.branding.Logo,
+		PrimaryColor: hexColor(g.primaryColor()),
+		TextColor:    hexColor(g.textColor()),
+		FooterText:   g.branding.FooterText,
+	}
+}
+
+// hexColor devuelve el color en formato #RRGGBB
+func hexColor(c *props.Color) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.Red, c.Green, c.Blue)
+}
@@ -0,0 +1,278 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/code"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
+	"github.com/johnfercher/maroto/v2/pkg/components/line"
+	"github.com/johnfercher/maroto/v2/pkg/components/page"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
+	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+
+	"github.com/luispfcanales/rainforestapp/pkg/ficha"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// Código impreso en el fotocheck con el número de documento del trabajador
+const (
+	BadgeBarcode = "barras"
+	BadgeQR      = "qr"
+)
+
+// Medidas en milímetros de la hoja de fotochecks: tarjetas CR80 en vertical, 3 x 3 por A4,
+// separadas por el espacio de las marcas de corte
+const (
+	badgeWidth   = 53.98
+	badgeHeight  = 85.6
+	badgeColumns = 3
+	badgeRows    = 3
+	badgeGutter  = 5.0
+	// badgeGridUnit ancho de una columna de la grilla; las marcas de corte se dibujan en
+	// columnas de una unidad para que caigan sobre el borde de la tarjeta
+	badgeGridUnit = 0.01
+	// badgeEdge alto de las filas del borde superior e inferior que llevan las marcas horizontales
+	badgeEdge = 0.2
+)
+
+// IsBadgeCode indica si el tipo de código del fotocheck existe
+func IsBadgeCode(c string) bool {
+	return c == BadgeBarcode || c == BadgeQR
+}
+
+// GenerateFotocheckPDF genera los fotochecks de los trabajadores en hojas A4 con marcas de corte,
+// agrupados por lugar de trabajo. La contraseña con el número de documento solo aplica al fotocheck de un
+// trabajador.
+func (g *PDFGenerator) GenerateFotocheckPDF(ctx context.Context, usuarios []*models.Usuario, badgeCode string, sec Security) ([]byte, error) {
+	start := time.Now()
+
+	if !IsBadgeCode(badgeCode) {
+		return nil, fmt.Errorf("código de fotocheck %q no soportado", badgeCode)
+	}
+
+	sheetWidth := badgeColumns*badgeWidth + (badgeColumns+1)*badgeGutter
+	sheetHeight := badgeRows*badgeHeight + (badgeRows+1)*badgeGutter
+	pageWidth, pageHeight := pagesize.GetDimensions(pagesize.A4)
+	marginX := (pageWidth - sheetWidth) / 2
+	marginY := (pageHeight - sheetHeight) / 2
+
+	// El margen inferior es menor para que la última fila no pase a otra hoja por redondeo
+	cfg := g.withFonts(config.NewBuilder().
+		WithPageSize(pagesize.A4).
+		WithOrientation(orientation.Vertical).
+		WithMaxGridSize(gridSpan(sheetWidth)).
+		WithLeftMargin(marginX).
+		WithRightMargin(marginX).
+		WithTopMargin(marginY).
+		WithBottomMargin(marginY - 1)).
+		Build()
+
+	m := maroto.New(cfg)
+
+	var ordered []*models.Usuario
+	for _, group := range groupByLugarTrabajo(usuarios) {
+		ordered = append(ordered, group.usuarios...)
+	}
+
+	perPage := badgeColumns * badgeRows
+	for first := 0; first < len(ordered); first += perPage {
+		sheet := ordered[first:min(first+perPage, len(ordered))]
+
+		var rows []core.Row
+		var above []*models.Usuario
+		for i := 0; i < len(sheet); i += badgeColumns {
+			slots := sheet[i:min(i+badgeColumns, len(sheet))]
+			rows = append(rows, g.badgeMarkRow(above, slots))
+			rows = append(rows, g.badgeCardRows(slots, badgeCode)...)
+			above = slots
+		}
+		rows = append(rows, g.badgeMarkRow(above, nil))
+
+		m.AddPages(page.New().Add(rows...))
+	}
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("error generando PDF: %w", err)
+	}
+
+	sec = g.protection(sec)
+	info := DocumentInfo{Tipo: DocumentFotocheck}
+	if len(usuarios) == 1 {
+		info.UsuarioID = usuarios[0].ID
+		if sec.PasswordDNI {
+			sec.Password = usuarios[0].Documento().Numero
+		}
+	}
+	pdfBytes, err := g.finish(ctx, document.GetBytes(), info, sec, start)
+	if err != nil {
+		return nil, err
+	}
+	metrics.PDFGenerated("fotocheck", time.Since(start), len(pdfBytes))
+
+	return pdfBytes, nil
+}
+
+// badgeCardRows filas de una hilera de fotochecks; cada fila atraviesa las tarjetas de la hilera
+func (g *PDFGenerator) badgeCardRows(slots []*models.Usuario, badgeCode string) []core.Row {
+	band := &props.Cell{BackgroundColor: g.primaryColor()}
+	white := &props.Color{Red: 255, Green: 255, Blue: 255}
+	centered := func(value string, size float64, style fontstyle.Type, color *props.Color) core.Component {
+		return text.New(value, props.Text{Size: size, Style: style, Align: align.Center, Left: 2, Right: 2, Color: color})
+	}
+	card := func(components ...core.Component) []core.Col {
+		return []core.Col{col.New(gridSpan(badgeWidth)).Add(components...)}
+	}
+
+	return []core.Row{
+		g.badgeRow(badgeEdge, slots, 5, func(u *models.Usuario) []core.Col {
+			return []core.Col{col.New(gridSpan(badgeWidth)).WithStyle(band)}
+		}),
+		g.badgeRow(13.8, slots, 0, func(u *models.Usuario) []core.Col {
+			logo := col.New(gridSpan(14)).WithStyle(band)
+			if len(g.branding.Logo) > 0 {
+				logo.Add(image.NewFromBytes(g.branding.Logo, imageExtension(g.branding.Logo), props.Rect{Center: true, Percent: 80}))
+			}
+			return []core.Col{
+				logo,
+				col.New(gridSpan(badgeWidth) - gridSpan(14)).WithStyle(band).Add(text.New(g.branding.CompanyName, props.Text{
+					Size: 7, Style: fontstyle.Bold, Align: align.Center, Top: 4, Right: 1, Color: white,
+				})),
+			}
+		}),
+		g.badgeRow(2, slots, 0, nil),
+		g.badgeRow(28, slots, 0, func(u *models.Usuario) []core.Col {
+			return card(g.badgePhoto(u))
+		}),
+		g.badgeRow(1, slots, 0, nil),
+		g.badgeRow(5, slots, 0, func(u *models.Usuario) []core.Col {
+			return card(centered(strings.ToUpper(u.Nombres), 9, fontstyle.Bold, nil))
+		}),
+		g.badgeRow(5, slots, 0, func(u *models.Usuario) []core.Col {
+			apellidos := strings.TrimSpace(u.ApellidoPaterno + " " + u.ApellidoMaterno)
+			return card(centered(strings.ToUpper(apellidos), 9, fontstyle.Bold, nil))
+		}),
+		g.badgeRow(5, slots, 0, func(u *models.Usuario) []core.Col {
+			return card(centered(u.PuestoActual, 7, fontstyle.Normal, g.primaryColor()))
+		}),
+		g.badgeRow(5, slots, 0, func(u *models.Usuario) []core.Col {
+			half := gridSpan(badgeWidth) / 2
+			return []core.Col{
+				col.New(half).Add(centered(u.Documento().Label()+": "+u.Documento().Numero, 7, fontstyle.Bold, nil)),
+				col.New(gridSpan(badgeWidth) - half).Add(centered("G.S.: "+orDash(u.GrupoSanguineo), 7, fontstyle.Bold, nil)),
+			}
+		}),
+		g.badgeRow(8, slots, 0, func(u *models.Usuario) []core.Col {
+			return card(centered(emergencyContact(u), 6, fontstyle.Normal, g.textColor()))
+		}),
+		g.badgeRow(11, slots, 0, func(u *models.Usuario) []core.Col {
+			if badgeCode == BadgeQR {
+				return card(code.NewQr(u.Documento().Numero, props.Rect{Center: true, Percent: 95}))
+			}
+			return card(code.NewBar(u.Documento().Numero, props.Barcode{Center: true, Percent: 80, Proportion: props.Proportion{Width: 5, Height: 1}}))
+		}),
+		g.badgeRow(1.4, slots, 0, func(u *models.Usuario) []core.Col {
+			return []core.Col{col.New(gridSpan(badgeWidth)).WithStyle(band)}
+		}),
+		g.badgeRow(badgeEdge, slots, 95, func(u *models.Usuario) []core.Col {
+			return []core.Col{col.New(gridSpan(badgeWidth)).WithStyle(band)}
+		}),
+	}
+}
+
+// badgeRow arma una fila de la hilera: el separador, las columnas de cada tarjeta y el
+// separador siguiente. markOffset distinto de cero dibuja en los separadores la marca de corte
+// horizontal a esa altura de la fila (5 arriba, 95 abajo).
+func (g *PDFGenerator) badgeRow(height float64, slots []*models.Usuario, markOffset float64, card func(u *models.Usuario) []core.Col) core.Row {
+	gutter := func(marked bool) core.Col {
+		c := col.New(gridSpan(badgeGutter))
+		if marked && markOffset > 0 {
+			c.Add(line.New(props.Line{Thickness: 0.2, OffsetPercent: markOffset, SizePercent: 60}))
+		}
+		return c
+	}
+
+	cols := []core.Col{gutter(len(slots) > 0)}
+	for i := 0; i < badgeColumns; i++ {
+		if i < len(slots) && card != nil {
+			cols = append(cols, card(slots[i])...)
+		} else {
+			cols = append(cols, col.New(gridSpan(badgeWidth)))
+		}
+		cols = append(cols, gutter(i < len(slots)))
+	}
+	return row.New(height).Add(cols...)
+}
+
+// badgeMarkRow separador entre hileras con las marcas de corte verticales de las tarjetas de
+// la hilera anterior y de la siguiente
+func (g *PDFGenerator) badgeMarkRow(above, below []*models.Usuario) core.Row {
+	mark := func() core.Col {
+		return col.New(1).Add(line.New(props.Line{Thickness: 0.2, Orientation: orientation.Vertical, SizePercent: 60}))
+	}
+
+	cols := []core.Col{col.New(gridSpan(badgeGutter))}
+	for i := 0; i < badgeColumns; i++ {
+		if i < len(above) || i < len(below) {
+			cols = append(cols, mark(), col.New(gridSpan(badgeWidth)-2), mark())
+		} else {
+			cols = append(cols, col.New(gridSpan(badgeWidth)))
+		}
+		cols = append(cols, col.New(gridSpan(badgeGutter)))
+	}
+	return row.New(badgeGutter).Add(cols...)
+}
+
+// badgePhoto foto del trabajador; si no tiene o no es PNG/JPEG se deja el recuadro para pegarla
+func (g *PDFGenerator) badgePhoto(u *models.Usuario) core.Component {
+	foto := ficha.DecodeFoto(u.Foto)
+	if contentType := http.DetectContentType(foto); len(foto) > 0 && (contentType == "image/png" || contentType == "image/jpeg") {
+		return image.NewFromBytes(foto, imageExtension(foto), props.Rect{Center: true, Percent: 100})
+	}
+	return text.New("[ SIN FOTO ]", props.Text{
+		Size: 8, Style: fontstyle.BoldItalic, Align: align.Center, Top: 12,
+		Color: &props.Color{Red: 150, Green: 150, Blue: 150},
+	})
+}
+
+// emergencyContact contacto de emergencia en una línea: nombre (parentesco) celular
+func emergencyContact(u *models.Usuario) string {
+	contact := strings.TrimSpace(u.ContactoNombre)
+	if u.ContactoParentesco != "" {
+		contact += " (" + u.ContactoParentesco + ")"
+	}
+	if phone := strings.TrimSpace(u.ContactoCelular); phone != "" {
+		contact += " " + phone
+	} else if phone := strings.TrimSpace(u.ContactoTelefonoFijo); phone != "" {
+		contact += " " + phone
+	}
+	return "EMERGENCIA: " + orDash(strings.TrimSpace(contact))
+}
+
+// orDash devuelve "-" si el valor está vacío
+func orDash(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+	return value
+}
+
+// gridSpan columnas de la grilla que ocupa la medida en milímetros
+func gridSpan(mm float64) int {
+	return int(math.Round(mm / badgeGridUnit))
+}
@@ -1,14 +1,18 @@
 package pdf
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
-	"encoding/base64"
 	"fmt"
-	"strings"
+	stdimage "image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
 	"time"
 
 	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/code"
 	"github.com/johnfercher/maroto/v2/pkg/components/col"
 	"github.com/johnfercher/maroto/v2/pkg/components/image"
 	"github.com/johnfercher/maroto/v2/pkg/components/line"
@@ -22,16 +26,42 @@ import (
 	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
 	"github.com/johnfercher/maroto/v2/pkg/core"
 	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/luispfcanales/rainforestapp/pkg/ficha"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
 	"github.com/luispfcanales/rainforestapp/pkg/models"
 )
 
 //go:embed rainforest.png
 var logoBytes []byte
 
-type PDFGenerator struct{}
+type PDFGenerator struct {
+	branding Branding
+	verifier Verifier
+	signer   Signer
+	security Security
+	letters  Letters
+}
+
+// Verifier emite la URL de verificación que se imprime como QR en la ficha y el código
+// corto que la acompaña
+type Verifier interface {
+	Issue(u *models.Usuario, issuedAt time.Time) (url string, code string, err error)
+}
+
+// WithVerifier agrega el QR de verificación a las fichas generadas
+func (g *PDFGenerator) WithVerifier(v Verifier) *PDFGenerator {
+	g.verifier = v
+	return g
+}
 
-func NewPDFGenerator() *PDFGenerator {
-	return &PDFGenerator{}
+func NewPDFGenerator(branding Branding) *PDFGenerator {
+	if len(branding.Logo) == 0 {
+		branding.Logo = logoBytes
+	}
+	if branding.CompanyName == "" {
+		branding.CompanyName = DefaultBranding().CompanyName
+	}
+	return &PDFGenerator{branding: branding}
 }
 
 // Colores como en la documentación oficial
@@ -51,37 +81,107 @@ func getTextColor() *props.Color {
 	}
 }
 
-// decodeBase64Image decodifica el string base64 a bytes
-func decodeBase64Image(base64Str string) ([]byte, error) {
-	// Remover el prefijo data:image/jpeg;base64, si existe
-	if strings.Contains(base64Str, "base64,") {
-		parts := strings.Split(base64Str, "base64,")
-		if len(parts) > 1 {
-			base64Str = parts[1]
-		}
+// imageExtension detecta el formato de una imagen (PNG o JPG)
+func imageExtension(img []byte) extension.Type {
+	if http.DetectContentType(img) == "image/png" {
+		return extension.Png
+	}
+	return extension.Jpg
+}
+
+// GenerateUsuarioPDF - Versión mejorada con diseño profesional (Cuadros). sec agrega protección
+// a la configurada en el generador.
+func (g *PDFGenerator) GenerateUsuarioPDF(ctx context.Context, usuario *models.Usuario, sec Security) ([]byte, error) {
+	start := time.Now()
+
+	document, err := g.renderUsuario(usuario, start, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sec = g.protection(sec)
+	if sec.PasswordDNI {
+		sec.Password = usuario.Documento().Numero
+	}
+	pdfBytes, err := g.finish(ctx, document, DocumentInfo{Tipo: DocumentFicha, UsuarioID: usuario.ID}, sec, start)
+	if err != nil {
+		return nil, err
+	}
+	metrics.PDFGenerated("ficha", time.Since(start), len(pdfBytes))
+
+	return pdfBytes, nil
+}
+
+// GeneratePreviewPDF genera la ficha de un registro que aún no se guardó: sin QR de
+// verificación ni firma digital y con la marca de agua "VISTA PREVIA"
+func (g *PDFGenerator) GeneratePreviewPDF(usuario *models.Usuario) ([]byte, error) {
+	start := time.Now()
+
+	document, err := g.renderUsuario(usuario, start, false)
+	if err != nil {
+		return nil, err
 	}
 
-	// Decodificar base64
-	imgBytes, err := base64.StdEncoding.DecodeString(base64Str)
+	preview, err := watermark(document, previewWatermark)
 	if err != nil {
-		return nil, fmt.Errorf("error decodificando base64: %w", err)
+		return nil, fmt.Errorf("error agregando marca de agua: %w", err)
 	}
+	metrics.PDFGenerated("ficha_preview", time.Since(start), len(preview))
 
-	return imgBytes, nil
+	return preview, nil
 }
 
-// GenerateUsuarioPDF - Versión mejorada con diseño profesional (Cuadros)
-func (g *PDFGenerator) GenerateUsuarioPDF(ctx context.Context, usuario *models.Usuario) ([]byte, error) {
-	cfg := config.NewBuilder().
+// renderUsuario dibuja la ficha del usuario; withVerification agrega el QR de verificación
+func (g *PDFGenerator) renderUsuario(usuario *models.Usuario, start time.Time, withVerification bool) ([]byte, error) {
+	cfg := g.withFonts(config.NewBuilder().
 		WithPageSize(pagesize.A4).
 		WithOrientation(orientation.Vertical).
 		WithLeftMargin(15).
 		WithTopMargin(15).
 		WithRightMargin(15).
 		WithBottomMargin(15).
+		WithPageNumber(props.PageNumber{
+			Pattern: "Página {current} de {total}",
+			Place:   props.RightBottom,
+			Size:    8,
+			Color:   g.textColor(),
+		})).
 		Build()
 
 	m := maroto.New(cfg)
+	doc := g.Layout(usuario)
+
+	// Encabezado y pie repetidos en cada página para identificar al trabajador en hojas sueltas
+	err := m.RegisterHeader(
+		row.New(5).Add(
+			col.New(6).Add(text.New(g.branding.CompanyName+" - Ficha de datos del personal", props.Text{
+				Size: 7, Align: align.Left, Color: g.textColor(),
+			})),
+			col.New(6).Add(text.New(doc.Trabajador+" - "+doc.Documento, props.Text{
+				Size: 7, Style: fontstyle.Bold, Align: align.Right, Color: g.textColor(),
+			})),
+		),
+		row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: g.primaryColor(), Thickness: 0.5}))),
+		row.New(3),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error registrando encabezado: %w", err)
+	}
+
+	err = m.RegisterFooter(
+		row.New(6).Add(
+			col.New(12).Add(line.New(props.Line{Color: g.primaryColor(), Thickness: 0.5})),
+		),
+		row.New(4).Add(
+			col.New(6).Add(text.New(g.branding.FooterText, props.Text{Size: 7, Color: g.textColor()})),
+			col.New(4).Add(text.New(fmt.Sprintf("Generado el: %s", start.Format("02/01/2006 15:04:05")), props.Text{
+				Size: 7, Align: align.Right, Color: g.textColor(),
+			})),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error registrando pie de página: %w", err)
+	}
 
 	// -- HEADER --
 	m.AddRows(
@@ -89,8 +189,8 @@ func (g *PDFGenerator) GenerateUsuarioPDF(ctx context.Context, usuario *models.U
 			// Logo (Izquierda)
 			col.New(3).Add(
 				func() core.Component {
-					if len(logoBytes) > 0 {
-						return image.NewFromBytes(logoBytes, extension.Png, props.Rect{
+					if len(g.branding.Logo) > 0 {
+						return image.NewFromBytes(g.branding.Logo, imageExtension(g.branding.Logo), props.Rect{
 							Center:  true,
 							Percent: 90,
 						})
@@ -104,31 +204,39 @@ func (g *PDFGenerator) GenerateUsuarioPDF(ctx context.Context, usuario *models.U
 			),
 			// Título (Centro)
 			col.New(6).Add(
-				text.New("RAINFOREST ENTERPRISE", props.Text{
+				text.New(g.branding.CompanyName, props.Text{
 					Size:  16,
 					Style: fontstyle.Bold,
 					Align: align.Center,
-					Color: getHeaderColor(),
+					Color: g.primaryColor(),
 					Top:   5,
 				}),
-				text.New("FICHA DE DATOS DEL PERSONAL", props.Text{
+				text.New(ficha.Title, props.Text{
 					Size:  12,
 					Style: fontstyle.Bold,
 					Align: align.Center,
 					Top:   15,
 				}),
+				func() core.Component {
+					if g.branding.RUC == "" {
+						return text.New("")
+					}
+					return text.New("RUC: "+g.branding.RUC, props.Text{
+						Size:  8,
+						Align: align.Center,
+						Top:   22,
+						Color: g.textColor(),
+					})
+				}(),
 			),
 			// Foto (Derecha)
 			col.New(3).Add(
 				func() core.Component {
-					if usuario.Foto != "" && usuario.Foto != "null" && usuario.Foto != "undefined" {
-						imgBytes, err := decodeBase64Image(usuario.Foto)
-						if err == nil {
-							return image.NewFromBytes(imgBytes, extension.Jpg, props.Rect{
-								Percent: 95,
-								Center:  true,
-							})
-						}
+					if len(doc.Foto) > 0 {
+						return image.NewFromBytes(doc.Foto, imageExtension(doc.Foto), props.Rect{
+							Percent: 95,
+							Center:  true,
+						})
 					}
 					return text.New("[ SIN FOTO ]", props.Text{
 						Size:  10,
@@ -141,292 +249,75 @@ func (g *PDFGenerator) GenerateUsuarioPDF(ctx context.Context, usuario *models.U
 			),
 		),
 		row.New(5),
-		row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: getHeaderColor(), Thickness: 2}))),
+		row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: g.primaryColor(), Thickness: 2}))),
 		row.New(5),
 	)
 
-	// Helpers
-	formatBool := func(b bool) string {
-		if b {
-			return "Si"
-		}
-		return "No"
-	}
-
-	headerTextStyle := props.Text{Style: fontstyle.Bold, Size: 9, Align: align.Center}
-	cellTextStyle := props.Text{Size: 8, Align: align.Center}
-
-	// Helper para Sección Título
-	addSectionTitle := func(title string) {
-		m.AddRows(
-			row.New(8).Add(col.New(12).Add(text.New(title, props.Text{
-				Style: fontstyle.Bold, Size: 11, Color: getHeaderColor(), Top: 1,
-			}))),
-			row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: getHeaderColor(), Thickness: 1}))),
-			row.New(3),
-		)
+	for _, section := range doc.Sections {
+		g.renderSection(m, section, doc)
 	}
 
-	// Helper para celda con borde simulado (Label: Value)
-	addLabelValue := func(label, value string) core.Component {
-		if value == "" {
-			value = "-"
+	if g.verifier != nil && withVerification {
+		verifyRow, err := g.verificationRow(usuario, start)
+		if err != nil {
+			return nil, err
 		}
-		// En Maroto v2, para simular borde en celda, podemos usar simplemente texto por ahora
-		// Ya que la implementación de bordes exactos por celda requiere layouts complejos.
-		// Usaremos formato "Label: Value"
-		return text.New(fmt.Sprintf("%s: %s", label, value), props.Text{Size: 8, Align: align.Left, Left: 2})
+		m.AddRows(verifyRow)
 	}
 
-	// -- I. DATOS PERSONALES --
-	addSectionTitle("I. DATOS PERSONALES")
+	// Generar
+	document, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("error generando PDF: %w", err)
+	}
 
-	m.AddRows(
-		row.New(6).Add(
-			col.New(3).Add(addLabelValue("DNI", usuario.Dni)),
-			col.New(5).Add(addLabelValue("Apellidos", fmt.Sprintf("%s %s", usuario.ApellidoPaterno, usuario.ApellidoMaterno))),
-			col.New(4).Add(addLabelValue("Nombres", usuario.Nombres)),
-		),
-		row.New(6).Add(
-			col.New(3).Add(addLabelValue("F. Nacimiento", usuario.FechaNacimiento)),
-			col.New(3).Add(addLabelValue("Sexo", usuario.Sexo)),
-			col.New(3).Add(addLabelValue("Estado Civil", usuario.EstadoCivil)),
-			col.New(3).Add(addLabelValue("G. Sanguíneo", usuario.GrupoSanguineo)),
-		),
-		row.New(6).Add(
-			col.New(3).Add(addLabelValue("Licencia", usuario.LicenciaConducir)),
-			col.New(3).Add(addLabelValue("Cat. Licencia", usuario.CategoriaLicencia)),
-		),
-		row.New(6).Add(
-			col.New(12).Add(addLabelValue("Dirección Domicilio", usuario.DireccionDomicilio)),
-		),
-		row.New(6).Add(
-			col.New(12).Add(addLabelValue("Lugar Nacimiento", fmt.Sprintf("%s - %s - %s", usuario.LugarNacimientoDepartamento, usuario.LugarNacimientoProvincia, usuario.LugarNacimientoDistrito))),
-		),
-		row.New(5),
-	)
+	return document.GetBytes(), nil
+}
 
-	// -- II. CONTACTO Y EMERGENCIA --
-	addSectionTitle("II. CONTACTO Y EMERGENCIA")
-	m.AddRows(
-		row.New(6).Add(
-			col.New(4).Add(addLabelValue("Teléfono", usuario.Telefono)),
-			col.New(4).Add(addLabelValue("Email", usuario.Email)),
-		),
-		row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: getTextColor(), Thickness: 0.5}))), // Separador
-		row.New(6).Add(
-			col.New(6).Add(addLabelValue("Contacto Emergencia", usuario.ContactoNombre)),
-			col.New(3).Add(addLabelValue("Parentesco", usuario.ContactoParentesco)),
-			col.New(3).Add(addLabelValue("Celular", usuario.ContactoCelular)),
-		),
-		row.New(6).Add(
-			col.New(12).Add(addLabelValue("Dirección Emergencia", usuario.ContactoDireccion)),
-		),
-		row.New(5),
-	)
+// verificationRow devuelve el QR con la URL de verificación y el código corto de la ficha
+func (g *PDFGenerator) verificationRow(usuario *models.Usuario, issuedAt time.Time) (core.Row, error) {
+	url, verifyCode, err := g.verifier.Issue(usuario, issuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error generando código de verificación: %w", err)
+	}
 
-	// -- III. DATOS LABORALES --
-	addSectionTitle("III. DATOS LABORALES")
-	m.AddRows(
-		row.New(6).Add(
-			col.New(4).Add(addLabelValue("Puesto", usuario.PuestoActual)),
-			col.New(4).Add(addLabelValue("Lugar", usuario.LugarTrabajo)),
-			col.New(4).Add(addLabelValue("F. Ingreso", usuario.FechaIngreso)),
+	return row.New(25).Add(
+		col.New(2).Add(code.NewQr(url, props.Rect{Center: true, Percent: 95})),
+		col.New(10).Add(
+			text.New("Verifique la autenticidad de esta ficha escaneando el código QR.", props.Text{
+				Size: 8, Top: 7, Left: 2, Color: g.textColor(),
+			}),
+			text.New("Código de verificación: "+verifyCode, props.Text{
+				Size: 8, Top: 12, Left: 2, Style: fontstyle.Bold, Color: g.textColor(),
+			}),
 		),
-		row.New(6).Add(
-			col.New(4).Add(addLabelValue("Régimen Pénsion", usuario.RegimenPensionario)),
-			col.New(4).Add(addLabelValue("AFP/ONP", usuario.AfpNombre+" "+usuario.Cuspp)),
-			col.New(4).Add(addLabelValue("Salud", usuario.RegimenSalud)),
-		),
-		row.New(6).Add(
-			col.New(12).Add(addLabelValue("Situación Contractual", usuario.SituacionContractual)),
-		),
-		row.New(5),
-	)
+	), nil
+}
 
-	// -- IV. APERTURA DE CUENTAS --
-	addSectionTitle("IV. APERTURA DE CUENTAS")
-	m.AddRows(
-		row.New(6).Add(
-			col.New(6).Add(addLabelValue("Autoriza Cuenta Sueldo BCP", formatBool(usuario.AutorizaBcp))),
-			col.New(6).Add(addLabelValue("Autoriza CTS BCP", formatBool(usuario.AutorizaCtsBcp))),
-		),
-	)
-	if usuario.AutorizaOtroBanco {
-		m.AddRows(row.New(6).Add(col.New(12).Add(addLabelValue("Otro Banco", fmt.Sprintf("%s (Cta: %s / CCI: %s)", usuario.OtroBancoNombre, usuario.OtroBancoCuenta, usuario.OtroBancoCci)))))
-	}
-	m.AddRows(row.New(5))
-
-	// -- V. DATOS FAMILIARES --
-	addSectionTitle("V. DATOS FAMILIARES")
-
-	// Cónyuge Table
-	m.AddRows(row.New(6).Add(col.New(12).Add(text.New("Datos del Cónyuge / Conviviente", props.Text{Style: fontstyle.Bold, Size: 9}))))
-	// Header Table
-	m.AddRows(row.New(6).Add(
-		col.New(6).Add(text.New("Apellidos y Nombres", headerTextStyle)),
-		col.New(3).Add(text.New("DNI", headerTextStyle)),
-		col.New(3).Add(text.New("F. Nacimiento", headerTextStyle)),
-	))
-	m.AddRows(row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: getTextColor(), Thickness: 0.5}))))
-	// Data or Empty
-	if usuario.DatosConyuge != nil && usuario.DatosConyuge.ApellidosNombres != "" {
-		m.AddRows(row.New(5).Add(
-			col.New(6).Add(text.New(usuario.DatosConyuge.ApellidosNombres, cellTextStyle)),
-			col.New(3).Add(text.New(usuario.DatosConyuge.Dni, cellTextStyle)),
-			col.New(3).Add(text.New(usuario.DatosConyuge.FechaNacimiento, cellTextStyle)),
-		))
-	} else {
-		m.AddRows(row.New(5).Add(col.New(12).Add(text.New("- Sin información -", cellTextStyle))))
-	}
-	m.AddRows(row.New(5))
-
-	// Hijos Table
-	m.AddRows(row.New(6).Add(col.New(12).Add(text.New("Hijos", props.Text{Style: fontstyle.Bold, Size: 9}))))
-	m.AddRows(row.New(6).Add(
-		col.New(6).Add(text.New("Apellidos y Nombres", headerTextStyle)),
-		col.New(2).Add(text.New("DNI", headerTextStyle)),
-		col.New(2).Add(text.New("F. Nac", headerTextStyle)),
-		col.New(2).Add(text.New("Edad", headerTextStyle)),
-	))
-	m.AddRows(row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: getTextColor(), Thickness: 0.5}))))
-	if len(usuario.Hijos) > 0 {
-		for _, h := range usuario.Hijos {
-			m.AddRows(row.New(5).Add(
-				col.New(6).Add(text.New(h.ApellidosNombres, cellTextStyle)),
-				col.New(2).Add(text.New(h.Dni, cellTextStyle)),
-				col.New(2).Add(text.New(h.FechaNacimiento, cellTextStyle)),
-				col.New(2).Add(text.New(fmt.Sprintf("%d", h.Edad), cellTextStyle)),
-			))
-		}
-	} else {
-		m.AddRows(row.New(5).Add(col.New(12).Add(text.New("- Sin hijos registrados -", cellTextStyle))))
-	}
-	m.AddRows(row.New(5))
-
-	// -- VI. EDUCACIÓN --
-	addSectionTitle("VI. EDUCACIÓN")
-
-	// Basica
-	m.AddRows(row.New(6).Add(col.New(12).Add(text.New("Educación Básica", props.Text{Style: fontstyle.Bold, Size: 9}))))
-	m.AddRows(row.New(6).Add(
-		col.New(2).Add(text.New("Nivel", headerTextStyle)),
-		col.New(5).Add(text.New("Institución", headerTextStyle)),
-		col.New(2).Add(text.New("Desde", headerTextStyle)),
-		col.New(2).Add(text.New("Hasta", headerTextStyle)),
-		col.New(1).Add(text.New("Comp.", headerTextStyle)),
-	))
-	m.AddRows(row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: getTextColor(), Thickness: 0.5}))))
-	if len(usuario.EducacionBasica) > 0 {
-		for _, e := range usuario.EducacionBasica {
-			m.AddRows(row.New(5).Add(
-				col.New(2).Add(text.New(e.Nivel, cellTextStyle)),
-				col.New(5).Add(text.New(e.CentroEstudios, cellTextStyle)),
-				col.New(2).Add(text.New(e.Desde, cellTextStyle)),
-				col.New(2).Add(text.New(e.Hasta, cellTextStyle)),
-				col.New(1).Add(text.New(formatBool(e.Completa), cellTextStyle)),
-			))
-		}
-	} else {
-		m.AddRows(row.New(5).Add(col.New(12).Add(text.New("- Sin información -", cellTextStyle))))
-	}
-	m.AddRows(row.New(5))
-
-	// Superior
-	m.AddRows(row.New(6).Add(col.New(12).Add(text.New("Educación Superior", props.Text{Style: fontstyle.Bold, Size: 9}))))
-	m.AddRows(row.New(6).Add(
-		col.New(2).Add(text.New("Nivel", headerTextStyle)),
-		col.New(3).Add(text.New("Institución", headerTextStyle)),
-		col.New(3).Add(text.New("Especialidad", headerTextStyle)),
-		col.New(2).Add(text.New("Periodo", headerTextStyle)),
-		col.New(2).Add(text.New("Grado", headerTextStyle)),
-	))
-	m.AddRows(row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: getTextColor(), Thickness: 0.5}))))
-	if len(usuario.EducacionSuperior) > 0 {
-		for _, e := range usuario.EducacionSuperior {
-			m.AddRows(row.New(5).Add(
-				col.New(2).Add(text.New(e.Nivel, cellTextStyle)),
-				col.New(3).Add(text.New(e.CentroEstudios, cellTextStyle)),
-				col.New(3).Add(text.New(e.Especialidad, cellTextStyle)),
-				col.New(2).Add(text.New(e.Desde+"-"+e.Hasta, cellTextStyle)),
-				col.New(2).Add(text.New(e.GradoAcademico, cellTextStyle)),
-			))
-		}
-	} else {
-		m.AddRows(row.New(5).Add(col.New(12).Add(text.New("- Sin información -", cellTextStyle))))
+// Check verifica que el logo configurado sea válido y que el generador pueda producir un PDF
+func (g *PDFGenerator) Check(ctx context.Context) error {
+	if len(g.branding.Logo) == 0 {
+		return fmt.Errorf("logo vacío")
 	}
-	m.AddRows(row.New(5))
-
-	// -- VII. EXPERIENCIA LABORAL --
-	addSectionTitle("VII. EXPERIENCIA LABORAL")
-	m.AddRows(row.New(6).Add(
-		col.New(3).Add(text.New("Empresa", headerTextStyle)),
-		col.New(3).Add(text.New("Cargo", headerTextStyle)),
-		col.New(2).Add(text.New("F. Inicio", headerTextStyle)),
-		col.New(2).Add(text.New("F. Fin", headerTextStyle)),
-		col.New(2).Add(text.New("Motivo", headerTextStyle)),
-	))
-	m.AddRows(row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: getTextColor(), Thickness: 0.5}))))
-	if len(usuario.ExperienciaLaboral) > 0 {
-		for _, ex := range usuario.ExperienciaLaboral {
-			m.AddRows(row.New(5).Add(
-				col.New(3).Add(text.New(ex.Empresa, cellTextStyle)),
-				col.New(3).Add(text.New(ex.Cargo, cellTextStyle)),
-				col.New(2).Add(text.New(ex.FechaIngreso, cellTextStyle)),
-				col.New(2).Add(text.New(ex.FechaCese, cellTextStyle)),
-				col.New(2).Add(text.New(ex.MotivoCese, cellTextStyle)),
-			))
-		}
-	} else {
-		m.AddRows(row.New(5).Add(col.New(12).Add(text.New("- Sin experiencia registrada -", cellTextStyle))))
-	}
-	m.AddRows(row.New(5))
-
-	// -- VIII. IDIOMAS --
-	addSectionTitle("VIII. IDIOMAS")
-	m.AddRows(row.New(6).Add(
-		col.New(3).Add(text.New("Idioma", headerTextStyle)),
-		col.New(3).Add(text.New("Lee", headerTextStyle)),
-		col.New(3).Add(text.New("Habla", headerTextStyle)),
-		col.New(3).Add(text.New("Escribe", headerTextStyle)),
-	))
-	m.AddRows(row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: getTextColor(), Thickness: 0.5}))))
-	if len(usuario.Idiomas) > 0 {
-		for _, i := range usuario.Idiomas {
-			m.AddRows(row.New(5).Add(
-				col.New(3).Add(text.New(i.Idioma, cellTextStyle)),
-				col.New(3).Add(text.New(i.Lee, cellTextStyle)),
-				col.New(3).Add(text.New(i.Habla, cellTextStyle)),
-				col.New(3).Add(text.New(i.Escribe, cellTextStyle)),
-			))
-		}
-	} else {
-		m.AddRows(row.New(5).Add(col.New(12).Add(text.New("- Sin idiomas registrados -", cellTextStyle))))
+	if _, _, err := stdimage.DecodeConfig(bytes.NewReader(g.branding.Logo)); err != nil {
+		return fmt.Errorf("logo inválido: %w", err)
 	}
 
-	// FOOTER
-	m.AddRows(
-		row.New(15).Add(
-			col.New(12).Add(
-				line.New(props.Line{Color: getHeaderColor(), Thickness: 1}),
-			),
-		),
-		row.New(5).Add(
-			col.New(12).Add(
-				text.New(fmt.Sprintf("Generado el: %s", time.Now().Format("02/01/2006 15:04:05")), props.Text{
-					Size: 8, Align: align.Right, Color: getTextColor(),
-				}),
-			),
-		),
-	)
+	m := maroto.New(config.NewBuilder().WithPageSize(pagesize.A4).Build())
+	m.AddRows(row.New(30).Add(
+		col.New(3).Add(image.NewFromBytes(g.branding.Logo, imageExtension(g.branding.Logo), props.Rect{Center: true, Percent: 90})),
+		col.New(9).Add(text.New("health check")),
+	))
 
-	// Generar
 	document, err := m.Generate()
 	if err != nil {
-		return nil, fmt.Errorf("error generando PDF: %w", err)
+		return fmt.Errorf("error generando PDF de prueba: %w", err)
+	}
+	if len(document.GetBytes()) == 0 {
+		return fmt.Errorf("PDF de prueba vacío")
 	}
 
-	return document.GetBytes(), nil
+	return nil
 }
 
 // calcularTiempoEnSistema calcula cuánto tiempo lleva el usuario en el sistema
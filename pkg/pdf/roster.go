@@ -0,0 +1,216 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
+	"github.com/johnfercher/maroto/v2/pkg/components/line"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/consts/orientation"
+	"github.com/johnfercher/maroto/v2/pkg/consts/pagesize"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// sinLugarTrabajo grupo para los trabajadores sin lugar de trabajo registrado
+const sinLugarTrabajo = "SIN LUGAR DE TRABAJO"
+
+// rosterGroup trabajadores de un mismo lugar de trabajo
+type rosterGroup struct {
+	lugar    string
+	usuarios []*models.Usuario
+}
+
+// groupByLugarTrabajo agrupa los usuarios por lugar de trabajo, ordenados por lugar y apellidos
+func groupByLugarTrabajo(usuarios []*models.Usuario) []rosterGroup {
+	index := make(map[string]int)
+	var groups []rosterGroup
+
+	for _, u := range usuarios {
+		lugar := strings.TrimSpace(u.LugarTrabajo)
+		if lugar == "" {
+			lugar = sinLugarTrabajo
+		}
+		i, ok := index[lugar]
+		if !ok {
+			i = len(groups)
+			index[lugar] = i
+			groups = append(groups, rosterGroup{lugar: lugar})
+		}
+		groups[i].usuarios = append(groups[i].usuarios, u)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].lugar < groups[j].lugar
+	})
+	for _, g := range groups {
+		sort.SliceStable(g.usuarios, func(i, j int) bool {
+			return fullName(g.usuarios[i]) < fullName(g.usuarios[j])
+		})
+	}
+
+	return groups
+}
+
+// fullName devuelve "APELLIDO_PATERNO APELLIDO_MATERNO, NOMBRES"
+func fullName(u *models.Usuario) string {
+	apellidos := strings.TrimSpace(u.ApellidoPaterno + " " + u.ApellidoMaterno)
+	return fmt.Sprintf("%s, %s", apellidos, u.Nombres)
+}
+
+// regimenPension devuelve el régimen pensionario incluyendo la AFP si corresponde
+func regimenPension(u *models.Usuario) string {
+	if u.AfpNombre != "" {
+		return fmt.Sprintf("%s - %s", u.RegimenPensionario, u.AfpNombre)
+	}
+	return u.RegimenPensionario
+}
+
+// GenerateRosterPDF genera el padrón de personal en formato horizontal agrupado por lugar de trabajo.
+// La contraseña con el número de documento no aplica al padrón porque incluye a varios trabajadores.
+func (g *PDFGenerator) GenerateRosterPDF(ctx context.Context, usuarios []*models.Usuario, filter models.UsuarioFilter, sec Security) ([]byte, error) {
+	start := time.Now()
+	generatedAt := start.Format("02/01/2006 15:04:05")
+
+	cfg := g.withFonts(config.NewBuilder().
+		WithPageSize(pagesize.A4).
+		WithOrientation(orientation.Horizontal).
+		WithLeftMargin(10).
+		WithTopMargin(10).
+		WithRightMargin(10).
+		WithBottomMargin(10).
+		WithPageNumber(props.PageNumber{
+			Pattern: "Página {current} de {total}",
+			Place:   props.RightBottom,
+			Size:    8,
+			Color:   g.textColor(),
+		})).
+		Build()
+
+	m := maroto.New(cfg)
+
+	headerTextStyle := props.Text{Style: fontstyle.Bold, Size: 8, Align: align.Center, Top: 1, Color: &props.Color{Red: 255, Green: 255, Blue: 255}}
+	cellTextStyle := props.Text{Size: 7, Align: align.Left, Left: 1, Top: 1}
+	headerCell := &props.Cell{BackgroundColor: g.primaryColor()}
+
+	// Encabezado repetido en cada página con los títulos de las columnas
+	err := m.RegisterHeader(
+		row.New(18).Add(
+			col.New(2).Add(
+				func() core.Component {
+					if len(g.branding.Logo) > 0 {
+						return image.NewFromBytes(g.branding.Logo, imageExtension(g.branding.Logo), props.Rect{Center: true, Percent: 90})
+					}
+					return text.New("")
+				}(),
+			),
+			col.New(8).Add(
+				text.New(g.branding.CompanyName, props.Text{Size: 13, Style: fontstyle.Bold, Align: align.Center, Color: g.primaryColor()}),
+				text.New("PADRÓN GENERAL DE PERSONAL", props.Text{Size: 11, Style: fontstyle.Bold, Align: align.Center, Top: 7}),
+				text.New(filter.Description(), props.Text{Size: 8, Align: align.Center, Top: 13, Color: g.textColor()}),
+			),
+			col.New(2).Add(
+				text.New(generatedAt, props.Text{Size: 7, Align: align.Right, Color: g.textColor()}),
+			),
+		),
+		row.New(2),
+		row.New(6).WithStyle(headerCell).Add(
+			col.New(1).Add(text.New("N°", headerTextStyle)),
+			col.New(1).Add(text.New("Documento", headerTextStyle)),
+			col.New(3).Add(text.New("Apellidos y Nombres", headerTextStyle)),
+			col.New(2).Add(text.New("Puesto", headerTextStyle)),
+			col.New(1).Add(text.New("F. Ingreso", headerTextStyle)),
+			col.New(2).Add(text.New("Situación Contractual", headerTextStyle)),
+			col.New(2).Add(text.New("Régimen Pensionario", headerTextStyle)),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error registrando encabezado: %w", err)
+	}
+
+	err = m.RegisterFooter(
+		row.New(6).Add(
+			col.New(12).Add(line.New(props.Line{Color: g.primaryColor(), Thickness: 0.5})),
+		),
+		row.New(4).Add(
+			col.New(8).Add(text.New(fmt.Sprintf("%s - Padrón de personal generado el %s", g.branding.CompanyName, generatedAt), props.Text{Size: 7, Color: g.textColor()})),
+			col.New(4).Add(text.New(g.branding.FooterText, props.Text{Size: 7, Align: align.Right, Color: g.textColor()})),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error registrando pie de página: %w", err)
+	}
+
+	groups := groupByLugarTrabajo(usuarios)
+	if len(groups) == 0 {
+		m.AddRows(row.New(10).Add(col.New(12).Add(text.New("- No se encontraron trabajadores -", props.Text{Size: 9, Align: align.Center, Top: 3}))))
+	}
+
+	n := 0
+	for _, group := range groups {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		m.AddRows(
+			row.New(7).Add(col.New(12).Add(text.New(group.lugar, props.Text{Size: 9, Style: fontstyle.Bold, Color: g.primaryColor(), Top: 2}))),
+			row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: g.primaryColor(), Thickness: 0.5}))),
+		)
+
+		for _, u := range group.usuarios {
+			n++
+			m.AddRows(row.New(5).Add(
+				col.New(1).Add(text.New(fmt.Sprintf("%d", n), props.Text{Size: 7, Align: align.Center, Top: 1})),
+				col.New(1).Add(text.New(u.Documento().String(), cellTextStyle)),
+				col.New(3).Add(text.New(fullName(u), cellTextStyle)),
+				col.New(2).Add(text.New(u.PuestoActual, cellTextStyle)),
+				col.New(1).Add(text.New(u.FechaIngreso, props.Text{Size: 7, Align: align.Center, Top: 1})),
+				col.New(2).Add(text.New(u.SituacionContractual, cellTextStyle)),
+				col.New(2).Add(text.New(regimenPension(u), cellTextStyle)),
+			))
+		}
+
+		// Subtotal del lugar de trabajo
+		m.AddRows(
+			row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: g.textColor(), Thickness: 0.3}))),
+			row.New(6).Add(col.New(12).Add(text.New(
+				fmt.Sprintf("Subtotal %s: %d trabajador(es)", group.lugar, len(group.usuarios)),
+				props.Text{Size: 8, Style: fontstyle.Bold, Align: align.Right, Top: 1},
+			))),
+			row.New(3),
+		)
+	}
+
+	m.AddRows(
+		row.New(1).Add(col.New(12).Add(line.New(props.Line{Color: g.primaryColor(), Thickness: 1}))),
+		row.New(7).Add(col.New(12).Add(text.New(
+			fmt.Sprintf("TOTAL GENERAL: %d trabajador(es) en %d lugar(es) de trabajo", len(usuarios), len(groups)),
+			props.Text{Size: 9, Style: fontstyle.Bold, Align: align.Right, Top: 2},
+		))),
+	)
+
+	document, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("error generando PDF: %w", err)
+	}
+
+	pdfBytes, err := g.finish(ctx, document.GetBytes(), DocumentInfo{Tipo: DocumentPadron}, g.protection(sec), start)
+	if err != nil {
+		return nil, err
+	}
+	metrics.PDFGenerated("padron", time.Since(start), len(pdfBytes))
+
+	return pdfBytes, nil
+}
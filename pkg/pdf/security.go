@@ -0,0 +1,211 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// ErrSignedEncrypted se pidió cifrar un documento de un generador que firma: la firma PAdES
+// no sobrevive al cifrado y no se puede firmar un PDF cifrado
+var ErrSignedEncrypted = errors.New("los documentos firmados digitalmente no se pueden cifrar: quite contrasena_dni, restringir_impresion y restringir_copia")
+
+// DefaultWatermarkText texto de la marca de agua si no se configura otro
+const DefaultWatermarkText = "CONFIDENCIAL"
+
+// previewWatermark marca de agua de las fichas de vista previa
+const previewWatermark = "VISTA PREVIA"
+
+// watermarkDescription diagonal, semitransparente y bajo el contenido para no tapar los datos
+const watermarkDescription = "font:Helvetica-Bold, points:40, diagonal:1, scalefactor:0.8 rel, fillcolor:#B40000, opacity:0.18"
+
+func init() {
+	// pdfcpu crea por defecto un directorio de configuración en el home del usuario y
+	// termina el proceso si no puede; en Vercel el sistema de archivos es de solo lectura
+	model.ConfigPath = "disable"
+}
+
+// Security protección de los documentos generados. Las opciones solo agregan protección:
+// al combinar las del config con las de la petición se aplica la más restrictiva.
+type Security struct {
+	// Watermark agrega la marca de agua diagonal con el nombre de quien descarga y la hora
+	Watermark     bool
+	WatermarkText string
+	// Viewer nombre de quien descarga el documento, impreso en la marca de agua
+	Viewer string
+	// Password contraseña de apertura del documento
+	Password string
+	// PasswordDNI usa el número de documento del trabajador como contraseña de apertura de su ficha
+	PasswordDNI bool
+	// OwnerPassword contraseña que levanta las restricciones; vacío genera una aleatoria
+	OwnerPassword string
+	RestrictPrint bool
+	RestrictCopy  bool
+}
+
+// Merge combina las opciones aplicando la protección más restrictiva de ambas
+func (s Security) Merge(other Security) Security {
+	merged := s
+	merged.Watermark = s.Watermark || other.Watermark
+	merged.PasswordDNI = s.PasswordDNI || other.PasswordDNI
+	merged.RestrictPrint = s.RestrictPrint || other.RestrictPrint
+	merged.RestrictCopy = s.RestrictCopy || other.RestrictCopy
+	if other.WatermarkText != "" {
+		merged.WatermarkText = other.WatermarkText
+	}
+	if other.Viewer != "" {
+		merged.Viewer = other.Viewer
+	}
+	if other.Password != "" {
+		merged.Password = other.Password
+	}
+	if other.OwnerPassword != "" {
+		merged.OwnerPassword = other.OwnerPassword
+	}
+	return merged
+}
+
+// Encrypted indica si el documento se cifra: con contraseña de apertura o con restricciones,
+// que solo se pueden aplicar cifrando
+func (s Security) Encrypted() bool {
+	return s.Password != "" || s.RestrictPrint || s.RestrictCopy
+}
+
+// WithSecurity define la protección que se aplica a todos los documentos del generador
+func (g *PDFGenerator) WithSecurity(s Security) *PDFGenerator {
+	g.security = s
+	return g
+}
+
+// protection combina la protección del generador con la de la petición
+func (g *PDFGenerator) protection(sec Security) Security {
+	return g.security.Merge(sec)
+}
+
+// CheckSecurity verifica que la protección pedida se pueda aplicar: si el generador firma
+// los documentos no se pueden cifrar, ni con contraseña ni con restricciones
+func (g *PDFGenerator) CheckSecurity(sec Security) error {
+	merged := g.protection(sec)
+	if g.signer != nil && (merged.Encrypted() || merged.PasswordDNI) {
+		return ErrSignedEncrypted
+	}
+	return nil
+}
+
+// finish aplica la marca de agua y después la firma o el cifrado. La firma PAdES no
+// sobrevive al cifrado (pdfcpu reescribe el archivo) y no se puede firmar un PDF cifrado,
+// así que un generador que firma rechaza cifrar en lugar de entregar el documento sin firma.
+func (g *PDFGenerator) finish(ctx context.Context, document []byte, info DocumentInfo, sec Security, generatedAt time.Time) ([]byte, error) {
+	if g.signer != nil && sec.Encrypted() {
+		return nil, ErrSignedEncrypted
+	}
+
+	if sec.Watermark {
+		var err error
+		document, err = watermark(document, watermarkText(sec, generatedAt))
+		if err != nil {
+			return nil, fmt.Errorf("error agregando marca de agua: %w", err)
+		}
+	}
+
+	if !sec.Encrypted() {
+		signed, err := g.sign(ctx, document, info)
+		if err != nil {
+			return nil, fmt.Errorf("error firmando PDF: %w", err)
+		}
+		return signed, nil
+	}
+
+	encrypted, err := encrypt(document, sec)
+	if err != nil {
+		return nil, fmt.Errorf("error cifrando PDF: %w", err)
+	}
+	return encrypted, nil
+}
+
+// watermarkText texto de la marca de agua: el texto configurado, quien descarga y la hora
+func watermarkText(sec Security, generatedAt time.Time) string {
+	text := sec.WatermarkText
+	if text == "" {
+		text = DefaultWatermarkText
+	}
+
+	detail := generatedAt.Format("02/01/2006 15:04")
+	if viewer := strings.TrimSpace(sec.Viewer); viewer != "" {
+		detail = viewer + " - " + detail
+	}
+	return text + "\n" + detail
+}
+
+// writeConfiguration configuración de pdfcpu que conserva la tabla xref clásica que
+// requiere la firma
+func writeConfiguration() *model.Configuration {
+	conf := model.NewDefaultConfiguration()
+	conf.WriteXRefStream = false
+	conf.WriteObjectStream = false
+	return conf
+}
+
+// watermark agrega la marca de agua a todas las páginas
+func watermark(document []byte, text string) ([]byte, error) {
+	// pdfcpu interpreta las comas como separador de opciones
+	text = strings.ReplaceAll(text, ",", " ")
+
+	wm, err := api.TextWatermark(text, watermarkDescription, false, false, types.POINTS)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := api.AddWatermarks(bytes.NewReader(document), &out, nil, wm, writeConfiguration()); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// encrypt cifra el documento con AES-256 y aplica las restricciones de impresión y copia
+func encrypt(document []byte, sec Security) ([]byte, error) {
+	ownerPassword := sec.OwnerPassword
+	if ownerPassword == "" {
+		// Sin contraseña de propietario cualquiera con la de apertura podría quitar las restricciones
+		random := make([]byte, 24)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		ownerPassword = hex.EncodeToString(random)
+	}
+
+	conf := writeConfiguration()
+	conf.UserPW = sec.Password
+	conf.OwnerPW = ownerPassword
+	conf.EncryptUsingAES = true
+	conf.EncryptKeyLength = 256
+	conf.Permissions = permissions(sec)
+
+	var out bytes.Buffer
+	if err := api.Encrypt(bytes.NewReader(document), &out, conf); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// permissions permisos del documento cifrado según las restricciones indicadas
+func permissions(sec Security) model.PermissionFlags {
+	perms := model.PermissionsAll
+	if sec.RestrictPrint {
+		perms &^= model.PermissionPrintRev2 | model.PermissionPrintRev3
+	}
+	if sec.RestrictCopy {
+		perms &^= model.PermissionExtract | model.PermissionExtractRev3
+	}
+	return perms
+}
@@ -0,0 +1,36 @@
+package pdf
+
+import "context"
+
+// Tipos de documento que se firman
+const (
+	DocumentFicha      = "ficha"
+	DocumentPadron     = "padron"
+	DocumentFotocheck  = "fotocheck"
+	DocumentCartaBanco = "carta_banco"
+)
+
+// DocumentInfo datos del documento que se registran al firmarlo
+type DocumentInfo struct {
+	Tipo      string
+	UsuarioID string
+}
+
+// Signer firma digitalmente los documentos generados
+type Signer interface {
+	Sign(ctx context.Context, document []byte, info DocumentInfo) ([]byte, error)
+}
+
+// WithSigner firma los documentos generados después de crearlos
+func (g *PDFGenerator) WithSigner(s Signer) *PDFGenerator {
+	g.signer = s
+	return g
+}
+
+// sign firma el documento si hay un firmador configurado
+func (g *PDFGenerator) sign(ctx context.Context, document []byte, info DocumentInfo) ([]byte, error) {
+	if g.signer == nil {
+		return document, nil
+	}
+	return g.signer.Sign(ctx, document, info)
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+)
+
+// rateLimitCollection colección con el estado de los buckets
+const rateLimitCollection = "rate_limits"
+
+// FirestoreStore almacena los buckets en Firestore para compartirlos entre instancias
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+// NewFirestoreStore crea un store respaldado por Firestore
+func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
+	return &FirestoreStore{
+		client: client,
+	}
+}
+
+// Take consume un token del bucket de la clave dentro de una transacción
+func (s *FirestoreStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	sum := sha256.Sum256([]byte(key))
+	ref := s.client.Collection(rateLimitCollection).Doc(hex.EncodeToString(sum[:]))
+
+	var result Result
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var b bucket
+
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			if err := doc.DataTo(&b); err != nil {
+				return err
+			}
+		}
+
+		result = b.take(time.Now(), limit)
+		return tx.Set(ref, &b)
+	})
+	if err != nil {
+		metrics.FirestoreError("rate_limit")
+		return Result{}, fmt.Errorf("error consultando rate limit: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxIdle tiempo tras el cual se descartan los buckets sin uso
+const maxIdle = 10 * time.Minute
+
+// MemoryStore almacena los buckets en memoria del proceso
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore crea un store en memoria vacío
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Take consume un token del bucket de la clave
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{}
+		s.buckets[key] = b
+	}
+
+	return b.take(now, limit), nil
+}
+
+// sweep elimina los buckets inactivos para que el mapa no crezca sin límite
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < maxIdle {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.Last) > maxIdle {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// Limit parámetros del token bucket
+type Limit struct {
+	// Rate tokens recargados por segundo
+	Rate float64
+	// Burst capacidad máxima del bucket
+	Burst int
+}
+
+// PerMinute crea un límite a partir de peticiones por minuto
+func PerMinute(requests, burst int) Limit {
+	return Limit{
+		Rate:  float64(requests) / 60,
+		Burst: burst,
+	}
+}
+
+// Result resultado de consumir un token
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store almacena el estado de los buckets. Permite compartir el estado entre
+// instancias (por ejemplo en Firestore) o mantenerlo en memoria.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Limiter aplica un límite por clave sobre un Store
+type Limiter struct {
+	store Store
+	limit Limit
+}
+
+// New crea un nuevo limitador
+func New(store Store, limit Limit) *Limiter {
+	return &Limiter{
+		store: store,
+		limit: limit,
+	}
+}
+
+// Allow consume un token para la clave indicada
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.store.Take(ctx, key, l.limit)
+}
+
+// Limit devuelve el límite configurado
+func (l *Limiter) Limit() Limit {
+	return l.limit
+}
+
+// bucket estado de un token bucket
+type bucket struct {
+	Tokens float64   `firestore:"tokens"`
+	Last   time.Time `firestore:"last"`
+}
+
+// take recarga el bucket según el tiempo transcurrido e intenta consumir un token
+func (b *bucket) take(now time.Time, limit Limit) Result {
+	if b.Last.IsZero() {
+		b.Tokens = float64(limit.Burst)
+	} else if elapsed := now.Sub(b.Last).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed*limit.Rate)
+	}
+	b.Last = now
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return Result{Allowed: true, Remaining: int(b.Tokens)}
+	}
+
+	wait := time.Duration((1 - b.Tokens) / limit.Rate * float64(time.Second))
+	return Result{Allowed: false, RetryAfter: wait}
+}
+
+// Proxies proxies delante de la API. Los headers X-Real-IP y X-Forwarded-For los puede
+// enviar cualquier cliente, así que solo se creen si la conexión viene de un proxy conocido.
+type Proxies struct {
+	// Trusted rangos de los proxies de confianza
+	Trusted []netip.Prefix
+	// Edge la API solo recibe peticiones de un borde que reescribe los headers, como Vercel
+	Edge bool
+}
+
+// ParseProxies convierte las IPs o rangos CIDR de los proxies de confianza
+func ParseProxies(trusted []string, edge bool) (Proxies, error) {
+	proxies := Proxies{Edge: edge}
+	for _, value := range trusted {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(value)
+			if addrErr != nil {
+				return Proxies{}, fmt.Errorf("proxy %q no es una IP ni un rango CIDR", value)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		proxies.Trusted = append(proxies.Trusted, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// trusted indica si la dirección es de un proxy de confianza
+func (p Proxies) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p.Trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP obtiene la IP del cliente. En un borde como Vercel se toman X-Real-IP y
+// X-Forwarded-For, que establece el proxy. Detrás de proxies de confianza se recorre
+// X-Forwarded-For desde el final y se toma la primera IP que no es de un proxy. En otro
+// caso se usa la dirección de la conexión.
+func (p Proxies) ClientIP(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+
+	if p.Edge {
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+		return remote
+	}
+
+	if !p.trusted(remote) {
+		return remote
+	}
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(hops[i])
+		if ip == "" {
+			break
+		}
+		if !p.trusted(ip) {
+			return ip
+		}
+		remote = ip
+	}
+	return remote
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// aperturasCollection colección del seguimiento de las aperturas de cuenta; el ID del
+// documento es el de models.AperturaID
+const aperturasCollection = "aperturas_cuenta"
+
+// ErrAperturaNotFound la apertura no existe
+var ErrAperturaNotFound = errors.New("apertura de cuenta no encontrada")
+
+// AperturaRepository define las operaciones de persistencia de las aperturas de cuenta
+type AperturaRepository interface {
+	// Request guarda las aperturas que aún no existen y devuelve el registro vigente de cada
+	// una en el mismo orden; las que ya existen no se modifican
+	Request(ctx context.Context, aperturas []*models.AperturaCuenta) ([]*models.AperturaCuenta, error)
+	// Advance aplica el nuevo estado a la apertura dentro de una transacción
+	Advance(ctx context.Context, req *models.UpdateAperturaRequest, now time.Time) (*models.AperturaCuenta, error)
+	// List devuelve las aperturas que cumplen con los filtros, las más recientes primero
+	List(ctx context.Context, filter models.AperturaFilter) ([]*models.AperturaCuenta, error)
+}
+
+// FirestoreAperturaRepository repositorio de aperturas de cuenta en Firestore
+type FirestoreAperturaRepository struct {
+	client *firestore.Client
+}
+
+// NewAperturaRepository crea una nueva instancia del repositorio
+func NewAperturaRepository(client *firestore.Client) *FirestoreAperturaRepository {
+	return &FirestoreAperturaRepository{
+		client: client,
+	}
+}
+
+// Request lee las aperturas existentes y crea las faltantes con escrituras en lote. Si otra
+// petición crea la misma apertura entre la lectura y la escritura se conserva la suya.
+func (r *FirestoreAperturaRepository) Request(ctx context.Context, aperturas []*models.AperturaCuenta) ([]*models.AperturaCuenta, error) {
+	if len(aperturas) == 0 {
+		return nil, nil
+	}
+
+	collection := r.client.Collection(aperturasCollection)
+	refs := make([]*firestore.DocumentRef, len(aperturas))
+	for i, a := range aperturas {
+		refs[i] = collection.Doc(a.ID)
+	}
+
+	docs, err := r.client.GetAll(ctx, refs)
+	if err != nil {
+		metrics.FirestoreError("apertura_request")
+		return nil, fmt.Errorf("error obteniendo aperturas: %w", err)
+	}
+
+	result := make([]*models.AperturaCuenta, len(aperturas))
+	bw := r.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(aperturas))
+	for i, doc := range docs {
+		if doc.Exists() {
+			if result[i], err = aperturaFromDoc(doc); err != nil {
+				bw.End()
+				return nil, err
+			}
+			continue
+		}
+		if jobs[i], err = bw.Create(refs[i], aperturas[i]); err != nil {
+			bw.End()
+			return nil, fmt.Errorf("error encolando apertura: %w", err)
+		}
+		result[i] = aperturas[i]
+	}
+	bw.End()
+
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+		if _, err := job.Results(); err != nil && status.Code(err) != codes.AlreadyExists {
+			metrics.FirestoreError("apertura_request")
+			return nil, fmt.Errorf("error guardando apertura %s: %w", aperturas[i].ID, err)
+		}
+	}
+	return result, nil
+}
+
+// Advance lee la apertura y guarda el nuevo estado en una transacción para que dos
+// actualizaciones simultáneas no se salten un estado
+func (r *FirestoreAperturaRepository) Advance(ctx context.Context, req *models.UpdateAperturaRequest, now time.Time) (*models.AperturaCuenta, error) {
+	ref := r.client.Collection(aperturasCollection).Doc(req.AperturaID())
+
+	var apertura *models.AperturaCuenta
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrAperturaNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if apertura, err = aperturaFromDoc(doc); err != nil {
+			return err
+		}
+		if err := apertura.Advance(req, now); err != nil {
+			return err
+		}
+		return tx.Set(ref, apertura)
+	})
+	if err != nil {
+		var vErr *models.ValidationError
+		if errors.Is(err, ErrAperturaNotFound) || errors.As(err, &vErr) {
+			return nil, err
+		}
+		metrics.FirestoreError("apertura_advance")
+		return nil, fmt.Errorf("error actualizando apertura: %w", err)
+	}
+	return apertura, nil
+}
+
+// List devuelve las aperturas que cumplen con los filtros.
+// Los filtros combinados con el orden por solicitada_at requieren los índices de firestore.indexes.json.
+func (r *FirestoreAperturaRepository) List(ctx context.Context, filter models.AperturaFilter) ([]*models.AperturaCuenta, error) {
+	query := r.client.Collection(aperturasCollection).Query
+	if filter.Tipo != "" {
+		query = query.Where("tipo", "==", filter.Tipo)
+	}
+	if filter.Estado != "" {
+		query = query.Where("estado", "==", filter.Estado)
+	}
+	if filter.Banco != "" {
+		query = query.Where("banco", "==", filter.Banco)
+	}
+	if filter.Dni != "" {
+		query = query.Where("dni", "==", filter.Dni)
+	}
+	query = query.OrderBy("solicitada_at", firestore.Desc)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		metrics.FirestoreError("apertura_list")
+		return nil, fmt.Errorf("error listando aperturas: %w", err)
+	}
+
+	aperturas := make([]*models.AperturaCuenta, 0, len(docs))
+	for _, doc := range docs {
+		a, err := aperturaFromDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		aperturas = append(aperturas, a)
+	}
+	return aperturas, nil
+}
+
+func aperturaFromDoc(doc *firestore.DocumentSnapshot) (*models.AperturaCuenta, error) {
+	var a models.AperturaCuenta
+	if err := doc.DataTo(&a); err != nil {
+		return nil, fmt.Errorf("error parseando apertura: %w", err)
+	}
+	a.ID = doc.Ref.ID
+	return &a, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// auditCollection colección de la auditoría; los registros solo se agregan
+const auditCollection = "auditoria"
+
+// AuditRepository registra los eventos de la auditoría
+type AuditRepository interface {
+	Record(ctx context.Context, event *models.AuditEvent) error
+}
+
+// FirestoreAuditRepository auditoría en Firestore
+type FirestoreAuditRepository struct {
+	client *firestore.Client
+}
+
+// NewAuditRepository crea una nueva instancia del repositorio
+func NewAuditRepository(client *firestore.Client) *FirestoreAuditRepository {
+	return &FirestoreAuditRepository{
+		client: client,
+	}
+}
+
+// Record guarda un evento con un ID generado por Firestore
+func (r *FirestoreAuditRepository) Record(ctx context.Context, event *models.AuditEvent) error {
+	ref := r.client.Collection(auditCollection).NewDoc()
+	if _, err := ref.Create(ctx, event); err != nil {
+		metrics.FirestoreError("audit_record")
+		return fmt.Errorf("error registrando auditoría: %w", err)
+	}
+	event.ID = ref.ID
+	return nil
+}
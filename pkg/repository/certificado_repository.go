@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+const (
+	// certificadosCollection colección de los certificados y constancias emitidos
+	certificadosCollection = "certificados"
+	// correlativosCollection contadores de los correlativos; un documento por tipo, año y tenant
+	correlativosCollection = "correlativos"
+)
+
+// CertificadoRepository define las operaciones de persistencia de los certificados emitidos
+type CertificadoRepository interface {
+	// Create asigna el siguiente correlativo y guarda el certificado en la misma operación
+	Create(ctx context.Context, cert *models.Certificado) error
+	// List devuelve los certificados del tenant, los más recientes primero
+	List(ctx context.Context, filter models.CertificadoFilter) ([]*models.Certificado, error)
+}
+
+// correlativo documento del contador de un correlativo
+type correlativo struct {
+	Ultimo    int       `firestore:"ultimo"`
+	UpdatedAt time.Time `firestore:"updated_at"`
+}
+
+// FirestoreCertificadoRepository repositorio de certificados en Firestore
+type FirestoreCertificadoRepository struct {
+	client *firestore.Client
+}
+
+// NewCertificadoRepository crea una nueva instancia del repositorio
+func NewCertificadoRepository(client *firestore.Client) *FirestoreCertificadoRepository {
+	return &FirestoreCertificadoRepository{
+		client: client,
+	}
+}
+
+// Create incrementa el contador y guarda el certificado dentro de una transacción para
+// que dos emisiones simultáneas no reciban el mismo número
+func (r *FirestoreCertificadoRepository) Create(ctx context.Context, cert *models.Certificado) error {
+	counterRef := r.client.Collection(correlativosCollection).Doc(cert.CorrelativoKey())
+	certRef := r.client.Collection(certificadosCollection).NewDoc()
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var counter correlativo
+		doc, err := tx.Get(counterRef)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return err
+		default:
+			if err := doc.DataTo(&counter); err != nil {
+				return err
+			}
+		}
+
+		counter.Ultimo++
+		counter.UpdatedAt = cert.CreatedAt
+		cert.SetNumero(counter.Ultimo)
+
+		if err := tx.Set(counterRef, counter); err != nil {
+			return err
+		}
+		return tx.Create(certRef, cert)
+	})
+	if err != nil {
+		metrics.FirestoreError("certificado_create")
+		return fmt.Errorf("error registrando certificado: %w", err)
+	}
+	cert.ID = certRef.ID
+	return nil
+}
+
+// List devuelve los certificados que cumplen con los filtros.
+// Los filtros combinados con el orden por created_at requieren los índices de firestore.indexes.json.
+func (r *FirestoreCertificadoRepository) List(ctx context.Context, filter models.CertificadoFilter) ([]*models.Certificado, error) {
+	query := r.client.Collection(certificadosCollection).Where("tenant", "==", filter.Tenant)
+	if filter.Tipo != "" {
+		query = query.Where("tipo", "==", filter.Tipo)
+	}
+	if filter.Dni != "" {
+		query = query.Where("dni", "==", filter.Dni)
+	}
+	query = query.OrderBy("created_at", firestore.Desc)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		metrics.FirestoreError("certificado_list")
+		return nil, fmt.Errorf("error listando certificados: %w", err)
+	}
+
+	certs := make([]*models.Certificado, 0, len(docs))
+	for _, doc := range docs {
+		var cert models.Certificado
+		if err := doc.DataTo(&cert); err != nil {
+			return nil, fmt.Errorf("error parseando certificado: %w", err)
+		}
+		cert.ID = doc.Ref.ID
+		certs = append(certs, &cert)
+	}
+	return certs, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+)
+
+// idempotencyCollection colección de claves de idempotencia. Se recomienda
+// configurar una política TTL de Firestore sobre el campo expires_at.
+const idempotencyCollection = "idempotency_keys"
+
+// ErrIdempotencyLeaseLost la reserva de la clave venció y la tomó otra petición
+var ErrIdempotencyLeaseLost = errors.New("la reserva de la clave de idempotencia venció")
+
+// IdempotencyRecord respuesta almacenada para una clave de idempotencia. Owner identifica la
+// petición que reservó la clave, la única que puede completarla o liberarla. ExpiresAt es el
+// fin de la reserva mientras la petición está en proceso y el de la respuesta una vez completada.
+type IdempotencyRecord struct {
+	Key         string    `firestore:"key"`
+	RequestHash string    `firestore:"request_hash"`
+	Owner       string    `firestore:"owner"`
+	Completed   bool      `firestore:"completed"`
+	StatusCode  int       `firestore:"status_code"`
+	Body        []byte    `firestore:"body"`
+	CreatedAt   time.Time `firestore:"created_at"`
+	ExpiresAt   time.Time `firestore:"expires_at"`
+}
+
+// Expired indica si el registro ya venció; una reserva vencida la puede tomar otra petición
+func (r *IdempotencyRecord) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// IdempotencyRepository almacena las respuestas asociadas a claves de idempotencia
+type IdempotencyRepository interface {
+	// Reserve marca la clave como en proceso por owner durante lease. Si la clave ya existe y
+	// no ha vencido devuelve el registro existente sin modificarlo.
+	Reserve(ctx context.Context, key, requestHash, owner string, lease time.Duration) (*IdempotencyRecord, error)
+	// Complete guarda la respuesta final asociada a la clave y la conserva durante ttl.
+	// Devuelve ErrIdempotencyLeaseLost si la reserva ya no es de owner.
+	Complete(ctx context.Context, key, owner string, statusCode int, body []byte, ttl time.Duration) error
+	// Release libera una clave reservada para que la petición se pueda reintentar; no hace
+	// nada si la reserva ya no es de owner
+	Release(ctx context.Context, key, owner string) error
+}
+
+// FirestoreIdempotencyRepository repositorio de idempotencia en Firestore
+type FirestoreIdempotencyRepository struct {
+	client *firestore.Client
+}
+
+// NewIdempotencyRepository crea una nueva instancia del repositorio
+func NewIdempotencyRepository(client *firestore.Client) *FirestoreIdempotencyRepository {
+	return &FirestoreIdempotencyRepository{
+		client: client,
+	}
+}
+
+// docRef devuelve la referencia del documento; la clave se hashea porque puede contener "/"
+func (r *FirestoreIdempotencyRepository) docRef(key string) *firestore.DocumentRef {
+	sum := sha256.Sum256([]byte(key))
+	return r.client.Collection(idempotencyCollection).Doc(hex.EncodeToString(sum[:]))
+}
+
+// Reserve marca la clave como en proceso
+func (r *FirestoreIdempotencyRepository) Reserve(ctx context.Context, key, requestHash, owner string, lease time.Duration) (*IdempotencyRecord, error) {
+	now := time.Now()
+	record := &IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		Owner:       owner,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(lease),
+	}
+
+	ref := r.docRef(key)
+	var existing *IdempotencyRecord
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		existing = nil
+
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			var stored IdempotencyRecord
+			if err := doc.DataTo(&stored); err != nil {
+				return err
+			}
+			if !stored.Expired(now) {
+				existing = &stored
+				return nil
+			}
+		}
+
+		return tx.Set(ref, record)
+	})
+	if err != nil {
+		metrics.FirestoreError("idempotency_reserve")
+		return nil, fmt.Errorf("error reservando clave de idempotencia: %w", err)
+	}
+
+	return existing, nil
+}
+
+// Complete guarda la respuesta final asociada a la clave
+func (r *FirestoreIdempotencyRepository) Complete(ctx context.Context, key, owner string, statusCode int, body []byte, ttl time.Duration) error {
+	ref := r.docRef(key)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := r.checkOwner(tx, ref, owner); err != nil {
+			return err
+		}
+		return tx.Update(ref, []firestore.Update{
+			{Path: "completed", Value: true},
+			{Path: "status_code", Value: statusCode},
+			{Path: "body", Value: body},
+			{Path: "expires_at", Value: time.Now().Add(ttl)},
+		})
+	})
+	if err != nil {
+		if !errors.Is(err, ErrIdempotencyLeaseLost) {
+			metrics.FirestoreError("idempotency_complete")
+		}
+		return fmt.Errorf("error guardando respuesta idempotente: %w", err)
+	}
+	return nil
+}
+
+// Release libera una clave reservada
+func (r *FirestoreIdempotencyRepository) Release(ctx context.Context, key, owner string) error {
+	ref := r.docRef(key)
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := r.checkOwner(tx, ref, owner); err != nil {
+			if errors.Is(err, ErrIdempotencyLeaseLost) {
+				return nil
+			}
+			return err
+		}
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		metrics.FirestoreError("idempotency_release")
+		return fmt.Errorf("error liberando clave de idempotencia: %w", err)
+	}
+	return nil
+}
+
+// checkOwner verifica dentro de la transacción que la reserva siga siendo de owner
+func (r *FirestoreIdempotencyRepository) checkOwner(tx *firestore.Transaction, ref *firestore.DocumentRef, owner string) error {
+	doc, err := tx.Get(ref)
+	if status.Code(err) == codes.NotFound {
+		return ErrIdempotencyLeaseLost
+	}
+	if err != nil {
+		return err
+	}
+	var stored IdempotencyRecord
+	if err := doc.DataTo(&stored); err != nil {
+		return err
+	}
+	if stored.Owner != owner || stored.Completed {
+		return ErrIdempotencyLeaseLost
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// jobsCollection colección de trabajos de reportes. Se recomienda configurar una
+// política TTL de Firestore sobre el campo expires_at.
+const jobsCollection = "report_jobs"
+
+// ErrJobNotFound el trabajo no existe
+var ErrJobNotFound = errors.New("trabajo no encontrado")
+
+// JobRepository define las operaciones de persistencia de los trabajos de reportes
+type JobRepository interface {
+	Create(ctx context.Context, job *models.Job) error
+	Get(ctx context.Context, id string) (*models.Job, error)
+	Update(ctx context.Context, job *models.Job) error
+	// Claim toma el trabajo en cola más antiguo y lo marca en ejecución; devuelve nil si no hay
+	Claim(ctx context.Context, now time.Time) (*models.Job, error)
+	// ListStale devuelve los trabajos en ejecución iniciados antes de before
+	ListStale(ctx context.Context, before time.Time) ([]*models.Job, error)
+}
+
+// FirestoreJobRepository repositorio de trabajos en Firestore
+type FirestoreJobRepository struct {
+	client *firestore.Client
+}
+
+// NewJobRepository crea una nueva instancia del repositorio
+func NewJobRepository(client *firestore.Client) *FirestoreJobRepository {
+	return &FirestoreJobRepository{
+		client: client,
+	}
+}
+
+// Create guarda un nuevo trabajo
+func (r *FirestoreJobRepository) Create(ctx context.Context, job *models.Job) error {
+	ref := r.client.Collection(jobsCollection).NewDoc()
+	if _, err := ref.Create(ctx, job); err != nil {
+		metrics.FirestoreError("job_create")
+		return fmt.Errorf("error creando trabajo: %w", err)
+	}
+	job.ID = ref.ID
+	return nil
+}
+
+// Get obtiene un trabajo por su ID
+func (r *FirestoreJobRepository) Get(ctx context.Context, id string) (*models.Job, error) {
+	doc, err := r.client.Collection(jobsCollection).Doc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		metrics.FirestoreError("job_get")
+		return nil, fmt.Errorf("error obteniendo trabajo: %w", err)
+	}
+	return jobFromDoc(doc)
+}
+
+// Update guarda el estado completo del trabajo
+func (r *FirestoreJobRepository) Update(ctx context.Context, job *models.Job) error {
+	if _, err := r.client.Collection(jobsCollection).Doc(job.ID).Set(ctx, job); err != nil {
+		metrics.FirestoreError("job_update")
+		return fmt.Errorf("error actualizando trabajo: %w", err)
+	}
+	return nil
+}
+
+// Claim toma el trabajo en cola más antiguo dentro de una transacción para que
+// dos workers no ejecuten el mismo trabajo
+func (r *FirestoreJobRepository) Claim(ctx context.Context, now time.Time) (*models.Job, error) {
+	query := r.client.Collection(jobsCollection).
+		Where("estado", "==", models.JobQueued).
+		OrderBy("created_at", firestore.Asc).
+		Limit(1)
+
+	var claimed *models.Job
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		claimed = nil
+
+		docs, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+
+		job, err := jobFromDoc(docs[0])
+		if err != nil {
+			return err
+		}
+		job.Estado = models.JobRunning
+		job.Intentos++
+		job.StartedAt = &now
+
+		if err := tx.Set(docs[0].Ref, job); err != nil {
+			return err
+		}
+		claimed = job
+		return nil
+	})
+	if err != nil {
+		metrics.FirestoreError("job_claim")
+		return nil, fmt.Errorf("error tomando trabajo: %w", err)
+	}
+	return claimed, nil
+}
+
+// ListStale devuelve los trabajos en ejecución iniciados antes de before
+func (r *FirestoreJobRepository) ListStale(ctx context.Context, before time.Time) ([]*models.Job, error) {
+	docs, err := r.client.Collection(jobsCollection).
+		Where("estado", "==", models.JobRunning).
+		Where("started_at", "<", before).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		metrics.FirestoreError("job_list_stale")
+		return nil, fmt.Errorf("error listando trabajos: %w", err)
+	}
+
+	jobs := make([]*models.Job, 0, len(docs))
+	for _, doc := range docs {
+		job, err := jobFromDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func jobFromDoc(doc *firestore.DocumentSnapshot) (*models.Job, error) {
+	var job models.Job
+	if err := doc.DataTo(&job); err != nil {
+		return nil, fmt.Errorf("error parseando trabajo: %w", err)
+	}
+	job.ID = doc.Ref.ID
+	return &job, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// MemoryAperturaRepository aperturas de cuenta en memoria para desarrollo local
+type MemoryAperturaRepository struct {
+	mu        sync.Mutex
+	aperturas map[string]models.AperturaCuenta
+}
+
+// NewMemoryAperturaRepository crea un repositorio de aperturas vacío
+func NewMemoryAperturaRepository() *MemoryAperturaRepository {
+	return &MemoryAperturaRepository{
+		aperturas: make(map[string]models.AperturaCuenta),
+	}
+}
+
+// Request guarda las aperturas que aún no existen y devuelve el registro vigente de cada una
+func (r *MemoryAperturaRepository) Request(ctx context.Context, aperturas []*models.AperturaCuenta) ([]*models.AperturaCuenta, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*models.AperturaCuenta, len(aperturas))
+	for i, a := range aperturas {
+		if existing, ok := r.aperturas[a.ID]; ok {
+			result[i] = &existing
+			continue
+		}
+		r.aperturas[a.ID] = *a
+		result[i] = a
+	}
+	return result, nil
+}
+
+// Advance aplica el nuevo estado a la apertura
+func (r *MemoryAperturaRepository) Advance(ctx context.Context, req *models.UpdateAperturaRequest, now time.Time) (*models.AperturaCuenta, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.aperturas[req.AperturaID()]
+	if !ok {
+		return nil, ErrAperturaNotFound
+	}
+	if err := a.Advance(req, now); err != nil {
+		return nil, err
+	}
+	r.aperturas[a.ID] = a
+	return &a, nil
+}
+
+// List devuelve las aperturas que cumplen con los filtros, las más recientes primero
+func (r *MemoryAperturaRepository) List(ctx context.Context, filter models.AperturaFilter) ([]*models.AperturaCuenta, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var aperturas []*models.AperturaCuenta
+	for _, a := range r.aperturas {
+		if filter.Matches(&a) {
+			a := a
+			aperturas = append(aperturas, &a)
+		}
+	}
+
+	sort.SliceStable(aperturas, func(i, j int) bool {
+		if aperturas[i].SolicitadaAt.Equal(aperturas[j].SolicitadaAt) {
+			return aperturas[i].ID < aperturas[j].ID
+		}
+		return aperturas[i].SolicitadaAt.After(aperturas[j].SolicitadaAt)
+	})
+	if filter.Limit > 0 && len(aperturas) > filter.Limit {
+		aperturas = aperturas[:filter.Limit]
+	}
+	return aperturas, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// MemoryAuditRepository auditoría en memoria para desarrollo local
+type MemoryAuditRepository struct {
+	mu     sync.Mutex
+	events []models.AuditEvent
+}
+
+// NewMemoryAuditRepository crea una auditoría vacía
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+// Record guarda un evento
+func (r *MemoryAuditRepository) Record(ctx context.Context, event *models.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.ID = fmt.Sprintf("audit-%d", len(r.events)+1)
+	r.events = append(r.events, *event)
+	return nil
+}
+
+// Events devuelve una copia de los eventos registrados
+func (r *MemoryAuditRepository) Events() []models.AuditEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]models.AuditEvent(nil), r.events...)
+}
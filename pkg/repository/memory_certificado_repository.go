@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// MemoryCertificadoRepository certificados en memoria para desarrollo local
+type MemoryCertificadoRepository struct {
+	mu           sync.Mutex
+	certs        []models.Certificado
+	correlativos map[string]int
+}
+
+// NewMemoryCertificadoRepository crea un repositorio de certificados vacío
+func NewMemoryCertificadoRepository() *MemoryCertificadoRepository {
+	return &MemoryCertificadoRepository{
+		correlativos: make(map[string]int),
+	}
+}
+
+// Create asigna el siguiente correlativo y guarda el certificado
+func (r *MemoryCertificadoRepository) Create(ctx context.Context, cert *models.Certificado) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := cert.CorrelativoKey()
+	r.correlativos[key]++
+	cert.SetNumero(r.correlativos[key])
+	cert.ID = fmt.Sprintf("cert-%d", len(r.certs)+1)
+	r.certs = append(r.certs, *cert)
+	return nil
+}
+
+// List devuelve los certificados que cumplen con los filtros, los más recientes primero
+func (r *MemoryCertificadoRepository) List(ctx context.Context, filter models.CertificadoFilter) ([]*models.Certificado, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var certs []*models.Certificado
+	for _, cert := range r.certs {
+		if filter.Matches(&cert) {
+			cert := cert
+			certs = append(certs, &cert)
+		}
+	}
+
+	sort.SliceStable(certs, func(i, j int) bool {
+		return certs[i].CreatedAt.After(certs[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(certs) > filter.Limit {
+		certs = certs[:filter.Limit]
+	}
+	return certs, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryIdempotencyRepository repositorio de idempotencia en memoria para desarrollo local
+type MemoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+// NewMemoryIdempotencyRepository crea un repositorio de idempotencia en memoria vacío
+func NewMemoryIdempotencyRepository() *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{
+		records: make(map[string]IdempotencyRecord),
+	}
+}
+
+// Reserve marca la clave como en proceso. Aprovecha para descartar los registros vencidos,
+// que en Firestore elimina la política TTL.
+func (r *MemoryIdempotencyRepository) Reserve(ctx context.Context, key, requestHash, owner string, lease time.Duration) (*IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for k, stored := range r.records {
+		if stored.Expired(now) {
+			delete(r.records, k)
+		}
+	}
+
+	if stored, ok := r.records[key]; ok {
+		return &stored, nil
+	}
+
+	r.records[key] = IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		Owner:       owner,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(lease),
+	}
+	return nil, nil
+}
+
+// Complete guarda la respuesta final asociada a la clave
+func (r *MemoryIdempotencyRepository) Complete(ctx context.Context, key, owner string, statusCode int, body []byte, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[key]
+	if !ok || record.Owner != owner || record.Completed {
+		return fmt.Errorf("clave de idempotencia %s: %w", key, ErrIdempotencyLeaseLost)
+	}
+	record.Completed = true
+	record.StatusCode = statusCode
+	record.Body = body
+	record.ExpiresAt = time.Now().Add(ttl)
+	r.records[key] = record
+	return nil
+}
+
+// Release libera una clave reservada
+func (r *MemoryIdempotencyRepository) Release(ctx context.Context, key, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record, ok := r.records[key]; ok && record.Owner == owner && !record.Completed {
+		delete(r.records, key)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// MemoryJobRepository repositorio de trabajos en memoria para desarrollo local
+type MemoryJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]models.Job
+}
+
+// NewMemoryJobRepository crea un repositorio de trabajos vacío
+func NewMemoryJobRepository() *MemoryJobRepository {
+	return &MemoryJobRepository{
+		jobs: make(map[string]models.Job),
+	}
+}
+
+// Create guarda un nuevo trabajo con un ID aleatorio
+func (r *MemoryJobRepository) Create(ctx context.Context, job *models.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := make([]byte, 10)
+	rand.Read(id)
+	job.ID = hex.EncodeToString(id)
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+// Get obtiene un trabajo por su ID
+func (r *MemoryJobRepository) Get(ctx context.Context, id string) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return &job, nil
+}
+
+// Update guarda el estado completo del trabajo
+func (r *MemoryJobRepository) Update(ctx context.Context, job *models.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.ID]; !ok {
+		return ErrJobNotFound
+	}
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+// Claim toma el trabajo en cola más antiguo y lo marca en ejecución
+func (r *MemoryJobRepository) Claim(ctx context.Context, now time.Time) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var oldest *models.Job
+	for _, job := range r.jobs {
+		if job.Estado != models.JobQueued {
+			continue
+		}
+		if oldest == nil || job.CreatedAt.Before(oldest.CreatedAt) {
+			job := job
+			oldest = &job
+		}
+	}
+	if oldest == nil {
+		return nil, nil
+	}
+
+	oldest.Estado = models.JobRunning
+	oldest.Intentos++
+	oldest.StartedAt = &now
+	r.jobs[oldest.ID] = *oldest
+	return oldest, nil
+}
+
+// ListStale devuelve los trabajos en ejecución iniciados antes de before
+func (r *MemoryJobRepository) ListStale(ctx context.Context, before time.Time) ([]*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var jobs []*models.Job
+	for _, job := range r.jobs {
+		if job.Estado == models.JobRunning && job.StartedAt != nil && job.StartedAt.Before(before) {
+			job := job
+			jobs = append(jobs, &job)
+		}
+	}
+	return jobs, nil
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// MemoryUsuarioRepository repositorio en memoria para desarrollo local.
+// Los datos se pierden al reiniciar el proceso.
+type MemoryUsuarioRepository struct {
+	mu       sync.RWMutex
+	usuarios map[string]models.Usuario
+	nextID   int
+}
+
+// NewMemoryUsuarioRepository crea un repositorio en memoria vacío
+func NewMemoryUsuarioRepository() *MemoryUsuarioRepository {
+	return &MemoryUsuarioRepository{
+		usuarios: make(map[string]models.Usuario),
+	}
+}
+
+// Create guarda un nuevo usuario en memoria
+func (r *MemoryUsuarioRepository) Create(ctx context.Context, usuario *models.Usuario) (*models.Usuario, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkDocumento(usuario.Documento(), ""); err != nil {
+		return nil, err
+	}
+
+	r.nextID++
+	usuario.ID = "mem-" + strconv.Itoa(r.nextID)
+	r.usuarios[usuario.ID] = *usuario
+
+	return usuario, nil
+}
+
+// checkDocumento verifica que ningún otro usuario tenga el documento; se llama con el lock tomado
+func (r *MemoryUsuarioRepository) checkDocumento(doc models.Documento, id string) error {
+	for _, usuario := range r.usuarios {
+		if usuario.ID != id && usuario.Documento() == doc {
+			return fmt.Errorf("%w: %s", ErrDocumentoRegistrado, doc)
+		}
+	}
+	return nil
+}
+
+// GetByID obtiene un usuario por su ID
+func (r *MemoryUsuarioRepository) GetByID(ctx context.Context, id string) (*models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	usuario, ok := r.usuarios[id]
+	if !ok {
+		return nil, fmt.Errorf("usuario %s no encontrado", id)
+	}
+	return &usuario, nil
+}
+
+// GetByDocumento obtiene un usuario por su tipo y número de documento
+func (r *MemoryUsuarioRepository) GetByDocumento(ctx context.Context, doc models.Documento) (*models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, usuario := range r.usuarios {
+		if usuario.Documento() == doc {
+			return &usuario, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUsuarioNotFound, doc)
+}
+
+// GetAll obtiene los usuarios que cumplen con los filtros ordenados por fecha de creación descendente
+func (r *MemoryUsuarioRepository) GetAll(ctx context.Context, filter models.UsuarioFilter) ([]*models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	usuarios := make([]*models.Usuario, 0, len(r.usuarios))
+	for _, usuario := range r.usuarios {
+		usuario := usuario
+		if filter.Matches(&usuario) {
+			usuarios = append(usuarios, &usuario)
+		}
+	}
+
+	sort.Slice(usuarios, func(i, j int) bool {
+		return usuarios[i].CreatedAt.After(usuarios[j].CreatedAt)
+	})
+
+	if filter.Limit > 0 && len(usuarios) > filter.Limit {
+		usuarios = usuarios[:filter.Limit]
+	}
+	return usuarios, nil
+}
+
+// Stream llama a fn por cada usuario que cumple con los filtros, en el mismo orden que GetAll
+func (r *MemoryUsuarioRepository) Stream(ctx context.Context, filter models.UsuarioFilter, fn func(*models.Usuario) error) error {
+	usuarios, err := r.GetAll(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, usuario := range usuarios {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(usuario); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Page devuelve hasta limit usuarios ordenados por ID a partir del siguiente a afterID
+func (r *MemoryUsuarioRepository) Page(ctx context.Context, afterID string, limit int) ([]*models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	usuarios := make([]*models.Usuario, 0, limit)
+	for _, usuario := range r.usuarios {
+		if usuario.ID > afterID {
+			usuario := usuario
+			usuarios = append(usuarios, &usuario)
+		}
+	}
+	sort.Slice(usuarios, func(i, j int) bool {
+		return usuarios[i].ID < usuarios[j].ID
+	})
+
+	if len(usuarios) > limit {
+		usuarios = usuarios[:limit]
+	}
+	return usuarios, nil
+}
+
+// GetByDocumentos obtiene los usuarios registrados con alguno de los documentos indicados,
+// indexados por documento
+func (r *MemoryUsuarioRepository) GetByDocumentos(ctx context.Context, docs []models.Documento) (map[models.Documento]*models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[models.Documento]bool, len(docs))
+	for _, doc := range docs {
+		wanted[doc] = true
+	}
+
+	usuarios := make(map[models.Documento]*models.Usuario)
+	for _, usuario := range r.usuarios {
+		if wanted[usuario.Documento()] {
+			usuario := usuario
+			usuarios[usuario.Documento()] = &usuario
+		}
+	}
+	return usuarios, nil
+}
+
+// SaveAll crea los usuarios sin ID y reemplaza los existentes
+func (r *MemoryUsuarioRepository) SaveAll(ctx context.Context, usuarios []*models.Usuario) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := make([]error, len(usuarios))
+	for i, usuario := range usuarios {
+		if err := r.checkDocumento(usuario.Documento(), usuario.ID); err != nil {
+			errs[i] = err
+			continue
+		}
+		if usuario.ID == "" {
+			r.nextID++
+			usuario.ID = "mem-" + strconv.Itoa(r.nextID)
+		} else if _, ok := r.usuarios[usuario.ID]; !ok {
+			errs[i] = fmt.Errorf("usuario %s no encontrado", usuario.ID)
+			continue
+		}
+		r.usuarios[usuario.ID] = *usuario
+	}
+	return errs
+}
+
+// PatchAll actualiza solo los campos indicados de cada usuario
+func (r *MemoryUsuarioRepository) PatchAll(ctx context.Context, patches []UsuarioPatch) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := make([]error, len(patches))
+	for i, patch := range patches {
+		stored, ok := r.usuarios[patch.Usuario.ID]
+		if !ok {
+			errs[i] = fmt.Errorf("usuario %s no encontrado", patch.Usuario.ID)
+			continue
+		}
+		stored.CopyFields(patch.Usuario, patch.Fields)
+		if err := r.checkDocumento(stored.Documento(), stored.ID); err != nil {
+			errs[i] = err
+			continue
+		}
+		r.usuarios[stored.ID] = stored
+	}
+	return errs
+}
+
+// Update actualiza un usuario existente
+func (r *MemoryUsuarioRepository) Update(ctx context.Context, id string, usuario *models.Usuario) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.usuarios[id]; !ok {
+		return fmt.Errorf("usuario %s no encontrado", id)
+	}
+	if err := r.checkDocumento(usuario.Documento(), id); err != nil {
+		return err
+	}
+	usuario.ID = id
+	r.usuarios[id] = *usuario
+	return nil
+}
+
+// Modify aplica fn al usuario y lo guarda con el lock tomado
+func (r *MemoryUsuarioRepository) Modify(ctx context.Context, id string, fn func(*models.Usuario) error) (*models.Usuario, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usuario, ok := r.usuarios[id]
+	if !ok {
+		return nil, fmt.Errorf("usuario %s no encontrado", id)
+	}
+	doc := usuario.Documento()
+	if err := fn(&usuario); err != nil {
+		return nil, err
+	}
+	if usuario.Documento() != doc {
+		return nil, fmt.Errorf("no se puede cambiar el documento al modificar el usuario %s", id)
+	}
+	r.usuarios[id] = usuario
+	return &usuario, nil
+}
+
+// Delete elimina un usuario
+func (r *MemoryUsuarioRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.usuarios, id)
+	return nil
+}
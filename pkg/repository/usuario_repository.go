@@ -2,43 +2,171 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
 	"github.com/luispfcanales/rainforestapp/pkg/models"
 )
 
 const usuariosCollection = "usuarios"
 
-// UsuarioRepository maneja las operaciones de base de datos para usuarios
-type UsuarioRepository struct {
+// documentosCollection reservas de los documentos de identidad; el ID es tipo-numero y se
+// escribe en la misma transacción que el usuario para que dos altas simultáneas con el mismo
+// documento no puedan confirmarse las dos
+const documentosCollection = "documentos"
+
+// writeChunk filas por transacción de SaveAll y PatchAll; cada fila escribe hasta tres
+// documentos (usuario, reserva nueva y reserva liberada), lejos del límite de 500 escrituras
+const writeChunk = 100
+
+// ErrUsuarioNotFound no hay un usuario con el documento indicado
+var ErrUsuarioNotFound = errors.New("usuario no encontrado")
+
+// ErrDocumentoRegistrado otro usuario ya tiene el mismo tipo y número de documento
+var ErrDocumentoRegistrado = errors.New("el documento ya está registrado")
+
+// documentoFields campos que forman el documento; si una actualización parcial los cambia
+// también se actualiza la reserva
+var documentoFields = []string{"tipo_documento", "numero_documento", "dni"}
+
+// UsuarioPatch actualización parcial de un usuario existente: solo se escriben los campos de
+// Fields, con los nombres de Firestore, tomando los valores de Usuario
+type UsuarioPatch struct {
+	Usuario *models.Usuario
+	Fields  []string
+}
+
+// documentoReserva reserva de un documento por el usuario que lo tiene
+type documentoReserva struct {
+	UsuarioID string `firestore:"usuario_id"`
+}
+
+// maxInValues cantidad máxima de valores de una consulta "in" en Firestore
+const maxInValues = 30
+
+// UsuarioRepository define las operaciones de persistencia de usuarios
+type UsuarioRepository interface {
+	Create(ctx context.Context, usuario *models.Usuario) (*models.Usuario, error)
+	GetByID(ctx context.Context, id string) (*models.Usuario, error)
+	GetByDocumento(ctx context.Context, doc models.Documento) (*models.Usuario, error)
+	GetAll(ctx context.Context, filter models.UsuarioFilter) ([]*models.Usuario, error)
+	Stream(ctx context.Context, filter models.UsuarioFilter, fn func(*models.Usuario) error) error
+	Page(ctx context.Context, afterID string, limit int) ([]*models.Usuario, error)
+	GetByDocumentos(ctx context.Context, docs []models.Documento) (map[models.Documento]*models.Usuario, error)
+	SaveAll(ctx context.Context, usuarios []*models.Usuario) []error
+	PatchAll(ctx context.Context, patches []UsuarioPatch) []error
+	Update(ctx context.Context, id string, usuario *models.Usuario) error
+	Modify(ctx context.Context, id string, fn func(*models.Usuario) error) (*models.Usuario, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// FirestoreUsuarioRepository maneja las operaciones de base de datos para usuarios en Firestore
+type FirestoreUsuarioRepository struct {
 	client *firestore.Client
 }
 
 // NewUsuarioRepository crea una nueva instancia del repositorio
-func NewUsuarioRepository(client *firestore.Client) *UsuarioRepository {
-	return &UsuarioRepository{
+func NewUsuarioRepository(client *firestore.Client) *FirestoreUsuarioRepository {
+	return &FirestoreUsuarioRepository{
 		client: client,
 	}
 }
 
-// Create guarda un nuevo usuario en Firestore
-func (r *UsuarioRepository) Create(ctx context.Context, usuario *models.Usuario) (*models.Usuario, error) {
-	docRef, _, err := r.client.Collection(usuariosCollection).Add(ctx, usuario)
+// Create guarda un nuevo usuario en Firestore y reserva su documento en la misma transacción.
+// Devuelve ErrDocumentoRegistrado si otro usuario ya tiene el documento.
+func (r *FirestoreUsuarioRepository) Create(ctx context.Context, usuario *models.Usuario) (*models.Usuario, error) {
+	ref := r.client.Collection(usuariosCollection).NewDoc()
+	doc := usuario.Documento()
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := r.checkReserva(tx, doc, ref.ID); err != nil {
+			return err
+		}
+		if err := tx.Set(r.documentoRef(doc), documentoReserva{UsuarioID: ref.ID}); err != nil {
+			return err
+		}
+		return tx.Create(ref, usuario)
+	})
 	if err != nil {
+		if !errors.Is(err, ErrDocumentoRegistrado) {
+			metrics.FirestoreError("create")
+		}
 		return nil, fmt.Errorf("error creando usuario: %w", err)
 	}
 
-	usuario.ID = docRef.ID
+	usuario.ID = ref.ID
 	return usuario, nil
 }
 
+// documentoRef reserva del documento
+func (r *FirestoreUsuarioRepository) documentoRef(doc models.Documento) *firestore.DocumentRef {
+	return r.client.Collection(documentosCollection).Doc(doc.Tipo + "-" + doc.Numero)
+}
+
+// documentoQueries consultas de los usuarios con el documento; los DNI también se buscan en el
+// campo dni, el único que tienen los registros anteriores a la migración
+func (r *FirestoreUsuarioRepository) documentoQueries(doc models.Documento) []firestore.Query {
+	collection := r.client.Collection(usuariosCollection)
+	queries := []firestore.Query{
+		collection.Where("tipo_documento", "==", doc.Tipo).Where("numero_documento", "==", doc.Numero),
+	}
+	if doc.Tipo == models.DocumentoDNI {
+		queries = append(queries, collection.Where("dni", "==", doc.Numero))
+	}
+	return queries
+}
+
+// checkReserva verifica dentro de la transacción que el documento no lo tenga otro usuario:
+// ni por su reserva ni, en los registros anteriores a las reservas, por sus campos. Una
+// reserva de un usuario que ya no existe se descarta.
+func (r *FirestoreUsuarioRepository) checkReserva(tx *firestore.Transaction, doc models.Documento, id string) error {
+	snap, err := tx.Get(r.documentoRef(doc))
+	switch {
+	case status.Code(err) == codes.NotFound:
+	case err != nil:
+		return err
+	default:
+		var reserva documentoReserva
+		if err := snap.DataTo(&reserva); err != nil {
+			return err
+		}
+		if reserva.UsuarioID != id {
+			_, err := tx.Get(r.client.Collection(usuariosCollection).Doc(reserva.UsuarioID))
+			if err == nil {
+				return fmt.Errorf("%w: %s", ErrDocumentoRegistrado, doc)
+			}
+			if status.Code(err) != codes.NotFound {
+				return err
+			}
+		}
+	}
+
+	for _, query := range r.documentoQueries(doc) {
+		docs, err := tx.Documents(query.Limit(2)).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, d := range docs {
+			if d.Ref.ID != id {
+				return fmt.Errorf("%w: %s", ErrDocumentoRegistrado, doc)
+			}
+		}
+	}
+	return nil
+}
+
 // GetByID obtiene un usuario por su ID
-func (r *UsuarioRepository) GetByID(ctx context.Context, id string) (*models.Usuario, error) {
+func (r *FirestoreUsuarioRepository) GetByID(ctx context.Context, id string) (*models.Usuario, error) {
 	doc, err := r.client.Collection(usuariosCollection).Doc(id).Get(ctx)
 	if err != nil {
+		metrics.FirestoreError("get_by_id")
 		return nil, fmt.Errorf("error obteniendo usuario: %w", err)
 	}
 
@@ -48,52 +176,68 @@ func (r *UsuarioRepository) GetByID(ctx context.Context, id string) (*models.Usu
 	}
 
 	usuario.ID = doc.Ref.ID
+	usuario.Version = doc.UpdateTime
 	return &usuario, nil
 }
 
-// GetByDNI obtiene un usuario por su DNI
-func (r *UsuarioRepository) GetByDNI(ctx context.Context, dni string) (*models.Usuario, error) {
-	iter := r.client.Collection(usuariosCollection).
-		Where("dni", "==", dni).
-		Limit(1).
-		Documents(ctx)
+// GetByDocumento obtiene un usuario por su tipo y número de documento. Los DNI también se
+// buscan en el campo dni, el único que tienen los registros anteriores a la migración.
+func (r *FirestoreUsuarioRepository) GetByDocumento(ctx context.Context, doc models.Documento) (*models.Usuario, error) {
+	for _, query := range r.documentoQueries(doc) {
+		docs, err := query.Limit(1).Documents(ctx).GetAll()
+		if err != nil {
+			metrics.FirestoreError("get_by_documento")
+			return nil, fmt.Errorf("error buscando usuario por documento: %w", err)
+		}
+		if len(docs) == 0 {
+			continue
+		}
 
-	doc, err := iter.Next()
-	if err == iterator.Done {
-		return nil, fmt.Errorf("usuario con email %s no encontrado", dni)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("error buscando usuario por email: %w", err)
+		var usuario models.Usuario
+		if err := docs[0].DataTo(&usuario); err != nil {
+			return nil, fmt.Errorf("error parseando usuario: %w", err)
+		}
+		usuario.ID = docs[0].Ref.ID
+		usuario.Version = docs[0].UpdateTime
+		return &usuario, nil
 	}
+	return nil, fmt.Errorf("%w: %s", ErrUsuarioNotFound, doc)
+}
 
-	var usuario models.Usuario
-	if err := doc.DataTo(&usuario); err != nil {
-		return nil, fmt.Errorf("error parseando usuario: %w", err)
+// GetAll obtiene los usuarios que cumplen con los filtros.
+// Los filtros combinados con el orden por created_at requieren los índices de firestore.indexes.json.
+func (r *FirestoreUsuarioRepository) GetAll(ctx context.Context, filter models.UsuarioFilter) ([]*models.Usuario, error) {
+	var usuarios []*models.Usuario
+	err := r.Stream(ctx, filter, func(usuario *models.Usuario) error {
+		usuarios = append(usuarios, usuario)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	usuario.ID = doc.Ref.ID
-	return &usuario, nil
+	return usuarios, nil
 }
 
-// GetAll obtiene todos los usuarios
-func (r *UsuarioRepository) GetAll(ctx context.Context, limit int) ([]*models.Usuario, error) {
-	query := r.client.Collection(usuariosCollection).OrderBy("created_at", firestore.Desc)
+// Stream recorre el cursor de Firestore llamando a fn por cada usuario sin cargarlos todos en memoria.
+// Si fn devuelve un error se detiene el recorrido y se devuelve ese error.
+func (r *FirestoreUsuarioRepository) Stream(ctx context.Context, filter models.UsuarioFilter, fn func(*models.Usuario) error) error {
+	query := applyFilter(r.client.Collection(usuariosCollection).Query, filter).OrderBy("created_at", firestore.Desc)
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
 	}
 
 	iter := query.Documents(ctx)
 	defer iter.Stop()
 
-	var usuarios []*models.Usuario
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
-			break
+			return nil
 		}
 		if err != nil {
-			return nil, fmt.Errorf("error iterando usuarios: %w", err)
+			metrics.FirestoreError("get_all")
+			return fmt.Errorf("error iterando usuarios: %w", err)
 		}
 
 		var usuario models.Usuario
@@ -101,26 +245,466 @@ func (r *UsuarioRepository) GetAll(ctx context.Context, limit int) ([]*models.Us
 			continue
 		}
 		usuario.ID = doc.Ref.ID
+		usuario.Version = doc.UpdateTime
+		if err := fn(&usuario); err != nil {
+			return err
+		}
+	}
+}
+
+// Page devuelve hasta limit usuarios ordenados por ID a partir del siguiente a afterID (vacío
+// desde el inicio). Permite recorrer la colección en lotes y retomar desde el último ID.
+func (r *FirestoreUsuarioRepository) Page(ctx context.Context, afterID string, limit int) ([]*models.Usuario, error) {
+	query := r.client.Collection(usuariosCollection).OrderBy(firestore.DocumentID, firestore.Asc).Limit(limit)
+	if afterID != "" {
+		query = query.StartAfter(afterID)
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		metrics.FirestoreError("page")
+		return nil, fmt.Errorf("error obteniendo usuarios: %w", err)
+	}
+
+	usuarios := make([]*models.Usuario, 0, len(docs))
+	for _, doc := range docs {
+		var usuario models.Usuario
+		if err := doc.DataTo(&usuario); err != nil {
+			return nil, fmt.Errorf("error parseando usuario %s: %w", doc.Ref.ID, err)
+		}
+		usuario.ID = doc.Ref.ID
+		usuario.Version = doc.UpdateTime
 		usuarios = append(usuarios, &usuario)
 	}
+	return usuarios, nil
+}
+
+// GetByDocumentos obtiene los usuarios registrados con alguno de los documentos indicados,
+// indexados por documento. Se consulta por tipo con "in" sobre el número; los DNI que no se
+// encuentran se buscan también en el campo dni de los registros anteriores a la migración.
+func (r *FirestoreUsuarioRepository) GetByDocumentos(ctx context.Context, docs []models.Documento) (map[models.Documento]*models.Usuario, error) {
+	usuarios := make(map[models.Documento]*models.Usuario, len(docs))
+
+	numeros := make(map[string][]string)
+	for _, doc := range docs {
+		numeros[doc.Tipo] = append(numeros[doc.Tipo], doc.Numero)
+	}
+
+	collection := r.client.Collection(usuariosCollection)
+	for tipo, values := range numeros {
+		query := collection.Where("tipo_documento", "==", tipo)
+		if err := r.collectByNumero(ctx, query, "numero_documento", values, usuarios); err != nil {
+			return nil, err
+		}
+	}
+
+	var legacy []string
+	for _, numero := range numeros[models.DocumentoDNI] {
+		if _, ok := usuarios[models.Documento{Tipo: models.DocumentoDNI, Numero: numero}]; !ok {
+			legacy = append(legacy, numero)
+		}
+	}
+	if err := r.collectByNumero(ctx, collection.Query, "dni", legacy, usuarios); err != nil {
+		return nil, err
+	}
 
 	return usuarios, nil
 }
 
-// Update actualiza un usuario existente
-func (r *UsuarioRepository) Update(ctx context.Context, id string, usuario *models.Usuario) error {
-	_, err := r.client.Collection(usuariosCollection).Doc(id).Set(ctx, usuario, firestore.MergeAll)
+// collectByNumero consulta los números en bloques de maxInValues y agrega los usuarios
+// encontrados indexados por su documento
+func (r *FirestoreUsuarioRepository) collectByNumero(ctx context.Context, query firestore.Query, field string, values []string, usuarios map[models.Documento]*models.Usuario) error {
+	for start := 0; start < len(values); start += maxInValues {
+		end := min(start+maxInValues, len(values))
+
+		docs, err := query.Where(field, "in", values[start:end]).Documents(ctx).GetAll()
+		if err != nil {
+			metrics.FirestoreError("get_by_documentos")
+			return fmt.Errorf("error buscando usuarios por documento: %w", err)
+		}
+
+		for _, doc := range docs {
+			var usuario models.Usuario
+			if err := doc.DataTo(&usuario); err != nil {
+				return fmt.Errorf("error parseando usuario: %w", err)
+			}
+			usuario.ID = doc.Ref.ID
+			usuario.Version = doc.UpdateTime
+			usuarios[usuario.Documento()] = &usuario
+		}
+	}
+	return nil
+}
+
+// SaveAll guarda los usuarios en transacciones de writeChunk filas: crea los que no tienen ID
+// reservando su documento y reemplaza los existentes, que mantienen su documento. Un documento
+// ya registrado solo descarta su fila; otro error descarta el bloque. Devuelve el error de cada
+// usuario en el mismo orden.
+func (r *FirestoreUsuarioRepository) SaveAll(ctx context.Context, usuarios []*models.Usuario) []error {
+	errs := make([]error, len(usuarios))
+	collection := r.client.Collection(usuariosCollection)
+
+	for start := 0; start < len(usuarios); start += writeChunk {
+		chunk := usuarios[start:min(start+writeChunk, len(usuarios))]
+		chunkErrs := errs[start : start+len(chunk)]
+		refs := make([]*firestore.DocumentRef, len(chunk))
+		for i, usuario := range chunk {
+			if usuario.ID == "" {
+				refs[i] = collection.NewDoc()
+			} else {
+				refs[i] = collection.Doc(usuario.ID)
+			}
+		}
+
+		err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			clear(chunkErrs)
+			// Firestore exige hacer todas las lecturas antes de la primera escritura
+			reservados := make(map[models.Documento]bool, len(chunk))
+			for i, usuario := range chunk {
+				if usuario.ID != "" {
+					continue
+				}
+				doc := usuario.Documento()
+				err := r.checkReserva(tx, doc, refs[i].ID)
+				if err == nil && reservados[doc] {
+					err = fmt.Errorf("%w: %s", ErrDocumentoRegistrado, doc)
+				}
+				if errors.Is(err, ErrDocumentoRegistrado) {
+					chunkErrs[i] = fmt.Errorf("error creando usuario: %w", err)
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				reservados[doc] = true
+			}
+
+			for i, usuario := range chunk {
+				if chunkErrs[i] != nil {
+					continue
+				}
+				if err := tx.Set(r.documentoRef(usuario.Documento()), documentoReserva{UsuarioID: refs[i].ID}); err != nil {
+					return err
+				}
+				var err error
+				if usuario.ID == "" {
+					err = tx.Create(refs[i], usuario)
+				} else {
+					err = tx.Set(refs[i], usuario)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			metrics.FirestoreError("save_all")
+			for i := range chunkErrs {
+				chunkErrs[i] = fmt.Errorf("error guardando usuario: %w", err)
+			}
+			continue
+		}
+
+		for i, usuario := range chunk {
+			if chunkErrs[i] == nil {
+				usuario.ID = refs[i].ID
+			}
+		}
+	}
+
+	return errs
+}
+
+// applyFilter agrega a la consulta las condiciones de igualdad de los filtros
+func applyFilter(query firestore.Query, filter models.UsuarioFilter) firestore.Query {
+	if filter.LugarTrabajo != "" {
+		query = query.Where("lugar_trabajo", "==", filter.LugarTrabajo)
+	}
+	if filter.PuestoActual != "" {
+		query = query.Where("puesto_actual", "==", filter.PuestoActual)
+	}
+	if filter.SituacionContractual != "" {
+		query = query.Where("situacion_contractual", "==", filter.SituacionContractual)
+	}
+	if filter.RegimenPensionario != "" {
+		query = query.Where("regimen_pensionario", "==", filter.RegimenPensionario)
+	}
+	return query
+}
+
+// PatchAll actualiza en lote solo los campos indicados de cada usuario. Si el usuario tiene
+// Version, la escritura falla cuando el documento cambió después de leerlo. Los cambios de
+// documento se guardan en transacciones que mueven la reserva como Update. Devuelve el error
+// de cada actualización en el mismo orden.
+func (r *FirestoreUsuarioRepository) PatchAll(ctx context.Context, patches []UsuarioPatch) []error {
+	errs := make([]error, len(patches))
+	collection := r.client.Collection(usuariosCollection)
+
+	bw := r.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, len(patches))
+	var documentos []int
+	updates := make([][]firestore.Update, len(patches))
+
+	for i, patch := range patches {
+		usuario := patch.Usuario
+		for _, name := range patch.Fields {
+			value, ok := usuario.FieldValue(name)
+			if !ok {
+				errs[i] = fmt.Errorf("campo desconocido: %s", name)
+				break
+			}
+			updates[i] = append(updates[i], firestore.Update{Path: name, Value: value})
+		}
+		if errs[i] != nil || len(updates[i]) == 0 {
+			continue
+		}
+		if slices.ContainsFunc(patch.Fields, isDocumentoField) {
+			documentos = append(documentos, i)
+			continue
+		}
+
+		var preconds []firestore.Precondition
+		if !usuario.Version.IsZero() {
+			preconds = append(preconds, firestore.LastUpdateTime(usuario.Version))
+		}
+		var err error
+		if jobs[i], err = bw.Update(collection.Doc(usuario.ID), updates[i], preconds...); err != nil {
+			errs[i] = fmt.Errorf("error encolando usuario: %w", err)
+		}
+	}
+	bw.End()
+
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+		if _, err := job.Results(); err != nil {
+			metrics.FirestoreError("patch_all")
+			errs[i] = fmt.Errorf("error actualizando usuario: %w", err)
+		}
+	}
+
+	for start := 0; start < len(documentos); start += writeChunk {
+		r.patchDocumentos(ctx, patches, updates, documentos[start:min(start+writeChunk, len(documentos))], errs)
+	}
+	return errs
+}
+
+// patchDocumentos guarda en una transacción las actualizaciones indicadas, que cambian el
+// documento: reserva el nuevo, libera el anterior si es suyo y actualiza los campos. Un
+// documento ya registrado, un usuario que no existe o que cambió después de leerlo solo
+// descartan su fila; otro error descarta el bloque.
+func (r *FirestoreUsuarioRepository) patchDocumentos(ctx context.Context, patches []UsuarioPatch, updates [][]firestore.Update, indexes []int, errs []error) {
+	collection := r.client.Collection(usuariosCollection)
+	releases := make([]*firestore.DocumentRef, len(indexes))
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		for _, i := range indexes {
+			errs[i] = nil
+		}
+		// Firestore exige hacer todas las lecturas antes de la primera escritura
+		reservados := make(map[models.Documento]bool, len(indexes))
+		for n, i := range indexes {
+			usuario := patches[i].Usuario
+			doc := usuario.Documento()
+
+			snap, err := tx.Get(collection.Doc(usuario.ID))
+			if status.Code(err) == codes.NotFound {
+				errs[i] = fmt.Errorf("error actualizando usuario: %w", err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if !usuario.Version.IsZero() && !snap.UpdateTime.Equal(usuario.Version) {
+				errs[i] = fmt.Errorf("error actualizando usuario: el usuario %s cambió después de leerlo", usuario.ID)
+				continue
+			}
+			var current models.Usuario
+			if err := snap.DataTo(&current); err != nil {
+				return err
+			}
+
+			err = r.checkReserva(tx, doc, usuario.ID)
+			if err == nil && reservados[doc] {
+				err = fmt.Errorf("%w: %s", ErrDocumentoRegistrado, doc)
+			}
+			if errors.Is(err, ErrDocumentoRegistrado) {
+				errs[i] = fmt.Errorf("error actualizando usuario: %w", err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if releases[n], err = r.ownReserva(tx, current.Documento(), doc, usuario.ID); err != nil {
+				return err
+			}
+			reservados[doc] = true
+		}
+
+		for n, i := range indexes {
+			if errs[i] != nil {
+				continue
+			}
+			usuario := patches[i].Usuario
+			if releases[n] != nil {
+				if err := tx.Delete(releases[n]); err != nil {
+					return err
+				}
+			}
+			if err := tx.Set(r.documentoRef(usuario.Documento()), documentoReserva{UsuarioID: usuario.ID}); err != nil {
+				return err
+			}
+			if err := tx.Update(collection.Doc(usuario.ID), updates[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
+		metrics.FirestoreError("patch_all")
+		for _, i := range indexes {
+			errs[i] = fmt.Errorf("error actualizando usuario: %w", err)
+		}
+	}
+}
+
+// isDocumentoField indica si el campo forma parte del documento
+func isDocumentoField(name string) bool {
+	return slices.Contains(documentoFields, name)
+}
+
+// Update actualiza un usuario existente. Si cambia el documento, reserva el nuevo y libera el
+// anterior en la misma transacción; devuelve ErrDocumentoRegistrado si otro usuario lo tiene.
+func (r *FirestoreUsuarioRepository) Update(ctx context.Context, id string, usuario *models.Usuario) error {
+	ref := r.client.Collection(usuariosCollection).Doc(id)
+	doc := usuario.Documento()
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		var current models.Usuario
+		if err := snap.DataTo(&current); err != nil {
+			return err
+		}
+		if err := r.checkReserva(tx, doc, id); err != nil {
+			return err
+		}
+		release, err := r.ownReserva(tx, current.Documento(), doc, id)
+		if err != nil {
+			return err
+		}
+
+		if release != nil {
+			if err := tx.Delete(release); err != nil {
+				return err
+			}
+		}
+		if err := tx.Set(r.documentoRef(doc), documentoReserva{UsuarioID: id}); err != nil {
+			return err
+		}
+		return tx.Set(ref, usuario, firestore.MergeAll)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrDocumentoRegistrado) {
+			metrics.FirestoreError("update")
+		}
 		return fmt.Errorf("error actualizando usuario: %w", err)
 	}
 	return nil
 }
 
-// Delete elimina un usuario
-func (r *UsuarioRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.client.Collection(usuariosCollection).Doc(id).Delete(ctx)
+// Modify lee el usuario, le aplica fn y lo guarda en la misma transacción, de modo que dos
+// modificaciones simultáneas no se pisen. fn no debe cambiar el documento; si devuelve un
+// error no se guarda nada.
+func (r *FirestoreUsuarioRepository) Modify(ctx context.Context, id string, fn func(*models.Usuario) error) (*models.Usuario, error) {
+	ref := r.client.Collection(usuariosCollection).Doc(id)
+
+	var usuario models.Usuario
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+		usuario = models.Usuario{}
+		if err := snap.DataTo(&usuario); err != nil {
+			return err
+		}
+		usuario.ID = id
+		doc := usuario.Documento()
+		if err := fn(&usuario); err != nil {
+			return err
+		}
+		if usuario.Documento() != doc {
+			return fmt.Errorf("no se puede cambiar el documento al modificar el usuario %s", id)
+		}
+		return tx.Set(ref, &usuario)
+	})
+	if err != nil {
+		metrics.FirestoreError("modify")
+		return nil, fmt.Errorf("error modificando usuario: %w", err)
+	}
+	return &usuario, nil
+}
+
+// Delete elimina un usuario y libera la reserva de su documento
+func (r *FirestoreUsuarioRepository) Delete(ctx context.Context, id string) error {
+	ref := r.client.Collection(usuariosCollection).Doc(id)
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var current models.Usuario
+		if err := snap.DataTo(&current); err != nil {
+			return err
+		}
+		release, err := r.ownReserva(tx, current.Documento(), models.Documento{}, id)
+		if err != nil {
+			return err
+		}
+
+		if release != nil {
+			if err := tx.Delete(release); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(ref)
+	})
 	if err != nil {
+		metrics.FirestoreError("delete")
 		return fmt.Errorf("error eliminando usuario: %w", err)
 	}
 	return nil
 }
+
+// ownReserva devuelve la reserva del documento anterior del usuario para liberarla si el
+// documento cambió y la reserva es suya; nil si no hay que liberar nada
+func (r *FirestoreUsuarioRepository) ownReserva(tx *firestore.Transaction, anterior, nuevo models.Documento, id string) (*firestore.DocumentRef, error) {
+	if anterior == nuevo || anterior.Numero == "" {
+		return nil, nil
+	}
+	ref := r.documentoRef(anterior)
+	snap, err := tx.Get(ref)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var reserva documentoReserva
+	if err := snap.DataTo(&reserva); err != nil {
+		return nil, err
+	}
+	if reserva.UsuarioID != id {
+		return nil, nil
+	}
+	return ref, nil
+}
@@ -65,3 +65,13 @@ func NotFound(w http.ResponseWriter, message string) {
 func Unauthorized(w http.ResponseWriter, message string) {
 	Error(w, http.StatusUnauthorized, message)
 }
+
+// RequestEntityTooLarge envía un error 413
+func RequestEntityTooLarge(w http.ResponseWriter, message string) {
+	Error(w, http.StatusRequestEntityTooLarge, message)
+}
+
+// TooManyRequests envía un error 429
+func TooManyRequests(w http.ResponseWriter, message string) {
+	Error(w, http.StatusTooManyRequests, message)
+}
@@ -1,21 +1,29 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/luispfcanales/rainforestapp/pkg/metrics"
 	"github.com/luispfcanales/rainforestapp/pkg/models"
 	"github.com/luispfcanales/rainforestapp/pkg/repository"
 )
 
+// ErrDocumentoRegistrado ya hay otro usuario con el mismo tipo y número de documento; el
+// repositorio lo verifica en la misma transacción que la escritura
+var ErrDocumentoRegistrado = repository.ErrDocumentoRegistrado
+
 // UsuarioService maneja la lógica de negocio de usuarios
 type UsuarioService struct {
-	repo *repository.UsuarioRepository
+	repo repository.UsuarioRepository
 }
 
 // NewUsuarioService crea una nueva instancia del servicio
-func NewUsuarioService(repo *repository.UsuarioRepository) *UsuarioService {
+func NewUsuarioService(repo repository.UsuarioRepository) *UsuarioService {
 	return &UsuarioService{
 		repo: repo,
 	}
@@ -25,6 +33,7 @@ func NewUsuarioService(repo *repository.UsuarioRepository) *UsuarioService {
 func (s *UsuarioService) CreateUsuario(ctx context.Context, req *models.CreateUsuarioRequest) (*models.Usuario, error) {
 	// Validar request
 	if err := req.Validate(); err != nil {
+		recordValidationFailure(err)
 		return nil, fmt.Errorf("validación fallida: %w", err)
 	}
 
@@ -40,13 +49,27 @@ func (s *UsuarioService) CreateUsuario(ctx context.Context, req *models.CreateUs
 	return createdUsuario, nil
 }
 
-// GetUsuarioByDNI obtiene un usuario por DNI
-func (s *UsuarioService) GetUsuarioByDNI(ctx context.Context, dni string) (*models.Usuario, error) {
-	if dni == "" {
+// GetUsuario obtiene un usuario por su ID
+func (s *UsuarioService) GetUsuario(ctx context.Context, id string) (*models.Usuario, error) {
+	if id == "" {
 		return nil, fmt.Errorf("ID es requerido")
 	}
 
-	usuario, err := s.repo.GetByDNI(ctx, dni)
+	usuario, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo usuario: %w", err)
+	}
+
+	return usuario, nil
+}
+
+// GetUsuarioByDocumento obtiene un usuario por su tipo y número de documento
+func (s *UsuarioService) GetUsuarioByDocumento(ctx context.Context, doc models.Documento) (*models.Usuario, error) {
+	if doc.Numero == "" {
+		return nil, fmt.Errorf("número de documento es requerido")
+	}
+
+	usuario, err := s.repo.GetByDocumento(ctx, doc)
 	if err != nil {
 		return nil, fmt.Errorf("error obteniendo usuario: %w", err)
 	}
@@ -54,9 +77,9 @@ func (s *UsuarioService) GetUsuarioByDNI(ctx context.Context, dni string) (*mode
 	return usuario, nil
 }
 
-// ListUsuarios lista todos los usuarios
-func (s *UsuarioService) ListUsuarios(ctx context.Context, limit int) ([]*models.Usuario, error) {
-	usuarios, err := s.repo.GetAll(ctx, limit)
+// ListUsuarios lista los usuarios que cumplen con los filtros
+func (s *UsuarioService) ListUsuarios(ctx context.Context, filter models.UsuarioFilter) ([]*models.Usuario, error) {
+	usuarios, err := s.repo.GetAll(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("error listando usuarios: %w", err)
 	}
@@ -64,9 +87,18 @@ func (s *UsuarioService) ListUsuarios(ctx context.Context, limit int) ([]*models
 	return usuarios, nil
 }
 
+// StreamUsuarios recorre los usuarios que cumplen con los filtros sin cargarlos todos en memoria
+func (s *UsuarioService) StreamUsuarios(ctx context.Context, filter models.UsuarioFilter, fn func(*models.Usuario) error) error {
+	if err := s.repo.Stream(ctx, filter, fn); err != nil {
+		return fmt.Errorf("error recorriendo usuarios: %w", err)
+	}
+	return nil
+}
+
 // UpdateUsuario actualiza un usuario existente
 func (s *UsuarioService) UpdateUsuario(ctx context.Context, id string, req *models.CreateUsuarioRequest) (*models.Usuario, error) {
 	if err := req.Validate(); err != nil {
+		recordValidationFailure(err)
 		return nil, fmt.Errorf("validación fallida: %w", err)
 	}
 
@@ -77,9 +109,11 @@ func (s *UsuarioService) UpdateUsuario(ctx context.Context, id string, req *mode
 	}
 
 	// Actualizar campos
-	// Preservar ID y timestamp de creación
+	// Preservar ID, timestamp de creación y la cuenta de otro banco de los registros
+	// anteriores, que la petición no puede escribir
 	currentID := existingUsuario.ID
 	createdAt := existingUsuario.CreatedAt
+	previous := *existingUsuario
 
 	// Convertir request a modelo con los nuevos datos
 	newUsuario := req.ToUsuario()
@@ -90,6 +124,9 @@ func (s *UsuarioService) UpdateUsuario(ctx context.Context, id string, req *mode
 	// Restaurar ID y metadatos
 	existingUsuario.ID = currentID
 	existingUsuario.CreatedAt = createdAt
+	existingUsuario.OtroBancoNombre = previous.OtroBancoNombre
+	existingUsuario.OtroBancoCuenta = previous.OtroBancoCuenta
+	existingUsuario.OtroBancoCci = previous.OtroBancoCci
 	existingUsuario.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(ctx, id, existingUsuario); err != nil {
@@ -99,6 +136,26 @@ func (s *UsuarioService) UpdateUsuario(ctx context.Context, id string, req *mode
 	return existingUsuario, nil
 }
 
+// SetCuentaActiva registra la cuenta como la cuenta activa del trabajador para su propósito.
+// Las cuentas se leen y se guardan en la misma transacción para no perder otra cuenta
+// registrada al mismo tiempo.
+func (s *UsuarioService) SetCuentaActiva(ctx context.Context, doc models.Documento, cuenta models.CuentaBancaria) (*models.Usuario, error) {
+	usuario, err := s.repo.GetByDocumento(ctx, doc)
+	if err != nil {
+		return nil, fmt.Errorf("usuario no encontrado: %w", err)
+	}
+
+	usuario, err = s.repo.Modify(ctx, usuario.ID, func(u *models.Usuario) error {
+		u.SetCuentaActiva(cuenta)
+		u.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error actualizando usuario: %w", err)
+	}
+	return usuario, nil
+}
+
 // DeleteUsuario elimina un usuario
 func (s *UsuarioService) DeleteUsuario(ctx context.Context, id string) error {
 	if id == "" {
@@ -111,3 +168,149 @@ func (s *UsuarioService) DeleteUsuario(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// ImportUsuarios valida las filas importadas y, si no es dry-run, crea o actualiza
+// los usuarios válidos según la política indicada para los documentos ya registrados
+func (s *UsuarioService) ImportUsuarios(ctx context.Context, rows []models.ImportRow, opts models.ImportOptions) (*models.ImportReport, error) {
+	if !opts.Policy.Valid() {
+		return nil, fmt.Errorf("política de importación %q no soportada", opts.Policy)
+	}
+
+	report := &models.ImportReport{
+		DryRun:   opts.DryRun,
+		Politica: opts.Policy,
+		Total:    len(rows),
+		Filas:    make([]models.ImportRowResult, len(rows)),
+	}
+
+	// Validar cada fila y detectar documentos repetidos dentro del archivo
+	firstLine := make(map[models.Documento]int, len(rows))
+	docs := make([]models.Documento, len(rows))
+	var valid []models.Documento
+	for i := range rows {
+		row := &rows[i]
+		result := &report.Filas[i]
+		result.Fila = row.Line
+		result.TipoDocumento = strings.TrimSpace(row.Request.TipoDocumento)
+		result.NumeroDocumento = cmp.Or(strings.TrimSpace(row.Request.NumeroDocumento), strings.TrimSpace(row.Request.Dni))
+		result.Errores = row.Errors
+		if doc, err := models.ParseDocumento(row.Request.TipoDocumento, row.Request.NumeroDocumento, row.Request.Dni); err == nil {
+			docs[i] = doc
+			result.TipoDocumento, result.NumeroDocumento = doc.Tipo, doc.Numero
+		}
+		doc := docs[i]
+
+		if err := row.Request.Validate(); err != nil {
+			recordValidationFailure(err)
+			result.Errores = append(result.Errores, err.Error())
+		} else if err := row.Request.ValidateLimits(opts.Limits); err != nil {
+			recordValidationFailure(err)
+			result.Errores = append(result.Errores, err.Error())
+		}
+		if line, ok := firstLine[doc]; ok && doc.Numero != "" {
+			result.Errores = append(result.Errores, fmt.Sprintf("documento duplicado en el archivo (fila %d)", line))
+		} else if doc.Numero != "" {
+			firstLine[doc] = row.Line
+		}
+
+		if len(result.Errores) > 0 {
+			result.Estado = models.ImportEstadoInvalido
+			report.Invalidas++
+			continue
+		}
+		report.Validas++
+		valid = append(valid, doc)
+	}
+
+	existing, err := s.repo.GetByDocumentos(ctx, valid)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando documentos registrados: %w", err)
+	}
+
+	// Preparar la acción de cada fila válida. Los documentos nuevos se crean completos; en
+	// los registrados solo se escriben las columnas que trae el archivo.
+	var creates []*models.Usuario
+	var patches []repository.UsuarioPatch
+	var createRows, patchRows []int
+	for i := range rows {
+		result := &report.Filas[i]
+		if result.Estado == models.ImportEstadoInvalido {
+			continue
+		}
+
+		usuario := rows[i].Request.ToUsuario()
+		current, ok := existing[docs[i]]
+		if !ok {
+			result.Estado = models.ImportEstadoCrear
+			report.Creados++
+			creates = append(creates, usuario)
+			createRows = append(createRows, i)
+			continue
+		}
+
+		if opts.Policy == models.ImportPolicyOmitir {
+			result.Estado = models.ImportEstadoOmitir
+			if !opts.DryRun {
+				result.Estado = models.ImportEstadoOmitido
+			}
+			report.Omitidos++
+			continue
+		}
+
+		merged := *current
+		fields := merged.MergeImport(usuario, rows[i].Columns)
+		merged.UpdatedAt = time.Now()
+		fields = append(fields, "updated_at")
+		result.Estado = models.ImportEstadoActualizar
+		report.Actualizados++
+		patches = append(patches, repository.UsuarioPatch{Usuario: &merged, Fields: fields})
+		patchRows = append(patchRows, i)
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	if len(creates) > 0 {
+		recordImportResults(report, createRows, s.repo.SaveAll(ctx, creates))
+	}
+	if len(patches) > 0 {
+		recordImportResults(report, patchRows, s.repo.PatchAll(ctx, patches))
+	}
+
+	return report, nil
+}
+
+// recordImportResults deja en el reporte el resultado de escribir las filas indicadas
+func recordImportResults(report *models.ImportReport, rows []int, errs []error) {
+	for n, i := range rows {
+		result := &report.Filas[i]
+		if errs[n] != nil {
+			if result.Estado == models.ImportEstadoCrear {
+				report.Creados--
+			} else {
+				report.Actualizados--
+			}
+			report.Fallidos++
+			result.Estado = models.ImportEstadoError
+			result.Errores = []string{errs[n].Error()}
+			continue
+		}
+
+		if result.Estado == models.ImportEstadoCrear {
+			result.Estado = models.ImportEstadoCreado
+		} else {
+			result.Estado = models.ImportEstadoActualizado
+		}
+	}
+}
+
+// recordValidationFailure registra en métricas el campo que falló la validación
+func recordValidationFailure(err error) {
+	var vErr *models.ValidationError
+	if errors.As(err, &vErr) {
+		metrics.ValidationFailure(vErr.Field)
+		return
+	}
+	metrics.ValidationFailure("desconocido")
+}
@@ -0,0 +1,224 @@
+package signing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// signatureSize bytes reservados para la firma CMS dentro de /Contents
+const signatureSize = 8192
+
+// byteRangeWidth ancho reservado para el arreglo /ByteRange, que se completa al final
+const byteRangeWidth = 48
+
+// signatureFieldName nombre del campo de firma que muestra el lector
+const signatureFieldName = "Firma"
+
+var startXRefPattern = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+
+// signatureInfo datos visibles de la firma en el panel del lector de PDF
+type signatureInfo struct {
+	Name        string
+	Reason      string
+	Location    string
+	ContactInfo string
+	Time        time.Time
+}
+
+// signPDF agrega al documento una firma PAdES (ETSI.CAdES.detached) invisible mediante una
+// actualización incremental: el contenido original queda intacto y se anexan el diccionario
+// de firma, el campo del formulario, el catálogo y la primera página actualizados.
+// sign recibe los bytes cubiertos por /ByteRange y devuelve la firma CMS en DER.
+func signPDF(document []byte, info signatureInfo, sign func(content []byte) ([]byte, error)) ([]byte, error) {
+	ctx, err := api.ReadContext(bytes.NewReader(document), model.NewDefaultConfiguration())
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo PDF: %w", err)
+	}
+	if ctx.Encrypt != nil {
+		return nil, errors.New("no se puede firmar un PDF cifrado")
+	}
+
+	prevXRef, err := lastXRefOffset(document)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo catálogo: %w", err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, fmt.Errorf("error leyendo páginas: %w", err)
+	}
+	pageDict, pageRef, _, err := ctx.PageDict(1, false)
+	if err != nil || pageDict == nil {
+		return nil, fmt.Errorf("error leyendo la primera página: %w", err)
+	}
+
+	size := *ctx.Size
+	sigNr, fieldNr := size, size+1
+	rootNr := ctx.Root.ObjectNumber.Value()
+	pageNr := pageRef.ObjectNumber.Value()
+	fieldRef := *types.NewIndirectRef(fieldNr, 0)
+
+	var buf bytes.Buffer
+	buf.Write(document)
+	if !bytes.HasSuffix(document, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	offsets := make(map[int]int, 4)
+
+	// Diccionario de firma con /ByteRange y /Contents reservados
+	offsets[sigNr] = buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /ETSI.CAdES.detached /ByteRange ", sigNr)
+	byteRangePos := buf.Len()
+	buf.WriteString(strings.Repeat(" ", byteRangeWidth))
+	buf.WriteString(" /Contents ")
+	contentsPos := buf.Len()
+	buf.WriteString("<" + strings.Repeat("0", signatureSize*2) + ">")
+	contentsEnd := buf.Len()
+	fmt.Fprintf(&buf, " /M %s /Name %s /Reason %s /Location %s /ContactInfo %s >>\nendobj\n",
+		pdfDate(info.Time), pdfText(info.Name), pdfText(info.Reason), pdfText(info.Location), pdfText(info.ContactInfo))
+
+	// Campo de firma invisible en la primera página
+	offsets[fieldNr] = buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /Annot /Subtype /Widget /FT /Sig /T %s /V %d 0 R /P %d 0 R /Rect [0 0 0 0] /F 132 >>\nendobj\n",
+		fieldNr, pdfText(signatureFieldName), sigNr, pageNr)
+
+	// Catálogo con el formulario de firmas
+	acroForm := types.Dict{}
+	if obj, ok := catalog.Find("AcroForm"); ok {
+		existing, err := ctx.DereferenceDict(obj)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo formulario: %w", err)
+		}
+		if existing != nil {
+			acroForm = existing.Clone().(types.Dict)
+		}
+	}
+	fields, err := ctx.DereferenceArray(acroForm["Fields"])
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo campos del formulario: %w", err)
+	}
+	acroForm["Fields"] = append(fields, fieldRef)
+	acroForm["SigFlags"] = types.Integer(3)
+
+	newCatalog := catalog.Clone().(types.Dict)
+	newCatalog["AcroForm"] = acroForm
+	offsets[rootNr] = buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", rootNr, newCatalog.PDFString())
+
+	// Página con la anotación del campo de firma
+	annots, err := ctx.DereferenceArray(pageDict["Annots"])
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo anotaciones: %w", err)
+	}
+	newPage := pageDict.Clone().(types.Dict)
+	newPage["Annots"] = append(annots, fieldRef)
+	offsets[pageNr] = buf.Len()
+	fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", pageNr, newPage.PDFString())
+
+	// Tabla xref y trailer de la actualización
+	xrefPos := buf.Len()
+	buf.WriteString("xref\n")
+	numbers := []int{sigNr, fieldNr, rootNr, pageNr}
+	slices.Sort(numbers)
+	for _, nr := range numbers {
+		fmt.Fprintf(&buf, "%d 1\n%010d 00000 n\r\n", nr, offsets[nr])
+	}
+	trailer := types.Dict{
+		"Size": types.Integer(size + 2),
+		"Root": *ctx.Root,
+		"Prev": types.Integer(prevXRef),
+	}
+	if ctx.Info != nil {
+		trailer["Info"] = *ctx.Info
+	}
+	if len(ctx.ID) > 0 {
+		trailer["ID"] = ctx.ID
+	}
+	fmt.Fprintf(&buf, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer.PDFString(), xrefPos)
+
+	out := buf.Bytes()
+
+	byteRange := fmt.Sprintf("[0 %d %d %d]", contentsPos, contentsEnd, len(out)-contentsEnd)
+	copy(out[byteRangePos:], byteRange)
+
+	content := make([]byte, 0, len(out)-(contentsEnd-contentsPos))
+	content = append(content, out[:contentsPos]...)
+	content = append(content, out[contentsEnd:]...)
+
+	signature, err := sign(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(signature) > signatureSize {
+		return nil, fmt.Errorf("la firma ocupa %d bytes y supera los %d reservados", len(signature), signatureSize)
+	}
+	hex.Encode(out[contentsPos+1:], signature)
+
+	return out, nil
+}
+
+// lastXRefOffset devuelve la posición de la última tabla xref. Solo se admiten tablas
+// clásicas, que es lo que producen los generadores de la aplicación.
+func lastXRefOffset(document []byte) (int, error) {
+	match := startXRefPattern.FindSubmatch(document)
+	if match == nil {
+		return 0, errors.New("PDF sin startxref")
+	}
+	offset, err := strconv.Atoi(string(match[1]))
+	if err != nil || offset >= len(document) {
+		return 0, errors.New("startxref inválido")
+	}
+	if !bytes.HasPrefix(document[offset:], []byte("xref")) {
+		return 0, errors.New("PDF con xref stream no soportado para la firma")
+	}
+	return offset, nil
+}
+
+// pdfDate formatea la fecha como D:AAAAMMDDHHmmSS+HH'mm'
+func pdfDate(t time.Time) string {
+	_, offset := t.Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("(D:%s%c%02d'%02d')", t.Format("20060102150405"), sign, offset/3600, offset%3600/60)
+}
+
+// pdfText codifica un texto como string literal o, si tiene caracteres fuera de ASCII
+// (tildes, ñ), como hex string UTF-16BE con BOM
+func pdfText(value string) string {
+	ascii := true
+	for _, r := range value {
+		if r > 127 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		escaped := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(value)
+		return "(" + escaped + ")"
+	}
+
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(value)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">")
+	return b.String()
+}
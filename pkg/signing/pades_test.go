@@ -0,0 +1,165 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitorus/pkcs7"
+
+	"github.com/luispfcanales/rainforestapp/pkg/pdf"
+	"github.com/luispfcanales/rainforestapp/pkg/repository"
+)
+
+var byteRangePattern = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+) (\d+) (\d+) (\d+)\s*\]`)
+
+// testPDF arma un PDF de una página con su tabla xref. El contenido dibuja unas líneas para
+// superar los 512 bytes que pdfcpu lee al final del archivo buscando startxref.
+func testPDF() []byte {
+	content := strings.Repeat("72 72 m 523 770 l S\n", 32)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents 4 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// testSigner firma con un certificado autofirmado generado para la prueba
+func testSigner(t *testing.T, key crypto.Signer) (*Signer, *x509.Certificate) {
+	t.Helper()
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "Rainforest Prueba"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+		Options{Reason: "Prueba", Location: "Iquitos"},
+		repository.NewMemoryAuditRepository(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, s.cert
+}
+
+// parseSignature extrae la firma PKCS#7 de /Contents junto con los bytes cubiertos por /ByteRange
+func parseSignature(t *testing.T, signed []byte) *pkcs7.PKCS7 {
+	t.Helper()
+
+	m := byteRangePattern.FindSubmatch(signed)
+	if m == nil {
+		t.Fatal("el PDF firmado no tiene /ByteRange")
+	}
+	var br [4]int
+	for i := range br {
+		br[i], _ = strconv.Atoi(string(m[i+1]))
+	}
+	if br[0] != 0 || br[2]+br[3] != len(signed) {
+		t.Fatalf("/ByteRange %v no cubre el documento de %d bytes", br, len(signed))
+	}
+
+	// /Contents queda entre los dos rangos como <hex>, relleno con ceros
+	der, err := hex.DecodeString(string(signed[br[1]+1 : br[2]-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7, err := pkcs7.Parse(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7.Content = append(signed[:br[1]:br[1]], signed[br[2]:]...)
+	return p7
+}
+
+func TestSignVerificaSobreByteRange(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, key := range map[string]crypto.Signer{"rsa": rsaKey, "ecdsa": ecKey} {
+		t.Run(name, func(t *testing.T) {
+			s, cert := testSigner(t, key)
+			document := testPDF()
+
+			signed, err := s.Sign(context.Background(), document, pdf.DocumentInfo{Tipo: "prueba"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(signed, document) {
+				t.Fatal("la firma modificó el contenido original")
+			}
+			if !bytes.Contains(signed, []byte("/M (D:")) {
+				t.Fatal("el diccionario de firma no tiene /M")
+			}
+
+			p7 := parseSignature(t, signed)
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			if err := p7.VerifyWithChain(roots); err != nil {
+				t.Fatal("firma inválida:", err)
+			}
+			for _, attr := range p7.Signers[0].AuthenticatedAttributes {
+				if attr.Type.Equal(pkcs7.OIDAttributeSigningTime) {
+					t.Fatal("la firma PAdES no debe llevar el atributo signing-time")
+				}
+			}
+
+			// un byte alterado dentro del rango firmado invalida la firma
+			tampered := bytes.Clone(signed)
+			tampered[len(document)/2] ^= 0xff
+			if err := parseSignature(t, tampered).VerifyWithChain(roots); err == nil {
+				t.Fatal("se aceptó un documento alterado")
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package signing firma digitalmente los PDF generados (PAdES) y registra cada firma en la auditoría
+package signing
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+	"github.com/luispfcanales/rainforestapp/pkg/pdf"
+	"github.com/luispfcanales/rainforestapp/pkg/repository"
+)
+
+// oidSigningCertificateV2 atributo ESS signing-certificate-v2 (RFC 5035) requerido por PAdES
+var oidSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+
+// essCertIDv2 identifica el certificado firmante; el algoritmo por defecto es SHA-256
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// Options datos que muestra el lector de PDF en el panel de firmas
+type Options struct {
+	Name        string
+	Reason      string
+	Location    string
+	ContactInfo string
+}
+
+// Signer firma los PDF con el certificado de la empresa
+type Signer struct {
+	cert  *x509.Certificate
+	chain []*x509.Certificate
+	key   crypto.Signer
+	opts  Options
+	audit repository.AuditRepository
+}
+
+// New crea un firmador a partir del certificado (con su cadena) y la clave privada en PEM
+func New(certPEM, keyPEM []byte, opts Options, audit repository.AuditRepository) (*Signer, error) {
+	certs, err := parseCertificates(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, ok := certs[0].PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(key.Public()) {
+		return nil, errors.New("la clave privada no corresponde al certificado")
+	}
+	if _, err := signatureAlgorithm(key.Public()); err != nil {
+		return nil, err
+	}
+
+	if opts.Name == "" {
+		opts.Name = certs[0].Subject.CommonName
+	}
+
+	return &Signer{
+		cert:  certs[0],
+		chain: certs[1:],
+		key:   key,
+		opts:  opts,
+		audit: audit,
+	}, nil
+}
+
+// Sign firma el documento y registra la firma en la auditoría. Si no se puede registrar
+// la firma el documento no se entrega.
+func (s *Signer) Sign(ctx context.Context, document []byte, info pdf.DocumentInfo) ([]byte, error) {
+	now := time.Now()
+
+	signed, err := signPDF(document, signatureInfo{
+		Name:        s.opts.Name,
+		Reason:      s.opts.Reason,
+		Location:    s.opts.Location,
+		ContactInfo: s.opts.ContactInfo,
+		Time:        now,
+	}, s.cms)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(signed)
+	event := &models.AuditEvent{
+		Accion:    models.AuditPDFFirmado,
+		Documento: info.Tipo,
+		UsuarioID: info.UsuarioID,
+		Detalle: map[string]string{
+			"firmante":   s.cert.Subject.String(),
+			"emisor":     s.cert.Issuer.String(),
+			"serie":      s.cert.SerialNumber.Text(16),
+			"formato":    "PAdES ETSI.CAdES.detached",
+			"algoritmo":  "SHA-256",
+			"motivo":     s.opts.Reason,
+			"sha256":     hex.EncodeToString(sum[:]),
+			"bytes":      fmt.Sprintf("%d", len(signed)),
+			"firmado_en": now.Format(time.RFC3339),
+		},
+		CreatedAt: now,
+	}
+	if err := s.audit.Record(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return signed, nil
+}
+
+// Check verifica que el certificado esté vigente
+func (s *Signer) Check(now time.Time) error {
+	if now.Before(s.cert.NotBefore) {
+		return fmt.Errorf("el certificado de firma es válido desde %s", s.cert.NotBefore.Format(time.DateOnly))
+	}
+	if now.After(s.cert.NotAfter) {
+		return fmt.Errorf("el certificado de firma venció el %s", s.cert.NotAfter.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// parseCertificates lee el certificado firmante seguido de su cadena
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificado inválido: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no se encontró un certificado PEM")
+	}
+	return certs, nil
+}
+
+// parsePrivateKey lee una clave RSA o ECDSA en PKCS#8, PKCS#1 o SEC 1
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no se encontró una clave privada PEM")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("clave privada inválida: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("tipo de clave privada no soportado")
+	}
+	return signer, nil
+}
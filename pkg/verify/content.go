@@ -0,0 +1,165 @@
+package verify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// ContentVersion versión de la proyección con la que se emiten los tokens nuevos. Si cambian
+// los datos impresos en la ficha se agrega una versión nueva y se conservan las anteriores
+// para verificar los tokens ya emitidos.
+const ContentVersion = 1
+
+// projections proyección de los datos de la ficha por versión. La versión 0 es la de los
+// tokens emitidos antes de versionar el hash: el usuario completo en JSON.
+var projections = map[int]func(u *models.Usuario) any{
+	0: func(u *models.Usuario) any { return u },
+	1: fichaV1,
+}
+
+// fichaContentV1 datos impresos en la ficha, con los códigos guardados y no los nombres del
+// catálogo para que renombrar un ítem no cambie el hash. Las tablas van como filas de texto
+// en el orden de la ficha.
+type fichaContentV1 struct {
+	TipoDocumento     string    `json:"tipo_documento"`
+	NumeroDocumento   string    `json:"numero_documento"`
+	ApellidoPaterno   string    `json:"apellido_paterno"`
+	ApellidoMaterno   string    `json:"apellido_materno"`
+	Nombres           string    `json:"nombres"`
+	FechaNacimiento   string    `json:"fecha_nacimiento"`
+	Sexo              string    `json:"sexo"`
+	EstadoCivil       string    `json:"estado_civil"`
+	GrupoSanguineo    string    `json:"grupo_sanguineo"`
+	Licencia          string    `json:"licencia"`
+	CategoriaLicencia string    `json:"categoria_licencia"`
+	Domicilio         string    `json:"domicilio"`
+	Nacimiento        [4]string `json:"nacimiento"`
+	Foto              string    `json:"foto"`
+
+	Telefono             string `json:"telefono"`
+	Email                string `json:"email"`
+	ContactoNombre       string `json:"contacto_nombre"`
+	ContactoParentesco   string `json:"contacto_parentesco"`
+	ContactoCelular      string `json:"contacto_celular"`
+	ContactoTelefonoFijo string `json:"contacto_telefono_fijo"`
+	ContactoDireccion    string `json:"contacto_direccion"`
+
+	PuestoActual         string `json:"puesto_actual"`
+	LugarTrabajo         string `json:"lugar_trabajo"`
+	FechaIngreso         string `json:"fecha_ingreso"`
+	FechaCese            string `json:"fecha_cese"`
+	RegimenPensionario   string `json:"regimen_pensionario"`
+	AfpNombre            string `json:"afp_nombre"`
+	Cuspp                string `json:"cuspp"`
+	RegimenSalud         string `json:"regimen_salud"`
+	SituacionContractual string `json:"situacion_contractual"`
+
+	Autoriza  [3]bool    `json:"autoriza"`
+	OtroBanco [3]string  `json:"otro_banco"`
+	Cuentas   [][]string `json:"cuentas"`
+
+	Conyuge            []string   `json:"conyuge"`
+	Hijos              [][]string `json:"hijos"`
+	Padres             [][]string `json:"padres"`
+	EducacionBasica    [][]string `json:"educacion_basica"`
+	EducacionSuperior  [][]string `json:"educacion_superior"`
+	Capacitaciones     [][]string `json:"capacitaciones"`
+	ExperienciaLaboral [][]string `json:"experiencia_laboral"`
+	Idiomas            [][]string `json:"idiomas"`
+}
+
+// fichaV1 proyecta el usuario en los datos de la versión 1 de la ficha
+func fichaV1(u *models.Usuario) any {
+	doc := u.Documento()
+	c := fichaContentV1{
+		TipoDocumento:     doc.Tipo,
+		NumeroDocumento:   doc.Numero,
+		ApellidoPaterno:   u.ApellidoPaterno,
+		ApellidoMaterno:   u.ApellidoMaterno,
+		Nombres:           u.Nombres,
+		FechaNacimiento:   u.FechaNacimiento,
+		Sexo:              u.Sexo,
+		EstadoCivil:       u.EstadoCivil,
+		GrupoSanguineo:    u.GrupoSanguineo,
+		Licencia:          u.LicenciaConducir,
+		CategoriaLicencia: u.CategoriaLicencia,
+		Domicilio:         u.DireccionDomicilio,
+		Nacimiento:        [4]string{u.LugarNacimientoDepartamento, u.LugarNacimientoProvincia, u.LugarNacimientoDistrito, u.UbigeoNacimiento},
+
+		Telefono:             u.Telefono,
+		Email:                u.Email,
+		ContactoNombre:       u.ContactoNombre,
+		ContactoParentesco:   u.ContactoParentesco,
+		ContactoCelular:      u.ContactoCelular,
+		ContactoTelefonoFijo: u.ContactoTelefonoFijo,
+		ContactoDireccion:    u.ContactoDireccion,
+
+		PuestoActual:         u.PuestoActual,
+		LugarTrabajo:         u.LugarTrabajo,
+		FechaIngreso:         u.FechaIngreso,
+		FechaCese:            u.FechaCese,
+		RegimenPensionario:   u.RegimenPensionario,
+		AfpNombre:            u.AfpNombre,
+		Cuspp:                u.Cuspp,
+		RegimenSalud:         u.RegimenSalud,
+		SituacionContractual: u.SituacionContractual,
+
+		Autoriza: [3]bool{u.AutorizaBcp, u.AutorizaCtsBcp, u.AutorizaOtroBanco},
+	}
+	if u.Foto != "" {
+		sum := sha256.Sum256([]byte(u.Foto))
+		c.Foto = hex.EncodeToString(sum[:])
+	}
+	if u.AutorizaOtroBanco && len(u.CuentasBancarias) == 0 {
+		c.OtroBanco = [3]string{u.OtroBancoNombre, u.OtroBancoCuenta, u.OtroBancoCci}
+	}
+	for _, cu := range u.CuentasBancarias {
+		c.Cuentas = append(c.Cuentas, []string{cu.Proposito, cu.Banco, cu.NumeroCuenta, cu.Cci, cu.Moneda, strconv.FormatBool(cu.Activa)})
+	}
+
+	if cy := u.DatosConyuge; cy != nil && cy.ApellidosNombres != "" {
+		c.Conyuge = []string{cy.ApellidosNombres, cy.Dni, cy.FechaNacimiento, cy.Direccion}
+	}
+	for _, h := range u.Hijos {
+		c.Hijos = append(c.Hijos, []string{h.ApellidosNombres, h.Dni, h.FechaNacimiento, strconv.Itoa(h.Edad)})
+	}
+	for _, p := range u.Padres {
+		c.Padres = append(c.Padres, []string{p.ApellidosNombres, p.FechaNacimiento, p.Ocupacion, p.EstadoCivil, strconv.FormatBool(p.Vive)})
+	}
+	for _, e := range u.EducacionBasica {
+		c.EducacionBasica = append(c.EducacionBasica, []string{e.Nivel, e.CentroEstudios, e.Desde, e.Hasta, strconv.FormatBool(e.Completa)})
+	}
+	for _, e := range u.EducacionSuperior {
+		c.EducacionSuperior = append(c.EducacionSuperior, []string{e.Nivel, e.CentroEstudios, e.Especialidad, e.Desde, e.Hasta, e.GradoAcademico})
+	}
+	for _, ca := range u.Capacitaciones {
+		c.Capacitaciones = append(c.Capacitaciones, []string{ca.Nombre, ca.Institucion, strconv.Itoa(ca.Horas)})
+	}
+	for _, ex := range u.ExperienciaLaboral {
+		c.ExperienciaLaboral = append(c.ExperienciaLaboral, []string{ex.Empresa, ex.Cargo, ex.FechaIngreso, ex.FechaCese, ex.MotivoCese})
+	}
+	for _, i := range u.Idiomas {
+		c.Idiomas = append(c.Idiomas, []string{i.Idioma, i.Lee, i.Habla, i.Escribe})
+	}
+	return c
+}
+
+// ContentHash devuelve el SHA-256 en hexadecimal de los datos del usuario impresos en la
+// ficha según la proyección de la versión indicada
+func ContentHash(u *models.Usuario, version int) (string, error) {
+	project, ok := projections[version]
+	if !ok {
+		return "", fmt.Errorf("versión de contenido desconocida: %d", version)
+	}
+	data, err := json.Marshal(project(u))
+	if err != nil {
+		return "", fmt.Errorf("error serializando usuario: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,54 @@
+package verify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+func testUsuario() *models.Usuario {
+	return &models.Usuario{
+		ID:              "u1",
+		CreatedAt:       time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		TipoDocumento:   models.DocumentoDNI,
+		NumeroDocumento: "12345678",
+		Dni:             "12345678",
+		ApellidoPaterno: "Quispe",
+		ApellidoMaterno: "Mamani",
+		Nombres:         "Rosa",
+		Hijos:           []models.Hijo{{ID: "h1", ApellidosNombres: "Quispe Rosa", Dni: "87654321", Edad: 4}},
+	}
+}
+
+func TestContentHashIgnoraCamposNoImpresos(t *testing.T) {
+	want, err := ContentHash(testUsuario(), ContentVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := testUsuario()
+	u.UpdatedAt = time.Now()
+	u.Hijos[0].ID = "otro"
+	u.Domicilio = &models.Direccion{NombreVia: "Lima"}
+	if got, _ := ContentHash(u, ContentVersion); got != want {
+		t.Fatal("cambió el hash por campos que no se imprimen")
+	}
+
+	u.Hijos[0].Edad = 5
+	if got, _ := ContentHash(u, ContentVersion); got == want {
+		t.Fatal("no cambió el hash al cambiar un dato impreso")
+	}
+}
+
+func TestContentHashVersiones(t *testing.T) {
+	// Cambiar la proyección de una versión invalida los tokens ya emitidos con ella
+	const v1 = "4a97000597ffa1db3a818f7c0aee60ea77373470baac1a7e6307079cf08f93f7"
+	if got, _ := ContentHash(testUsuario(), 1); got != v1 {
+		t.Fatalf("hash v1 = %s, want %s", got, v1)
+	}
+
+	if _, err := ContentHash(testUsuario(), 99); err == nil {
+		t.Fatal("se aceptó una versión desconocida")
+	}
+}
@@ -0,0 +1,124 @@
+// Package verify firma y valida los tokens de verificación impresos como QR en los documentos
+package verify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/luispfcanales/rainforestapp/pkg/models"
+)
+
+// DocumentoFicha tipo de documento de la ficha de datos del personal
+const DocumentoFicha = "ficha"
+
+// ErrInvalidToken el token está mal formado o su firma no coincide
+var ErrInvalidToken = errors.New("token de verificación inválido")
+
+// Claims datos firmados en el token. No incluyen datos personales: solo el ID interno
+// del trabajador, la fecha de emisión y el hash del contenido con la versión de la
+// proyección con que se calculó.
+type Claims struct {
+	Documento string `json:"doc"`
+	UsuarioID string `json:"sub"`
+	EmitidoEn int64  `json:"iat"`
+	Hash      string `json:"h"`
+	Version   int    `json:"v,omitempty"`
+}
+
+// Issued devuelve la fecha de emisión
+func (c *Claims) Issued() time.Time {
+	return time.Unix(c.EmitidoEn, 0)
+}
+
+// Signer firma los tokens con HMAC-SHA256
+type Signer struct {
+	secret  []byte
+	baseURL string
+}
+
+// NewSigner crea un firmador; baseURL es la URL pública del despliegue
+func NewSigner(secret, baseURL string) *Signer {
+	return &Signer{
+		secret:  []byte(secret),
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Sign devuelve el token "payload.firma" en base64url
+func (s *Signer) Sign(c Claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("error serializando token: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded)), nil
+}
+
+// Parse valida la firma del token y devuelve sus datos
+func (s *Signer) Parse(token string) (*Claims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, s.mac(encoded)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var c Claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return &c, nil
+}
+
+// Issue firma el token de la ficha del usuario y devuelve la URL de verificación y el
+// código corto que se imprime junto al QR
+func (s *Signer) Issue(u *models.Usuario, issuedAt time.Time) (string, string, error) {
+	hash, err := ContentHash(u, ContentVersion)
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := s.Sign(Claims{
+		Documento: DocumentoFicha,
+		UsuarioID: u.ID,
+		EmitidoEn: issuedAt.Unix(),
+		Hash:      hash,
+		Version:   ContentVersion,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.baseURL + "/api/verificar?token=" + url.QueryEscape(token), ShortCode(hash), nil
+}
+
+func (s *Signer) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// ShortCode devuelve los primeros 16 caracteres del hash en grupos de 4 ("A1B2-C3D4-...")
+func ShortCode(hash string) string {
+	code := strings.ToUpper(hash[:min(16, len(hash))])
+	var groups []string
+	for len(code) > 4 {
+		groups = append(groups, code[:4])
+		code = code[4:]
+	}
+	return strings.Join(append(groups, code), "-")
+}